@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+)
+
+// Sending paths to a coding agent running in another terminal pane.
+// Paths are sent as @path references, the syntax agent CLIs use to attach
+// files to a prompt. A tmux pane receives them as typed text (without Enter,
+// so the prompt can be finished by hand); FIFOs and sockets receive one line.
+
+// agentTargetKind is how paths are delivered
+type agentTargetKind int
+
+const (
+	agentTargetTmux   agentTargetKind = iota // tmux send-keys to a pane
+	agentTargetFIFO                          // Write a line to a named pipe
+	agentTargetSocket                        // Write a line to a Unix socket
+)
+
+// agentTarget is a destination for @path references
+type agentTarget struct {
+	Kind  agentTargetKind
+	Addr  string // tmux pane ID (e.g., "%3"), FIFO path or socket path
+	Label string // Shown in the picker and messages
+	Dir   string // Working directory of the pane (paths are sent relative to it)
+}
+
+// agentCommands are programs that identify a pane running a coding agent
+var agentCommands = []string{"claude", "codex", "aider", "gemini", "opencode"}
+
+// parseAgentTarget parses "tmux:<pane>", "fifo:<path>" or "unix:<path>"
+func parseAgentTarget(s string) (agentTarget, error) {
+	scheme, addr, ok := strings.Cut(s, ":")
+	if !ok || addr == "" {
+		return agentTarget{}, fmt.Errorf("invalid agent target %q (want tmux:<pane>, fifo:<path> or unix:<path>)", s)
+	}
+	t := agentTarget{Addr: addr, Label: s}
+	switch scheme {
+	case "tmux":
+		t.Kind = agentTargetTmux
+	case "fifo":
+		t.Kind = agentTargetFIFO
+		t.Addr = expandHome(addr)
+	case "unix":
+		t.Kind = agentTargetSocket
+		t.Addr = expandHome(addr)
+	default:
+		return agentTarget{}, fmt.Errorf("invalid agent target %q (want tmux:<pane>, fifo:<path> or unix:<path>)", s)
+	}
+	return t, nil
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// listTmuxPanes returns the panes of the running tmux server except self,
+// panes running a known agent first
+func listTmuxPanes(self string) ([]agentTarget, error) {
+	out, err := exec.Command("tmux", "list-panes", "-a", "-F",
+		"#{pane_id}\t#{session_name}:#{window_index}.#{pane_index}\t#{pane_current_command}\t#{pane_current_path}").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseTmuxPanes(string(out), self), nil
+}
+
+// tmuxPaneDir returns the current directory of a tmux pane ("" if unknown)
+func tmuxPaneDir(pane string) string {
+	out, err := exec.Command("tmux", "display-message", "-p", "-t", pane, "#{pane_current_path}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// parseTmuxPanes parses list-panes output (pane ID, position, command, path per line)
+func parseTmuxPanes(out, self string) []agentTarget {
+	var agents, others []agentTarget
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 || fields[0] == self {
+			continue
+		}
+		t := agentTarget{
+			Kind:  agentTargetTmux,
+			Addr:  fields[0],
+			Label: fmt.Sprintf("%s (%s)", fields[1], fields[2]),
+			Dir:   fields[3],
+		}
+		if slices.Contains(agentCommands, fields[2]) {
+			agents = append(agents, t)
+		} else {
+			others = append(others, t)
+		}
+	}
+	return append(agents, others...)
+}
+
+// agentRefs formats paths as @path references separated by spaces.
+// Paths under dir are relative to it; others stay absolute. Paths with
+// whitespace are quoted as @"path" so each reference stays one token.
+func agentRefs(paths []string, dir string) string {
+	refs := make([]string, len(paths))
+	for i, p := range paths {
+		if dir != "" {
+			if rel, err := filepath.Rel(dir, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				p = rel
+			}
+		}
+		if strings.ContainsFunc(p, unicode.IsSpace) {
+			p = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+		}
+		refs[i] = "@" + p
+	}
+	return strings.Join(refs, " ")
+}
+
+// sendToAgent delivers text to the target
+func sendToAgent(t agentTarget, text string) error {
+	switch t.Kind {
+	case agentTargetTmux:
+		// -l sends the text literally; the trailing space separates further input
+		out, err := exec.Command("tmux", "send-keys", "-t", t.Addr, "-l", "--", text+" ").CombinedOutput()
+		if err != nil {
+			if msg := strings.TrimSpace(string(out)); msg != "" {
+				return fmt.Errorf("tmux: %s", msg)
+			}
+			return err
+		}
+		return nil
+
+	case agentTargetFIFO:
+		info, err := os.Stat(t.Addr)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeNamedPipe == 0 {
+			return fmt.Errorf("%s is not a FIFO", t.Addr)
+		}
+		// Non-blocking open fails instead of hanging when nobody is reading
+		f, err := os.OpenFile(t.Addr, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			return fmt.Errorf("%s: no reader", t.Addr)
+		}
+		defer f.Close()
+		_, err = f.WriteString(text + "\n")
+		return err
+
+	case agentTargetSocket:
+		conn, err := net.DialTimeout("unix", t.Addr, time.Second)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		_, err = conn.Write([]byte(text + "\n"))
+		return err
+	}
+	return fmt.Errorf("unknown target %s", t.Label)
+}
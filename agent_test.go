@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseAgentTarget(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    agentTargetKind
+		addr    string
+		wantErr bool
+	}{
+		{"tmux:%3", agentTargetTmux, "%3", false},
+		{"fifo:/tmp/agent.fifo", agentTargetFIFO, "/tmp/agent.fifo", false},
+		{"unix:/run/agent.sock", agentTargetSocket, "/run/agent.sock", false},
+		{"tmux:", 0, "", true},
+		{"%3", 0, "", true},
+		{"http://x", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAgentTarget(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAgentTarget(%q) error = %v", tt.input, err)
+			}
+			if !tt.wantErr && (got.Kind != tt.kind || got.Addr != tt.addr) {
+				t.Errorf("parseAgentTarget(%q) = %+v", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestParseTmuxPanes(t *testing.T) {
+	out := "%1\tmain:1.0\tbon3ai\t/src/app\n" +
+		"%2\tmain:1.1\tzsh\t/src/app\n" +
+		"%3\tmain:2.0\tclaude\t/src/app\n" +
+		"garbage\n"
+
+	panes := parseTmuxPanes(out, "%1")
+	if len(panes) != 2 {
+		t.Fatalf("Expected 2 panes (self and garbage skipped), got %d", len(panes))
+	}
+	if panes[0].Addr != "%3" || panes[0].Label != "main:2.0 (claude)" || panes[0].Dir != "/src/app" {
+		t.Errorf("Expected the agent pane first, got %+v", panes[0])
+	}
+	if panes[1].Addr != "%2" {
+		t.Errorf("Expected the shell pane second, got %+v", panes[1])
+	}
+}
+
+func TestAgentRefs(t *testing.T) {
+	paths := []string{"/src/app/main.go", "/src/app/pkg", "/etc/hosts"}
+	if got := agentRefs(paths, "/src/app"); got != "@main.go @pkg @/etc/hosts" {
+		t.Errorf("agentRefs = %q", got)
+	}
+	if got := agentRefs(paths[:1], ""); got != "@/src/app/main.go" {
+		t.Errorf("agentRefs without dir = %q", got)
+	}
+	spaced := []string{"/src/app/my notes.md", "/src/app/a\tb"}
+	if got := agentRefs(spaced, "/src/app"); got != `@"my notes.md" @"a`+"\t"+`b"` {
+		t.Errorf("agentRefs with whitespace = %q", got)
+	}
+}
+
+func TestSendToAgent(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("socket", func(t *testing.T) {
+		sock := filepath.Join(dir, "agent.sock")
+		ln, err := net.Listen("unix", sock)
+		if err != nil {
+			t.Skipf("Unix sockets unavailable: %v", err)
+		}
+		defer ln.Close()
+
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			received <- line
+		}()
+
+		if err := sendToAgent(agentTarget{Kind: agentTargetSocket, Addr: sock}, "@a.go"); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-received; got != "@a.go\n" {
+			t.Errorf("Received %q", got)
+		}
+	})
+
+	t.Run("fifo", func(t *testing.T) {
+		fifo := filepath.Join(dir, "agent.fifo")
+		if err := exec.Command("mkfifo", fifo).Run(); err != nil {
+			t.Skipf("FIFOs unavailable: %v", err)
+		}
+		target := agentTarget{Kind: agentTargetFIFO, Addr: fifo}
+
+		// Nobody reading: fails instead of blocking
+		if err := sendToAgent(target, "@a.go"); err == nil || !strings.Contains(err.Error(), "no reader") {
+			t.Errorf("Expected no reader error, got %v", err)
+		}
+
+		r, err := os.OpenFile(fifo, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		if err := sendToAgent(target, "@a.go @b.go"); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 64)
+		n, _ := r.Read(buf)
+		if got := string(buf[:n]); got != "@a.go @b.go\n" {
+			t.Errorf("Received %q", got)
+		}
+	})
+
+	t.Run("not a fifo", func(t *testing.T) {
+		file := filepath.Join(dir, "plain")
+		os.WriteFile(file, nil, 0644)
+		if err := sendToAgent(agentTarget{Kind: agentTargetFIFO, Addr: file}, "@x"); err == nil {
+			t.Error("Expected error for a regular file")
+		}
+	})
+}
+
+func TestAgentPick_RemembersTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), nil, 0644)
+
+	sockDir := t.TempDir()
+	sock := filepath.Join(sockDir, "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			conn.Close()
+			received <- line
+		}
+	}()
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.width = 80
+	model.height = 20
+	model.applyConfig(Config{Agent: AgentConfig{Targets: []string{"unix:" + sock}}})
+	model.selected = 1
+
+	var m tea.Model = model
+	m, _ = m.Update(keyMsg("@"))
+	if m.(Model).inputMode != ModeAgentPick {
+		t.Fatalf("Expected the target picker on first send, got %v", m.(Model).inputMode)
+	}
+	if got := m.(Model).agentCandidates; len(got) == 0 || got[0].Addr != sock {
+		t.Errorf("Expected the configured target first, got %+v", got)
+	}
+	if !strings.Contains(m.(Model).renderTreeView(), "Send to agent") {
+		t.Error("Expected the picker popup")
+	}
+
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	if got := <-received; got != "@"+filepath.Join(tmpDir, "main.go")+"\n" {
+		t.Errorf("Received %q", got)
+	}
+
+	// The target is remembered: the next send goes straight out
+	m, _ = m.Update(keyMsg("@"))
+	if m.(Model).inputMode != ModeNormal {
+		t.Error("Expected no picker once a target is chosen")
+	}
+	<-received
+	if !strings.HasPrefix(m.(Model).message, "Sent @") {
+		t.Errorf("Unexpected message %q", m.(Model).message)
+	}
+}
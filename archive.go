@@ -0,0 +1,456 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Archive listing, extraction and creation.
+// zip, tar, gzip and bzip2 use the standard library; xz uses github.com/ulikunitz/xz.
+
+// archiveEntry is a file or directory inside an archive
+type archiveEntry struct {
+	Name    string // Slash-separated path inside the archive (no trailing slash)
+	Size    int64
+	ModTime time.Time
+	Mode    fs.FileMode
+	IsDir   bool
+	Link    string // Symlink target (tar only)
+}
+
+// archiveFormat returns the archive format for a path ("" if not an archive)
+func archiveFormat(p string) string {
+	name := strings.ToLower(filepath.Base(p))
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".tar.bz2"), strings.HasSuffix(name, ".tbz2"), strings.HasSuffix(name, ".tbz"):
+		return "tar.bz2"
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		return "tar.xz"
+	}
+	return ""
+}
+
+// isArchiveFile returns true if the path is a supported archive
+func isArchiveFile(p string) bool {
+	return archiveFormat(p) != ""
+}
+
+// uniqueArchivePath is getUniquePath that keeps compound extensions like .tar.gz intact
+func uniqueArchivePath(p string) string {
+	lower := strings.ToLower(p)
+	for _, ext := range []string{".tar.gz", ".tar.bz2", ".tar.xz"} {
+		if !strings.HasSuffix(lower, ext) {
+			continue
+		}
+		base, ext := p[:len(p)-len(ext)], p[len(p)-len(ext):]
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			return p
+		}
+		for counter := 1; counter <= 1000; counter++ {
+			candidate := fmt.Sprintf("%s_%d%s", base, counter, ext)
+			if _, err := os.Stat(candidate); os.IsNotExist(err) {
+				return candidate
+			}
+		}
+		return fmt.Sprintf("%s_%d%s", base, time.Now().UnixNano(), ext)
+	}
+	return getUniquePath(p)
+}
+
+// listArchive returns the entries of an archive in stored order
+func listArchive(archivePath string) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	err := walkArchive(archivePath, func(e archiveEntry, _ io.Reader) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// walkArchive calls fn for each entry; the reader yields the file contents
+func walkArchive(archivePath string, fn func(e archiveEntry, r io.Reader) error) error {
+	format := archiveFormat(archivePath)
+	if format == "zip" {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			e := archiveEntry{
+				Name:    strings.TrimSuffix(f.Name, "/"),
+				Size:    int64(f.UncompressedSize64),
+				ModTime: f.Modified,
+				Mode:    f.Mode(),
+				IsDir:   f.FileInfo().IsDir(),
+			}
+			if e.IsDir {
+				if err := fn(e, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = fn(e, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	switch format {
+	case "tar.gz":
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case "tar.bz2":
+		r = bzip2.NewReader(file)
+	case "tar.xz":
+		xr, err := xz.NewReader(file)
+		if err != nil {
+			return err
+		}
+		r = xr
+	case "tar":
+	default:
+		return fmt.Errorf("unsupported archive: %s", filepath.Base(archivePath))
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		e := archiveEntry{
+			Name:    strings.TrimSuffix(hdr.Name, "/"),
+			Size:    hdr.Size,
+			ModTime: hdr.ModTime,
+			Mode:    hdr.FileInfo().Mode(),
+			IsDir:   hdr.Typeflag == tar.TypeDir,
+		}
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			e.Link = hdr.Linkname
+		case tar.TypeLink:
+			// Hard links have no data of their own; list them but don't extract
+			e.Mode |= fs.ModeIrregular
+		}
+		if err := fn(e, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extractArchive extracts entries into destDir and returns the number of files written.
+// If names is non-empty, only those entries (and everything below directory entries) are extracted.
+// Top-level names that already exist in destDir are given a unique name instead of being overwritten.
+// Entries with unsafe paths (absolute, escaping destDir, or leading through a
+// symlink extracted earlier) are skipped.
+func extractArchive(archivePath, destDir string, names []string) (int, error) {
+	renamed := make(map[string]string) // Top-level name -> name used in destDir
+	count := 0
+
+	err := walkArchive(archivePath, func(e archiveEntry, r io.Reader) error {
+		if !archiveEntrySelected(e.Name, names) {
+			return nil
+		}
+		rel := path.Clean(e.Name)
+		if rel == "." || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return nil
+		}
+
+		// Avoid overwriting existing files by renaming the top-level component once
+		top, rest, _ := strings.Cut(rel, "/")
+		target, ok := renamed[top]
+		if !ok {
+			target = filepath.Base(getUniquePath(filepath.Join(destDir, top)))
+			renamed[top] = target
+		}
+		dest := filepath.Join(destDir, target, filepath.FromSlash(rest))
+
+		// A chain of links can each look local and still point outside
+		parent := filepath.Dir(dest)
+		if e.IsDir {
+			parent = dest
+		}
+		if throughSymlink(destDir, parent) {
+			return nil
+		}
+
+		switch {
+		case e.IsDir:
+			return os.MkdirAll(dest, 0755)
+		case e.Link != "":
+			// Only recreate links that stay inside the extracted tree
+			if filepath.IsAbs(e.Link) || !filepath.IsLocal(filepath.Join(filepath.Dir(filepath.FromSlash(rel)), e.Link)) {
+				return nil
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(e.Link, dest); err != nil {
+				return err
+			}
+			count++
+			return nil
+		case !e.Mode.IsRegular() || r == nil:
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		perm := e.Mode.Perm()
+		if perm == 0 {
+			perm = 0644
+		}
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+		if !e.ModTime.IsZero() {
+			os.Chtimes(dest, e.ModTime, e.ModTime)
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// throughSymlink reports whether any existing component of p below destDir
+// is a symlink, so writing there would follow it
+func throughSymlink(destDir, p string) bool {
+	rel, err := filepath.Rel(destDir, p)
+	if err != nil {
+		return true
+	}
+	if rel == "." {
+		return false
+	}
+	cur := destDir
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		cur = filepath.Join(cur, name)
+		info, err := os.Lstat(cur)
+		if err != nil {
+			return false // Not created yet, so nothing below it exists either
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveEntrySelected returns true if name is one of names or below one of them
+func archiveEntrySelected(name string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, n := range names {
+		if name == n || strings.HasPrefix(name, n+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// createArchive writes the given paths (recursively) to a new archive at dest.
+// The format is chosen by dest's extension. Returns the number of files added.
+func createArchive(dest string, paths []string) (count int, err error) {
+	format := archiveFormat(dest)
+	switch format {
+	case "":
+		return 0, errors.New("unknown archive type (use .zip, .tar, .tar.gz or .tar.xz)")
+	case "tar.bz2":
+		return 0, errors.New("creating .tar.bz2 is not supported")
+	}
+
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+
+	var add func(src, name string, info fs.FileInfo) error
+	var finish func() error
+
+	if format == "zip" {
+		zw := zip.NewWriter(file)
+		finish = zw.Close
+		add = func(src, name string, info fs.FileInfo) error {
+			hdr, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if info.IsDir() {
+				hdr.Name += "/"
+			} else {
+				hdr.Method = zip.Deflate
+			}
+			w, err := zw.CreateHeader(hdr)
+			if err != nil || info.IsDir() {
+				return err
+			}
+			if info.Mode()&fs.ModeSymlink != 0 {
+				target, err := os.Readlink(src)
+				if err != nil {
+					return err
+				}
+				_, err = io.WriteString(w, target)
+				return err
+			}
+			return copyFileTo(w, src)
+		}
+	} else {
+		var w io.Writer = file
+		var closers []io.Closer
+		switch format {
+		case "tar.gz":
+			gz := gzip.NewWriter(file)
+			w = gz
+			closers = append(closers, gz)
+		case "tar.xz":
+			xw, err := xz.NewWriter(file)
+			if err != nil {
+				return 0, err
+			}
+			w = xw
+			closers = append(closers, xw)
+		}
+		tw := tar.NewWriter(w)
+		finish = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			for _, c := range closers {
+				if err := c.Close(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		add = func(src, name string, info fs.FileInfo) error {
+			link := ""
+			if info.Mode()&fs.ModeSymlink != 0 {
+				target, err := os.Readlink(src)
+				if err != nil {
+					return err
+				}
+				link = target
+			}
+			hdr, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if info.IsDir() {
+				hdr.Name += "/"
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			return copyFileTo(tw, src)
+		}
+	}
+
+	absDest, _ := filepath.Abs(dest)
+	for _, p := range paths {
+		base := filepath.Dir(p)
+		err := filepath.Walk(p, func(src string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			// Don't add the archive being written
+			if abs, _ := filepath.Abs(src); abs == absDest {
+				return nil
+			}
+			rel, err := filepath.Rel(base, src)
+			if err != nil {
+				return err
+			}
+			if err := add(src, filepath.ToSlash(rel), info); err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := finish(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// copyFileTo copies a file's contents to w
+func copyFileTo(w io.Writer, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestArchiveFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"a.zip", "zip"},
+		{"a.tar", "tar"},
+		{"a.tar.gz", "tar.gz"},
+		{"a.TGZ", "tar.gz"},
+		{"a.tar.bz2", "tar.bz2"},
+		{"a.tar.xz", "tar.xz"},
+		{"a.gz", ""},
+		{"a.7z", ""},
+		{"main.go", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := archiveFormat(tt.path); got != tt.expected {
+				t.Errorf("archiveFormat(%q) = %q, expected %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+// setupArchiveSource creates src/ with a file and a nested directory
+func setupArchiveSource(t *testing.T, dir string) string {
+	t.Helper()
+	src := filepath.Join(dir, "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("hello"), 0644)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("world"), 0644)
+	return src
+}
+
+func TestCreateListExtractArchive(t *testing.T) {
+	for _, name := range []string{"out.zip", "out.tar", "out.tar.gz", "out.tar.xz"} {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			src := setupArchiveSource(t, tmpDir)
+			archivePath := filepath.Join(tmpDir, name)
+
+			count, err := createArchive(archivePath, []string{src})
+			if err != nil {
+				t.Fatalf("createArchive: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Expected 2 files archived, got %d", count)
+			}
+
+			entries, err := listArchive(archivePath)
+			if err != nil {
+				t.Fatalf("listArchive: %v", err)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name)
+			}
+			sort.Strings(names)
+			expected := []string{"src", "src/a.txt", "src/sub", "src/sub/b.txt"}
+			if len(names) != len(expected) {
+				t.Fatalf("Entries = %v, expected %v", names, expected)
+			}
+			for i := range expected {
+				if names[i] != expected[i] {
+					t.Errorf("Entries = %v, expected %v", names, expected)
+					break
+				}
+			}
+
+			// Extract all: existing src/ is kept, extraction goes to src_1/
+			count, err = extractArchive(archivePath, tmpDir, nil)
+			if err != nil {
+				t.Fatalf("extractArchive: %v", err)
+			}
+			if count != 2 {
+				t.Errorf("Expected 2 files extracted, got %d", count)
+			}
+			data, err := os.ReadFile(filepath.Join(tmpDir, "src_1", "sub", "b.txt"))
+			if err != nil || string(data) != "world" {
+				t.Errorf("Expected extracted src_1/sub/b.txt = world, got %q (%v)", data, err)
+			}
+		})
+	}
+}
+
+func TestExtractArchive_Selected(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := setupArchiveSource(t, tmpDir)
+	archivePath := filepath.Join(tmpDir, "out.zip")
+	if _, err := createArchive(archivePath, []string{src}); err != nil {
+		t.Fatalf("createArchive: %v", err)
+	}
+
+	destDir := filepath.Join(tmpDir, "dest")
+	os.Mkdir(destDir, 0755)
+
+	// Selecting a directory entry extracts everything below it
+	count, err := extractArchive(archivePath, destDir, []string{"src/sub"})
+	if err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 file extracted, got %d", count)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "src", "sub", "b.txt")); err != nil {
+		t.Error("Expected src/sub/b.txt to be extracted")
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "src", "a.txt")); err == nil {
+		t.Error("Expected src/a.txt not to be extracted")
+	}
+}
+
+func TestExtractArchive_SkipsUnsafePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "evil.zip")
+
+	f, _ := os.Create(archivePath)
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"../escape.txt", "/abs.txt", "ok.txt"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte("x"))
+	}
+	zw.Close()
+	f.Close()
+
+	destDir := filepath.Join(tmpDir, "dest")
+	os.Mkdir(destDir, 0755)
+	count, err := extractArchive(archivePath, destDir, nil)
+	if err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected only the safe entry extracted, got %d", count)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "escape.txt")); err == nil {
+		t.Error("Entry escaped the destination directory")
+	}
+}
+
+func TestExtractArchive_SkipsSymlinkChains(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "evil.tar")
+
+	// Each link looks local, but l1/l2 is written through l1 and points two levels up
+	f, _ := os.Create(archivePath)
+	tw := tar.NewWriter(f)
+	tw.WriteHeader(&tar.Header{Name: "t/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "t/l1", Typeflag: tar.TypeSymlink, Linkname: "."})
+	tw.WriteHeader(&tar.Header{Name: "t/l1/l2", Typeflag: tar.TypeSymlink, Linkname: "../.."})
+	tw.WriteHeader(&tar.Header{Name: "t/l2/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 1})
+	tw.Write([]byte("x"))
+	tw.Close()
+	f.Close()
+
+	destDir := filepath.Join(tmpDir, "dest")
+	os.Mkdir(destDir, 0755)
+	if _, err := extractArchive(archivePath, destDir, nil); err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(tmpDir, "pwned")); err == nil {
+		t.Error("Entry escaped the destination directory through a symlink chain")
+	}
+	if info, err := os.Lstat(filepath.Join(destDir, "t", "l2")); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		t.Error("Expected no link written through t/l1")
+	}
+}
+
+func TestCreateArchive_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := setupArchiveSource(t, tmpDir)
+
+	for _, name := range []string{"out.rar", "out.tar.bz2"} {
+		if _, err := createArchive(filepath.Join(tmpDir, name), []string{src}); err == nil {
+			t.Errorf("Expected error creating %s", name)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err == nil {
+			t.Errorf("Expected no file left behind for %s", name)
+		}
+	}
+}
+
+func TestUniqueArchivePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	existing := filepath.Join(tmpDir, "a.tar.gz")
+	os.WriteFile(existing, nil, 0644)
+
+	if got := uniqueArchivePath(existing); got != filepath.Join(tmpDir, "a_1.tar.gz") {
+		t.Errorf("uniqueArchivePath = %q", got)
+	}
+	fresh := filepath.Join(tmpDir, "b.zip")
+	if got := uniqueArchivePath(fresh); got != fresh {
+		t.Errorf("uniqueArchivePath(%q) = %q", fresh, got)
+	}
+}
+
+func TestPreview_ArchiveExtract(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := setupArchiveSource(t, tmpDir)
+	if _, err := createArchive(filepath.Join(tmpDir, "pack.zip"), []string{src}); err != nil {
+		t.Fatalf("createArchive: %v", err)
+	}
+	os.RemoveAll(src)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.height = 20
+	model.selected = 1 // pack.zip
+	model.openPreview()
+	if !model.previewIsArchive {
+		t.Fatal("Expected archive listing")
+	}
+	if len(model.previewContent) != 4 {
+		t.Fatalf("Expected 4 entries, got %v", model.previewContent)
+	}
+
+	// Move to src/a.txt, mark it, extract marked entries
+	m := model
+	for i, e := range m.previewArchive {
+		if e.Name == "src/a.txt" {
+			m.movePreviewCursor(i)
+		}
+	}
+	newModel, _ := m.Update(keyMsg(" "))
+	m = newModel.(Model)
+	if len(m.previewArchiveMarked) != 1 {
+		t.Fatalf("Expected 1 marked entry, got %d", len(m.previewArchiveMarked))
+	}
+	newModel, _ = m.Update(keyMsg("x"))
+	m = newModel.(Model)
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "src", "a.txt")); err != nil {
+		t.Error("Expected src/a.txt to be extracted")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src", "sub", "b.txt")); err == nil {
+		t.Error("Expected unmarked entry not to be extracted")
+	}
+	if len(m.previewArchiveMarked) != 0 {
+		t.Error("Expected marks cleared after extraction")
+	}
+}
+
+func TestNewArchive_FromMarkedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	file1 := filepath.Join(tmpDir, "one.txt")
+	file2 := filepath.Join(tmpDir, "two.txt")
+	os.WriteFile(file1, []byte("1"), 0644)
+	os.WriteFile(file2, []byte("2"), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.marked[file1] = true
+	model.marked[file2] = true
+
+	newModel, _ := model.Update(keyMsg("Z"))
+	m := newModel.(Model)
+	if m.inputMode != ModeNewArchive {
+		t.Fatalf("Expected ModeNewArchive, got %v", m.inputMode)
+	}
+	m.inputBuffer = "bundle.tar.gz"
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	m = newModel.(Model)
+
+	entries, err := listArchive(filepath.Join(tmpDir, "bundle.tar.gz"))
+	if err != nil {
+		t.Fatalf("Expected archive to be created: %v (message %q)", err, m.message)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(entries))
+	}
+	if len(m.marked) != 0 {
+		t.Error("Expected marks cleared after creating archive")
+	}
+}
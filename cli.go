@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Command-line options
+
+// chooseMode restricts what can be picked in chooser mode
+type chooseMode int
+
+const (
+	chooseNone chooseMode = iota // Normal file manager
+	chooseAny                    // Files and directories (--choose)
+	chooseDir                    // Directories only (--choose-dir)
+)
+
+// options holds the parsed command line
+type options struct {
+	Path       string     // Tree root (default ".")
+	Choose     chooseMode // Chooser mode: print the picked paths on exit
+	ChooseFile string     // Write picked paths here instead of stdout
+	Print0     bool       // Separate picked paths with NUL instead of newline
+	CwdFile    string     // Write the last directory here on exit (shell cd integration)
+	ShellInit  string     // Print the wrapper function for this shell and exit
+	Reveal     string     // Expand to this path and select it on startup
+	VCSRoot    bool       // Use the repository root as the tree root
+	Fresh      bool       // Start without restoring the saved session
+}
+
+// parseArgs parses flags and the optional root path, in any order
+func parseArgs(args []string) (options, error) {
+	var opts options
+	var choose, chooseDirs bool
+
+	fs := flag.NewFlagSet("bon3", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&choose, "choose", false, "pick files or directories and print them on exit")
+	fs.BoolVar(&chooseDirs, "choose-dir", false, "pick directories only and print them on exit")
+	fs.StringVar(&opts.ChooseFile, "choose-file", "", "write picked paths to `path` instead of stdout")
+	fs.BoolVar(&opts.Print0, "print0", false, "separate picked paths with NUL instead of newline")
+	fs.StringVar(&opts.CwdFile, "cwd-file", "", "write the last directory to `path` on exit")
+	fs.StringVar(&opts.ShellInit, "shell-init", "", "print the cd-on-exit wrapper for `shell` (bash, zsh, fish)")
+	fs.StringVar(&opts.Reveal, "reveal", "", "expand to `path` and select it (a file argument does the same)")
+	fs.BoolVar(&opts.VCSRoot, "vcs-root", false, "open the repository root containing the path")
+	fs.BoolVar(&opts.Fresh, "fresh", false, "start with only the root expanded (the session is still saved)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: bon3 [flags] [path | file]")
+		fmt.Fprintln(fs.Output(), "       bon3 ctl COMMAND [ARGS]  (see bon3 ctl --help)")
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return options{}, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) > 1 {
+		err := fmt.Errorf("too many paths: %s", strings.Join(positional, " "))
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return options{}, err
+	}
+
+	opts.Path = "."
+	if len(positional) == 1 {
+		opts.Path = positional[0]
+	}
+	switch {
+	case chooseDirs:
+		opts.Choose = chooseDir
+	case choose || opts.ChooseFile != "":
+		opts.Choose = chooseAny
+	}
+	return opts, nil
+}
+
+// startupPaths resolves the tree root and the path to select on startup.
+// A file argument or --reveal outside the given root opens the target's
+// directory; --vcs-root widens the root to the enclosing repository.
+func startupPaths(opts options) (root, reveal string, err error) {
+	root, err = filepath.Abs(opts.Path)
+	if err != nil {
+		return "", "", err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", "", err
+	}
+
+	if opts.Reveal != "" {
+		if reveal, err = filepath.Abs(opts.Reveal); err != nil {
+			return "", "", err
+		}
+		if _, err := os.Stat(reveal); err != nil {
+			return "", "", err
+		}
+		if !info.IsDir() || !isWithin(root, reveal) {
+			root = filepath.Dir(reveal)
+		}
+	} else if !info.IsDir() {
+		reveal = root
+		root = filepath.Dir(root)
+	}
+
+	if opts.VCSRoot {
+		if repo := NewVCSRepo(root); repo.IsInsideRepo() && repo.GetRoot() != "" {
+			root = repo.GetRoot()
+			// The repository root may be reported with symlinks resolved
+			if reveal != "" && !isWithin(root, reveal) {
+				if resolved, err := filepath.EvalSymlinks(reveal); err == nil {
+					reveal = resolved
+				}
+			}
+		}
+	}
+	return root, reveal, nil
+}
+
+// isWithin reports whether path is dir or below it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// formatChosen joins picked paths, each terminated by a newline or NUL
+func formatChosen(paths []string, print0 bool) string {
+	sep := "\n"
+	if print0 {
+		sep = "\x00"
+	}
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString(p)
+		b.WriteString(sep)
+	}
+	return b.String()
+}
+
+// writeChosen writes picked paths to the choose file, or stdout
+func writeChosen(opts options, paths []string) error {
+	data := formatChosen(paths, opts.Print0)
+	if opts.ChooseFile != "" {
+		return os.WriteFile(opts.ChooseFile, []byte(data), 0644)
+	}
+	_, err := os.Stdout.WriteString(data)
+	return err
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected options
+		wantErr  bool
+	}{
+		{"defaults", nil, options{Path: "."}, false},
+		{"path", []string{"~/src"}, options{Path: "~/src"}, false},
+		{"choose", []string{"--choose"}, options{Path: ".", Choose: chooseAny}, false},
+		{"choose dir after path", []string{"/tmp", "--choose-dir", "--print0"}, options{Path: "/tmp", Choose: chooseDir, Print0: true}, false},
+		{"choose file implies choose", []string{"--choose-file=/tmp/out"}, options{Path: ".", Choose: chooseAny, ChooseFile: "/tmp/out"}, false},
+		{"choose dir with file", []string{"--choose-dir", "--choose-file", "/tmp/out"}, options{Path: ".", Choose: chooseDir, ChooseFile: "/tmp/out"}, false},
+		{"reveal", []string{"--reveal", "a.go", "--vcs-root"}, options{Path: ".", Reveal: "a.go", VCSRoot: true}, false},
+		{"unknown flag", []string{"--nope"}, options{}, true},
+		{"two paths", []string{"a", "b"}, options{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devNull, _ := os.Open(os.DevNull)
+			defer devNull.Close()
+			stderr := os.Stderr
+			os.Stderr = devNull // Usage output of failing cases
+			got, err := parseArgs(tt.args)
+			os.Stderr = stderr
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArgs(%v) error = %v", tt.args, err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseArgs(%v) = %+v, expected %+v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatChosen(t *testing.T) {
+	paths := []string{"/a/b", "/c d"}
+	if got := formatChosen(paths, false); got != "/a/b\n/c d\n" {
+		t.Errorf("newline separated = %q", got)
+	}
+	if got := formatChosen(paths, true); got != "/a/b\x00/c d\x00" {
+		t.Errorf("NUL separated = %q", got)
+	}
+
+	out := filepath.Join(t.TempDir(), "chosen")
+	if err := writeChosen(options{ChooseFile: out}, paths); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "/a/b\n/c d\n" {
+		t.Errorf("choose file = %q", data)
+	}
+}
+
+func TestStartupPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "repo", "pkg"), 0755)
+	os.Mkdir(filepath.Join(tmpDir, "other"), 0755)
+	file := filepath.Join(tmpDir, "repo", "pkg", "main.go")
+	os.WriteFile(file, nil, 0644)
+	pkg := filepath.Join(tmpDir, "repo", "pkg")
+
+	tests := []struct {
+		name       string
+		opts       options
+		wantRoot   string
+		wantReveal string
+	}{
+		{"directory", options{Path: pkg}, pkg, ""},
+		{"file argument", options{Path: file}, pkg, file},
+		{"reveal inside root", options{Path: tmpDir, Reveal: file}, tmpDir, file},
+		{"reveal outside root", options{Path: filepath.Join(tmpDir, "other"), Reveal: file}, pkg, file},
+		{"vcs root without repo", options{Path: file, VCSRoot: true}, pkg, file},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, reveal, err := startupPaths(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root != tt.wantRoot || reveal != tt.wantReveal {
+				t.Errorf("startupPaths = %s, %s; expected %s, %s", root, reveal, tt.wantRoot, tt.wantReveal)
+			}
+		})
+	}
+
+	if _, _, err := startupPaths(options{Path: filepath.Join(tmpDir, "missing")}); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+
+	// --vcs-root opens the repository root
+	if _, err := exec.LookPath("git"); err == nil {
+		repo := filepath.Join(tmpDir, "repo")
+		if err := exec.Command("git", "init", "-q", repo).Run(); err != nil {
+			t.Fatal(err)
+		}
+		root, reveal, err := startupPaths(options{Path: file, VCSRoot: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isWithin(root, reveal) || filepath.Base(root) != "repo" {
+			t.Errorf("Expected the repository root, got %s (reveal %s)", root, reveal)
+		}
+	}
+}
+
+func TestChooseMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Mkdir(filepath.Join(tmpDir, "dir"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "a.txt"), nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, "b.txt"), nil, 0644)
+
+	newChooser := func(t *testing.T, mode chooseMode) Model {
+		model, err := NewModel(tmpDir)
+		if err != nil {
+			t.Fatalf("Failed to create model: %v", err)
+		}
+		t.Cleanup(func() {
+			if model.watcher != nil {
+				model.watcher.Close()
+			}
+		})
+		model.height = 20
+		model.chooseMode = mode
+		return model
+	}
+
+	t.Run("enter expands directories and picks files", func(t *testing.T) {
+		m := newChooser(t, chooseAny)
+		m.selected = 1 // dir/
+		next, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+		if cmd != nil || next.(Model).chosen != nil {
+			t.Fatal("Expected Enter on a directory to expand it")
+		}
+
+		m = next.(Model)
+		m.selected = 2 // a.txt
+		next, cmd = m.Update(specialKeyMsg(tea.KeyEnter))
+		if cmd == nil || len(next.(Model).chosen) != 1 || filepath.Base(next.(Model).chosen[0]) != "a.txt" {
+			t.Errorf("Expected a.txt picked, got %v", next.(Model).chosen)
+		}
+	})
+
+	t.Run("ctrl+s picks a directory", func(t *testing.T) {
+		m := newChooser(t, chooseAny)
+		m.selected = 1
+		next, _ := m.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+		if got := next.(Model).chosen; len(got) != 1 || filepath.Base(got[0]) != "dir" {
+			t.Errorf("Expected dir picked, got %v", got)
+		}
+	})
+
+	t.Run("marked paths", func(t *testing.T) {
+		m := newChooser(t, chooseAny)
+		m.marked[filepath.Join(tmpDir, "b.txt")] = true
+		m.marked[filepath.Join(tmpDir, "a.txt")] = true
+		next, _ := m.Update(specialKeyMsg(tea.KeyEnter))
+		if got := next.(Model).chosen; len(got) != 2 || filepath.Base(got[0]) != "a.txt" {
+			t.Errorf("Expected marked files in order, got %v", got)
+		}
+	})
+
+	t.Run("directories only", func(t *testing.T) {
+		m := newChooser(t, chooseDir)
+		m.selected = 2 // a.txt
+		next, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+		if cmd != nil || next.(Model).chosen != nil {
+			t.Error("Expected a file to be refused")
+		}
+		m.selected = 1
+		next, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+		if got := next.(Model).chosen; len(got) != 1 || filepath.Base(got[0]) != "dir" {
+			t.Errorf("Expected dir picked, got %v", got)
+		}
+	})
+}
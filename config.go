@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds user settings read from config.toml.
+// Every setting is optional; the zero value means the built-in default.
+type Config struct {
+	Preview PreviewConfig `toml:"preview"`
+
+	// Openers maps an extension (".pdf"), MIME type ("image/png") or MIME
+	// pattern ("image/*", "*") to the command run by O ([openers] table)
+	Openers map[string]string `toml:"openers"`
+
+	Agent AgentConfig `toml:"agent"`
+
+	Watch WatchConfig `toml:"watch"`
+}
+
+// PreviewConfig holds preview settings ([preview] table)
+type PreviewConfig struct {
+	// ImageProtocol forces the inline image protocol:
+	// "auto" (default), "kitty", "sixel", "iterm2" or "ascii"
+	ImageProtocol string `toml:"image_protocol"`
+}
+
+// WatchConfig holds file watcher settings ([watch] table)
+type WatchConfig struct {
+	// Ignore lists paths whose changes don't refresh the tree: a name matches
+	// anywhere ("node_modules", "*.swp"), a path with a slash from the root
+	// ("/dist", ".git/objects"). Unset means DefaultWatchIgnore; [] ignores nothing.
+	Ignore []string `toml:"ignore"`
+	// Backend is "auto" (fsnotify, polling when it fails) or "poll"
+	Backend string `toml:"backend"`
+	// RecentSeconds is how long changed files keep their badge
+	// (0 = DefaultRecentSeconds, negative = no badges)
+	RecentSeconds int `toml:"recent_seconds"`
+}
+
+// AgentConfig holds send-to-agent settings ([agent] table).
+// Targets are "tmux:<pane>", "fifo:<path>" or "unix:<path>".
+type AgentConfig struct {
+	// Target receives paths without asking (empty = pick on first send)
+	Target string `toml:"target"`
+	// Targets are offered in the picker next to the tmux panes
+	Targets []string `toml:"targets"`
+}
+
+// configPath returns the config file location ($XDG_CONFIG_HOME/bon3/config.toml)
+func configPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "bon3", "config.toml")
+}
+
+// LoadConfig reads the config file; a missing file yields the defaults
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := parseImageProtocol(cfg.Preview.ImageProtocol); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, t := range append([]string{cfg.Agent.Target}, cfg.Agent.Targets...) {
+		if t == "" {
+			continue
+		}
+		if _, err := parseAgentTarget(t); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	switch cfg.Watch.Backend {
+	case "", "auto", "poll":
+	default:
+		return Config{}, fmt.Errorf("%s: unknown watch backend %q (auto or poll)", path, cfg.Watch.Backend)
+	}
+	for _, pattern := range cfg.Watch.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return Config{}, fmt.Errorf("%s: watch ignore %q: %w", path, pattern, err)
+		}
+	}
+	if len(cfg.Openers) > 0 {
+		// Extensions and MIME types match case-insensitively
+		openers := make(map[string]string, len(cfg.Openers))
+		for key, cmd := range cfg.Openers {
+			openers[strings.ToLower(key)] = cmd
+		}
+		cfg.Openers = openers
+	}
+	return cfg, nil
+}
+
+// applyConfig applies loaded settings to the model
+func (m *Model) applyConfig(cfg Config) {
+	m.config = cfg
+	if p, _ := parseImageProtocol(cfg.Preview.ImageProtocol); p != imageProtocolAuto {
+		m.imageProtocol = p
+		m.imageForced = true
+	}
+	if t, err := parseAgentTarget(cfg.Agent.Target); err == nil {
+		m.agentTarget = &t
+	}
+	if m.watcher != nil {
+		if cfg.Watch.Backend == "poll" && m.watcher.Backend() != "poll" {
+			m.watcher.Close()
+			watcher, err := m.startWatcher(m.tree.Root.Path)
+			if err != nil {
+				// Report watching as off rather than leave it on without a watcher
+				m.watcher = nil
+				m.watcherEnabled = false
+				m.message = fmt.Sprintf("Error: %v", err)
+				return
+			}
+			m.watcher = watcher
+		} else {
+			m.watcher.SetIgnore(cfg.Watch.Ignore)
+		}
+	}
+}
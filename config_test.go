@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("missing file uses defaults", func(t *testing.T) {
+		cfg, err := LoadConfig(filepath.Join(tmpDir, "nope.toml"))
+		if err != nil || cfg.Preview.ImageProtocol != "" {
+			t.Errorf("LoadConfig = %+v, %v", cfg, err)
+		}
+	})
+
+	t.Run("image protocol", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "config.toml")
+		os.WriteFile(path, []byte("[preview]\nimage_protocol = \"sixel\"\n"), 0644)
+		cfg, err := LoadConfig(path)
+		if err != nil || cfg.Preview.ImageProtocol != "sixel" {
+			t.Errorf("LoadConfig = %+v, %v", cfg, err)
+		}
+	})
+
+	t.Run("openers", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "openers.toml")
+		os.WriteFile(path, []byte("[openers]\n\".PDF\" = \"zathura\"\n\"image/*\" = \"feh\"\n"), 0644)
+		cfg, err := LoadConfig(path)
+		if err != nil || cfg.Openers[".pdf"] != "zathura" || cfg.Openers["image/*"] != "feh" {
+			t.Errorf("LoadConfig = %+v, %v", cfg, err)
+		}
+	})
+
+	t.Run("agent targets", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "agent.toml")
+		os.WriteFile(path, []byte("[agent]\ntarget = \"tmux:%2\"\ntargets = [\"fifo:/tmp/a\"]\n"), 0644)
+		cfg, err := LoadConfig(path)
+		if err != nil || cfg.Agent.Target != "tmux:%2" || len(cfg.Agent.Targets) != 1 {
+			t.Errorf("LoadConfig = %+v, %v", cfg, err)
+		}
+
+		os.WriteFile(path, []byte("[agent]\ntargets = [\"pane 2\"]\n"), 0644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Error("Expected error for invalid agent target")
+		}
+	})
+
+	t.Run("watch ignore", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "watch.toml")
+		os.WriteFile(path, []byte("[watch]\nignore = [\"target\", \"*.swp\"]\n"), 0644)
+		cfg, err := LoadConfig(path)
+		if err != nil || len(cfg.Watch.Ignore) != 2 {
+			t.Errorf("LoadConfig = %+v, %v", cfg, err)
+		}
+
+		os.WriteFile(path, []byte("[watch]\nignore = [\"[\"]\n"), 0644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Error("Expected error for a bad pattern")
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "bad.toml")
+		os.WriteFile(path, []byte("[preview]\nimage_protocol = \"png\"\n"), 0644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Error("Expected error for unknown protocol")
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "broken.toml")
+		os.WriteFile(path, []byte("[preview\n"), 0644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Error("Expected parse error")
+		}
+	})
+}
+
+func TestConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if got := configPath(); got != "/tmp/xdg/bon3/config.toml" {
+		t.Errorf("configPath = %q", got)
+	}
+}
+
+func TestApplyConfig_PollWatcherFails(t *testing.T) {
+	tmpDir := t.TempDir()
+	root := filepath.Join(tmpDir, "root")
+	os.Mkdir(root, 0755)
+
+	model, err := NewModel(root)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if model.watcher == nil {
+		t.Skip("watcher unavailable")
+	}
+
+	// The poll backend can't start once the root is gone
+	os.Remove(root)
+	model.applyConfig(Config{Watch: WatchConfig{Backend: "poll"}})
+	if model.watcher != nil || model.watcherEnabled {
+		t.Error("Expected watching reported as off")
+	}
+	if !strings.HasPrefix(model.message, "Error: ") {
+		t.Errorf("Expected the error in the status bar, got %q", model.message)
+	}
+}
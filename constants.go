@@ -8,20 +8,115 @@ const (
 	// DebounceDropMs is the wait time for paste/drop completion
 	DebounceDropMs = 100
 
-	// DebounceWatchMs is the debounce interval for file watcher events
+	// DebounceWatchMs is how long file events must stop before the tree updates
 	DebounceWatchMs = 200
 
+	// MaxWaitWatchMs caps the debounce during a continuous stream of events
+	MaxWaitWatchMs = 1000
+
+	// PollMinIntervalMs is the polling interval right after a change
+	PollMinIntervalMs = 500
+
+	// PollMaxIntervalMs is the polling interval once the tree has been idle
+	PollMaxIntervalMs = 4000
+
 	// DoubleClickMs is the maximum interval between clicks for double-click
 	DoubleClickMs = 400
+
+	// StreamFollowMs is the polling interval for follow mode in streaming previews
+	StreamFollowMs = 500
+
+	// ImageDrawDelayMs is the wait before drawing an inline image, so it lands after the repaint
+	ImageDrawDelayMs = 50
+
+	// SplitPreviewDebounceMs is how long the selection must rest before the split pane loads
+	SplitPreviewDebounceMs = 100
 )
 
 // Size constants
 const (
-	// MaxPreviewBytes is the maximum file size for preview (512KB)
+	// MaxPreviewBytes is the largest file loaded into memory for preview (512KB);
+	// larger files are streamed
 	MaxPreviewBytes = 512 * 1024
 
-	// MaxHexPreviewBytes is the maximum bytes to show in hex preview (100 lines)
-	MaxHexPreviewBytes = 1600
+	// MaxPreviewMatches is the maximum number of search matches tracked in preview
+	MaxPreviewMatches = 10000
+
+	// MaxHighlightCacheEntries is the number of files whose syntax highlighting is cached
+	MaxHighlightCacheEntries = 32
+
+	// PollScanFactor keeps the poll interval at least this many times the
+	// time a scan takes, so huge trees aren't rescanned back to back
+	PollScanFactor = 4
+
+	// MaxWatchBatch is the most changed paths applied to the tree one by one;
+	// bigger bursts (checkouts, builds) trigger a full refresh instead
+	MaxWatchBatch = 256
+)
+
+// Streaming preview constants
+const (
+	// MaxStreamLineBytes is the longest line shown in a streaming preview;
+	// longer lines are split into several rows
+	MaxStreamLineBytes = 4096
+
+	// StreamIndexInterval is the number of lines between line index checkpoints
+	StreamIndexInterval = 1024
+
+	// StreamChunkBytes is the read size for indexing and searching streamed files
+	StreamChunkBytes = 1024 * 1024
+
+	// StreamIndexStepBytes is how much of a file is indexed per background step
+	StreamIndexStepBytes = 32 * 1024 * 1024
+
+	// StreamIncrementalSearchBytes bounds the search done while typing a query;
+	// Enter searches the whole file
+	StreamIncrementalSearchBytes = 8 * 1024 * 1024
+)
+
+// Preview layout constants
+const (
+	// PreviewHScrollStep is the number of columns scrolled per h/l in preview
+	PreviewHScrollStep = 8
+
+	// PreviewTabWidth is the tab stop width used when rendering preview lines
+	PreviewTabWidth = 4
+
+	// MaxTOCWidth is the maximum width of the Markdown table of contents sidebar
+	MaxTOCWidth = 30
+
+	// DataTreeExpandDepth is how many levels of a JSON/YAML/TOML tree start expanded
+	DataTreeExpandDepth = 2
+
+	// MaxTableColumnWidth caps the width of a CSV/TSV column
+	MaxTableColumnWidth = 40
+
+	// MinTableColumnWidth is the narrowest a column is shrunk to fit the terminal
+	MinTableColumnWidth = 6
+)
+
+// Split-pane preview constants
+const (
+	// SplitPreviewBytes is how much of a file the split pane reads
+	SplitPreviewBytes = 64 * 1024
+
+	// SplitDefaultPercent is the initial tree width as a percentage of the terminal
+	SplitDefaultPercent = 40
+
+	// SplitMinPercent and SplitMaxPercent bound the tree width when resizing
+	SplitMinPercent = 20
+	SplitMaxPercent = 80
+
+	// SplitResizeStep is the width change per < or > in percent
+	SplitResizeStep = 5
+)
+
+// Inline image constants
+const (
+	// DefaultCellWidthPx and DefaultCellHeightPx are the assumed cell size in
+	// pixels until the terminal reports its own
+	DefaultCellWidthPx  = 10
+	DefaultCellHeightPx = 20
 )
 
 // Completion display constants
@@ -29,3 +124,30 @@ const (
 	// MaxCompletionVisible is the maximum number of completion candidates to display
 	MaxCompletionVisible = 5
 )
+
+// Recent changes constants
+const (
+	// DefaultRecentSeconds is how long a changed file keeps its badge
+	DefaultRecentSeconds = 30
+
+	// MaxRecentChanges is how many changed paths the gR panel remembers
+	MaxRecentChanges = 100
+)
+
+// Shell command constants
+const (
+	// MaxShellHistory is how many commands the ! prompt remembers
+	MaxShellHistory = 100
+
+	// MaxShellOutputLines caps the captured output (the tail is kept)
+	MaxShellOutputLines = 10000
+
+	// ShellWaitDelayMs is how long a cancelled command's output is waited for
+	// after it was killed
+	ShellWaitDelayMs = 500
+)
+
+// DefaultWatchIgnore lists paths whose changes don't refresh the tree: package
+// and VCS stores anywhere, build output only at the top, since source packages
+// are named build or dist too (overridden by [watch] ignore in config.toml)
+var DefaultWatchIgnore = []string{"node_modules", ".git/objects", "__pycache__", "/dist", "/build", "/target"}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Remote control: a Unix socket that accepts JSON-line commands so editors
+// and agent hooks can keep the tree in sync with what they are working on.
+
+// ControlSocketEnv points child processes (shell commands, editors) at the socket
+const ControlSocketEnv = "BON3_SOCKET"
+
+// controlRequest is one command line sent by a client
+type controlRequest struct {
+	Cmd   string   `json:"cmd"`
+	Path  string   `json:"path,omitempty"`
+	Paths []string `json:"paths,omitempty"`
+}
+
+// controlSelection describes the tree state reported to clients
+type controlSelection struct {
+	Root   string   `json:"root"`
+	Path   string   `json:"path"`
+	Marked []string `json:"marked"`
+}
+
+// controlResponse answers a request
+type controlResponse struct {
+	OK        bool              `json:"ok"`
+	Error     string            `json:"error,omitempty"`
+	Selection *controlSelection `json:"selection,omitempty"`
+}
+
+// controlEvent is pushed to subscribers when the selection changes
+type controlEvent struct {
+	Event string `json:"event"`
+	controlSelection
+}
+
+// controlMsg delivers a request to Update; the answer goes back on reply
+type controlMsg struct {
+	req   controlRequest
+	reply chan controlResponse
+}
+
+// ControlServer listens on the control socket
+type ControlServer struct {
+	path     string
+	listener net.Listener
+	requests chan controlMsg
+	closed   chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+	subs     map[chan []byte]struct{}
+}
+
+// controlSocketDir is where sockets live: $XDG_RUNTIME_DIR, else a per-user
+// bon3-<uid> directory in the temp dir
+func controlSocketDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "bon3-"+strconv.Itoa(os.Getuid()))
+}
+
+// controlSocketPath returns the socket path for the given process
+func controlSocketPath(pid int) string {
+	return filepath.Join(controlSocketDir(), "bon3-"+strconv.Itoa(pid)+".sock")
+}
+
+// NewControlServer listens on path, replacing a stale socket left by a crash.
+// The socket's directory must be private (created 0700 if missing): the socket
+// is reachable as soon as Listen returns, before its own mode can be changed.
+func NewControlServer(path string) (*ControlServer, error) {
+	if err := ensurePrivateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: already in use", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	os.Chmod(path, 0600)
+
+	s := &ControlServer{
+		path:     path,
+		listener: listener,
+		requests: make(chan controlMsg),
+		closed:   make(chan struct{}),
+		subs:     make(map[chan []byte]struct{}),
+	}
+	go s.accept()
+	return s, nil
+}
+
+// ensurePrivateDir creates dir for the current user only, or checks that an
+// existing one is a directory no one else can enter
+func ensurePrivateDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || info.Mode().Perm()&0077 != 0 || !ownedByCurrentUser(info) {
+		return fmt.Errorf("%s: not a private directory", dir)
+	}
+	return nil
+}
+
+// Path returns the socket path
+func (s *ControlServer) Path() string {
+	return s.path
+}
+
+func (s *ControlServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+// serve answers request lines until the client hangs up or subscribes
+func (s *ControlServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req controlRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(controlResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+
+		if req.Cmd == "subscribe" {
+			// Register before answering so no change after the answer is missed
+			events := make(chan []byte, 16)
+			s.mu.Lock()
+			s.subs[events] = struct{}{}
+			s.mu.Unlock()
+			defer func() {
+				s.mu.Lock()
+				delete(s.subs, events)
+				s.mu.Unlock()
+			}()
+
+			// Answer with the current selection, then stream events
+			resp, ok := s.dispatch(controlRequest{Cmd: "selection"})
+			if !ok {
+				return
+			}
+			enc.Encode(resp)
+			s.stream(conn, scanner, events)
+			return
+		}
+
+		resp, ok := s.dispatch(req)
+		if !ok {
+			return
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// dispatch hands a request to Update and waits for the answer
+func (s *ControlServer) dispatch(req controlRequest) (controlResponse, bool) {
+	reply := make(chan controlResponse, 1)
+	select {
+	case s.requests <- controlMsg{req: req, reply: reply}:
+	case <-s.closed:
+		return controlResponse{}, false
+	}
+	select {
+	case resp := <-reply:
+		return resp, true
+	case <-s.closed:
+		return controlResponse{}, false
+	}
+}
+
+// stream writes selection events to a subscriber until it disconnects
+func (s *ControlServer) stream(conn net.Conn, scanner *bufio.Scanner, events chan []byte) {
+	// Reading hits EOF when the client goes away
+	gone := make(chan struct{})
+	go func() {
+		for scanner.Scan() {
+		}
+		close(gone)
+	}()
+
+	for {
+		select {
+		case line := <-events:
+			if _, err := conn.Write(line); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// Listen returns a command that waits for the next client request
+func (s *ControlServer) Listen() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-s.requests:
+			return msg
+		case <-s.closed:
+			return nil
+		}
+	}
+}
+
+// HasSubscribers reports whether anyone is waiting for selection events
+func (s *ControlServer) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs) > 0
+}
+
+// Publish sends a selection event to every subscriber; slow ones miss events
+func (s *ControlServer) Publish(sel controlSelection) {
+	data, err := json.Marshal(controlEvent{Event: "selection", controlSelection: sel})
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		select {
+		case sub <- data:
+		default:
+		}
+	}
+}
+
+// Close stops listening and removes the socket
+func (s *ControlServer) Close() error {
+	if s == nil {
+		return nil
+	}
+	var err error
+	s.once.Do(func() {
+		close(s.closed)
+		err = s.listener.Close()
+		os.Remove(s.path)
+	})
+	return err
+}
+
+// findControlSocket picks the socket for bon3 ctl: $BON3_SOCKET, else the
+// most recently started instance in the socket directory
+func findControlSocket() (string, error) {
+	if path := os.Getenv(ControlSocketEnv); path != "" {
+		return path, nil
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(controlSocketDir(), "bon3-*.sock"))
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var live []candidate
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.Mode()&os.ModeSocket == 0 {
+			continue
+		}
+		// Skip sockets left behind by instances that crashed
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+		live = append(live, candidate{path, info.ModTime()})
+	}
+	if len(live) == 0 {
+		return "", errors.New("no running bon3 found (set " + ControlSocketEnv + " or use --socket)")
+	}
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].modTime.After(live[j].modTime)
+	})
+	return live[0].path, nil
+}
+
+// controlUsage documents the bon3 ctl subcommand
+const controlUsage = `Usage: bon3 ctl [--socket PATH] COMMAND [ARGS]
+
+Commands:
+  reveal PATH      Expand to PATH and select it
+  cd DIR           Change the tree root
+  refresh          Reload the tree and VCS status
+  mark PATH...     Mark paths
+  unmark PATH...   Unmark paths (all marks when none given)
+  selection        Print the root, selected path and marks as JSON
+  subscribe        Print a JSON line on every selection change
+`
+
+// parseControlArgs turns bon3 ctl arguments into a socket path and request
+func parseControlArgs(args []string, cwd string) (string, controlRequest, error) {
+	var socket string
+	for len(args) > 0 {
+		switch {
+		case args[0] == "--socket" && len(args) > 1:
+			socket = args[1]
+			args = args[2:]
+			continue
+		case strings.HasPrefix(args[0], "--socket="):
+			socket = strings.TrimPrefix(args[0], "--socket=")
+			args = args[1:]
+			continue
+		}
+		break
+	}
+	if len(args) == 0 {
+		return "", controlRequest{}, errors.New("missing command")
+	}
+
+	abs := func(path string) string {
+		if filepath.IsAbs(path) {
+			return filepath.Clean(path)
+		}
+		return filepath.Join(cwd, path)
+	}
+
+	req := controlRequest{Cmd: args[0]}
+	rest := args[1:]
+	switch req.Cmd {
+	case "reveal", "cd":
+		if len(rest) != 1 {
+			return "", req, fmt.Errorf("%s takes one path", req.Cmd)
+		}
+		req.Path = abs(rest[0])
+	case "mark", "unmark":
+		if req.Cmd == "mark" && len(rest) == 0 {
+			return "", req, errors.New("mark takes at least one path")
+		}
+		for _, path := range rest {
+			req.Paths = append(req.Paths, abs(path))
+		}
+	case "refresh", "selection", "subscribe":
+		if len(rest) != 0 {
+			return "", req, fmt.Errorf("%s takes no arguments", req.Cmd)
+		}
+	default:
+		return "", req, fmt.Errorf("unknown command %q", req.Cmd)
+	}
+	return socket, req, nil
+}
+
+// runControl implements bon3 ctl, returning the exit status
+func runControl(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		fmt.Fprint(stdout, controlUsage)
+		return 0
+	}
+
+	cwd, _ := os.Getwd()
+	socket, req, err := parseControlArgs(args, cwd)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n\n%s", err, controlUsage)
+		return 2
+	}
+	if socket == "" {
+		if socket, err = findControlSocket(); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if err := sendControl(socket, req, stdout); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// sendControl sends req and copies the answer (and any events) to out
+func sendControl(socket string, req controlRequest, out io.Writer) error {
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return err
+	}
+	var resp controlResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return err
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+	if req.Cmd == "selection" || req.Cmd == "subscribe" {
+		out.Write(line)
+	}
+
+	if req.Cmd == "subscribe" {
+		_, err := io.Copy(out, reader)
+		return err
+	}
+	return nil
+}
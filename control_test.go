@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseControlArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		socket   string
+		expected controlRequest
+		wantErr  bool
+	}{
+		{"reveal relative", []string{"reveal", "a/b.go"}, "", controlRequest{Cmd: "reveal", Path: "/work/a/b.go"}, false},
+		{"cd absolute", []string{"--socket", "/s.sock", "cd", "/tmp"}, "/s.sock", controlRequest{Cmd: "cd", Path: "/tmp"}, false},
+		{"mark", []string{"--socket=/s.sock", "mark", "x", "/y"}, "/s.sock", controlRequest{Cmd: "mark", Paths: []string{"/work/x", "/y"}}, false},
+		{"unmark all", []string{"unmark"}, "", controlRequest{Cmd: "unmark"}, false},
+		{"selection", []string{"selection"}, "", controlRequest{Cmd: "selection"}, false},
+		{"missing command", nil, "", controlRequest{}, true},
+		{"reveal without path", []string{"reveal"}, "", controlRequest{}, true},
+		{"mark without paths", []string{"mark"}, "", controlRequest{}, true},
+		{"refresh with args", []string{"refresh", "x"}, "", controlRequest{}, true},
+		{"unknown", []string{"explode"}, "", controlRequest{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socket, req, err := parseControlArgs(tt.args, "/work")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseControlArgs(%v) error = %v", tt.args, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if socket != tt.socket || !reflect.DeepEqual(req, tt.expected) {
+				t.Errorf("parseControlArgs(%v) = %q, %+v; expected %q, %+v", tt.args, socket, req, tt.socket, tt.expected)
+			}
+		})
+	}
+}
+
+// newControlModel starts a model with a control socket and runs its Update loop
+// for control requests in the background
+func newControlModel(t *testing.T, root string) string {
+	t.Helper()
+
+	// Unix socket paths are short; t.TempDir() can be too long
+	sockDir, err := os.MkdirTemp("", "bon3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sockDir) })
+	socket := filepath.Join(sockDir, "ctl.sock")
+
+	model, err := NewModel(root)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if model.watcher != nil {
+		model.watcher.Close()
+		model.watcher = nil
+	}
+	model.height = 20
+	model.control, err = NewControlServer(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { model.control.Close() })
+
+	go func() {
+		m := model
+		for {
+			msg := m.control.Listen()()
+			if msg == nil {
+				return
+			}
+			next, _ := m.Update(msg)
+			m = next.(Model)
+		}
+	}()
+	return socket
+}
+
+func TestControlServer(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "a", "b"), 0755)
+	target := filepath.Join(tmpDir, "a", "b", "c.go")
+	os.WriteFile(target, nil, 0644)
+	other := filepath.Join(tmpDir, "z.txt")
+	os.WriteFile(other, nil, 0644)
+
+	socket := newControlModel(t, tmpDir)
+
+	// Subscribe first so the reveal below produces an event
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	json.NewEncoder(conn).Encode(controlRequest{Cmd: "subscribe"})
+	events := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if line, err := events.ReadString('\n'); err != nil || !strings.Contains(line, `"ok":true`) {
+		t.Fatalf("subscribe answer = %q, %v", line, err)
+	}
+
+	if err := sendControl(socket, controlRequest{Cmd: "reveal", Path: target}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	line, err := events.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event controlEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Event != "selection" || event.Path != target {
+		t.Errorf("Expected a selection event for %s, got %s", target, line)
+	}
+
+	if err := sendControl(socket, controlRequest{Cmd: "mark", Paths: []string{other}}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := sendControl(socket, controlRequest{Cmd: "selection"}, &out); err != nil {
+		t.Fatalf("selection: %v", err)
+	}
+	var resp controlResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Selection == nil || resp.Selection.Path != target || !reflect.DeepEqual(resp.Selection.Marked, []string{other}) {
+		t.Errorf("Unexpected selection %s", out.String())
+	}
+
+	if err := sendControl(socket, controlRequest{Cmd: "reveal", Path: filepath.Join(tmpDir, "missing")}, &out); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+	if err := sendControl(socket, controlRequest{Cmd: "cd", Path: other}, &out); err == nil {
+		t.Error("Expected an error changing the root to a file")
+	}
+}
+
+func TestNewControlServer_PrivateDir(t *testing.T) {
+	base, err := os.MkdirTemp("", "bon3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(base) })
+
+	// A missing socket directory is created for the user only
+	s, err := NewControlServer(filepath.Join(base, "run", "ctl.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if info, err := os.Stat(filepath.Join(base, "run")); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected a 0700 socket directory, got %v (%v)", info.Mode(), err)
+	}
+
+	// A directory others can enter is refused before listening
+	shared := filepath.Join(base, "shared")
+	os.Mkdir(shared, 0755)
+	os.Chmod(shared, 0755)
+	if _, err := NewControlServer(filepath.Join(shared, "ctl.sock")); err == nil {
+		t.Error("Expected an error for a shared socket directory")
+	}
+	if _, err := os.Lstat(filepath.Join(shared, "ctl.sock")); err == nil {
+		t.Error("Expected no socket in the shared directory")
+	}
+}
+
+func TestControlCd_KeepsWatching(t *testing.T) {
+	tmpDir := t.TempDir()
+	sub := filepath.Join(tmpDir, "sub")
+	os.Mkdir(sub, 0755)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if model.watcher == nil {
+		t.Skip("watcher unavailable")
+	}
+
+	reply := make(chan controlResponse, 1)
+	next, cmd := model.handleControl(controlMsg{req: controlRequest{Cmd: "cd", Path: sub}, reply: reply})
+	m := next.(Model)
+	defer func() {
+		if m.watcher != nil {
+			m.watcher.Close()
+		}
+	}()
+	if resp := <-reply; !resp.OK {
+		t.Fatalf("cd failed: %s", resp.Error)
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command starting the new watcher")
+	}
+
+	// The returned command delivers changes in the new root
+	msgs := make(chan tea.Msg, 1)
+	go func() { msgs <- cmd() }()
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(filepath.Join(sub, "new.txt"), nil, 0644)
+	select {
+	case msg := <-msgs:
+		if _, ok := msg.(FileChangeMsg); !ok {
+			t.Errorf("Expected a FileChangeMsg, got %T", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Expected a FileChangeMsg after cd")
+	}
+}
+
+func TestControlServer_HasSubscribers(t *testing.T) {
+	sockDir, err := os.MkdirTemp("", "bon3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sockDir) })
+	s, err := NewControlServer(filepath.Join(sockDir, "ctl.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	go func() {
+		for {
+			msg, ok := s.Listen()().(controlMsg)
+			if !ok {
+				return
+			}
+			msg.reply <- controlResponse{OK: true}
+		}
+	}()
+
+	if s.HasSubscribers() {
+		t.Error("Expected no subscribers before anyone subscribes")
+	}
+
+	conn, err := net.Dial("unix", s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewEncoder(conn).Encode(controlRequest{Cmd: "subscribe"})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	// Registered by the time the answer arrives, so no later change is missed
+	if !s.HasSubscribers() {
+		t.Error("Expected a subscriber after the subscribe answer")
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.HasSubscribers() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.HasSubscribers() {
+		t.Error("Expected the subscriber to go away with its connection")
+	}
+}
@@ -39,8 +39,7 @@ func (m *Model) checkDropBuffer() {
 					} else {
 						m.message = "Dropped: " + name
 					}
-					m.tree.Refresh()
-					m.vcsRepo.Refresh(m.tree.Root.Path)
+					m.refreshTreeAndVCS()
 				} else {
 					m.message = "Copy error: " + err.Error()
 				}
@@ -186,8 +185,7 @@ func (m *Model) tryHandleAsDrop() bool {
 
 		if _, err := CopyFile(normalized, destDir); err == nil {
 			m.message = "Dropped: " + filepath.Base(normalized)
-			m.tree.Refresh()
-			m.vcsRepo.Refresh(m.tree.Root.Path)
+			m.refreshTreeAndVCS()
 			return true
 		} else {
 			m.message = "Copy error: " + err.Error()
@@ -215,8 +213,7 @@ func (m *Model) tryHandleAsDrop() bool {
 
 	if success > 0 {
 		m.message = fmt.Sprintf("Dropped %d item(s)", success)
-		m.tree.Refresh()
-		m.vcsRepo.Refresh(m.tree.Root.Path)
+		m.refreshTreeAndVCS()
 		return true
 	}
 
@@ -244,7 +241,6 @@ func (m *Model) handleDrop(text string) {
 
 	if success > 0 {
 		m.message = fmt.Sprintf("Dropped %d item(s)", success)
-		m.tree.Refresh()
-		m.vcsRepo.Refresh(m.tree.Root.Path)
+		m.refreshTreeAndVCS()
 	}
 }
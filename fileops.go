@@ -67,18 +67,21 @@ func (c *Clipboard) rebuildSet() {
 	}
 }
 
-// CopyFile copies a file or directory to the destination directory
+// CopyFile copies a file or directory to the destination directory.
+// Symlinks are copied as links with the same target, broken ones included.
 func CopyFile(src, destDir string) (string, error) {
 	fileName := filepath.Base(src)
 	dest := filepath.Join(destDir, fileName)
 	dest = getUniquePath(dest)
 
-	srcInfo, err := os.Stat(src)
+	srcInfo, err := os.Lstat(src)
 	if err != nil {
 		return "", err
 	}
 
-	if srcInfo.IsDir() {
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		err = copySymlink(src, dest)
+	} else if srcInfo.IsDir() {
 		err = copyDirRecursive(src, dest)
 	} else {
 		err = copyFileOnly(src, dest)
@@ -103,12 +106,19 @@ func MoveFile(src, destDir string) (string, error) {
 	}
 
 	// If rename fails (cross-device), copy then delete
-	srcInfo, err := os.Stat(src)
+	srcInfo, err := os.Lstat(src)
 	if err != nil {
 		return "", err
 	}
 
-	if srcInfo.IsDir() {
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		if err := copySymlink(src, dest); err != nil {
+			return "", err
+		}
+		if err := os.Remove(src); err != nil {
+			return "", err
+		}
+	} else if srcInfo.IsDir() {
 		if err := copyDirRecursive(src, dest); err != nil {
 			return "", err
 		}
@@ -127,9 +137,79 @@ func MoveFile(src, destDir string) (string, error) {
 	return dest, nil
 }
 
-// DeleteFile deletes a file or directory
+// LinkType is the kind of link created by pasting as a link
+type LinkType int
+
+const (
+	LinkSymlinkRelative LinkType = iota
+	LinkSymlinkAbsolute
+	LinkHard
+)
+
+// String returns the link type as shown in the status bar
+func (t LinkType) String() string {
+	switch t {
+	case LinkSymlinkAbsolute:
+		return "absolute symlink"
+	case LinkHard:
+		return "hard link"
+	default:
+		return "relative symlink"
+	}
+}
+
+// LinkFile creates a link to src in the destination directory, named like
+// src (with a _N suffix if taken)
+func LinkFile(src, destDir string, linkType LinkType) (string, error) {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return "", err
+	}
+	dest := getUniquePath(filepath.Join(destDir, filepath.Base(src)))
+	if dest == "" {
+		return "", fmt.Errorf("%s: no free name in %s", filepath.Base(src), destDir)
+	}
+
+	switch linkType {
+	case LinkHard:
+		if srcInfo.IsDir() {
+			return "", fmt.Errorf("%s: cannot hard link a directory", filepath.Base(src))
+		}
+		err = os.Link(src, dest)
+	case LinkSymlinkAbsolute:
+		err = os.Symlink(src, dest)
+	default:
+		var target string
+		if target, err = relativeLinkTarget(src, destDir); err == nil {
+			err = os.Symlink(target, dest)
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// relativeLinkTarget returns src relative to the directory a link is created
+// in. Both directories are resolved first, since the kernel follows a
+// relative target from the link's real location.
+func relativeLinkTarget(src, destDir string) (string, error) {
+	srcDir, err := filepath.EvalSymlinks(filepath.Dir(src))
+	if err != nil {
+		return "", err
+	}
+	realDest, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(realDest, filepath.Join(srcDir, filepath.Base(src)))
+}
+
+// DeleteFile deletes a file or directory. A symlink is removed itself,
+// never what it points to.
 func DeleteFile(path string) error {
-	info, err := os.Stat(path)
+	info, err := os.Lstat(path)
 	if err != nil {
 		// Already deleted (ghost node) - treat as success
 		if os.IsNotExist(err) {
@@ -250,6 +330,15 @@ func copyFileOnly(src, dest string) error {
 	return err
 }
 
+// copySymlink creates a link at dest with the same target as the link at src
+func copySymlink(src, dest string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, dest)
+}
+
 // copyDirRecursive copies a directory recursively
 func copyDirRecursive(src, dest string) error {
 	srcInfo, err := os.Stat(src)
@@ -270,7 +359,11 @@ func copyDirRecursive(src, dest string) error {
 		srcPath := filepath.Join(src, entry.Name())
 		destPath := filepath.Join(dest, entry.Name())
 
-		if entry.IsDir() {
+		if entry.Type()&os.ModeSymlink != 0 {
+			if err := copySymlink(srcPath, destPath); err != nil {
+				return err
+			}
+		} else if entry.IsDir() {
 			if err := copyDirRecursive(srcPath, destPath); err != nil {
 				return err
 			}
@@ -285,9 +378,10 @@ func copyDirRecursive(src, dest string) error {
 }
 
 // getUniquePath returns a unique path by appending _N suffix if needed
-// Returns empty string if max attempts (1000) exceeded
+// Returns empty string if max attempts (1000) exceeded.
+// Lstat, so a broken symlink counts as taken.
 func getUniquePath(path string) string {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
 		return path
 	}
 
@@ -297,7 +391,7 @@ func getUniquePath(path string) string {
 	const maxAttempts = 1000
 	for counter := 1; counter <= maxAttempts; counter++ {
 		newPath := fmt.Sprintf("%s_%d%s", base, counter, ext)
-		if _, err := os.Stat(newPath); os.IsNotExist(err) {
+		if _, err := os.Lstat(newPath); os.IsNotExist(err) {
 			return newPath
 		}
 	}
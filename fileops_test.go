@@ -334,6 +334,67 @@ func TestMoveFile_DirectoryWithMultipleFiles(t *testing.T) {
 	}
 }
 
+func TestLinkFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "shared")
+	destDir := filepath.Join(tmpDir, "pkg", "a")
+	os.MkdirAll(srcDir, 0755)
+	os.MkdirAll(destDir, 0755)
+	src := filepath.Join(srcDir, "config.toml")
+	os.WriteFile(src, []byte("x"), 0644)
+
+	tests := []struct {
+		linkType LinkType
+		dest     string
+		target   string // Expected link contents ("" = hard link)
+	}{
+		{LinkSymlinkRelative, "config.toml", filepath.Join("..", "..", "shared", "config.toml")},
+		{LinkSymlinkAbsolute, "config_1.toml", src},
+		{LinkHard, "config_2.toml", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.linkType.String(), func(t *testing.T) {
+			dest, err := LinkFile(src, destDir, tt.linkType)
+			if err != nil {
+				t.Fatalf("LinkFile failed: %v", err)
+			}
+			if dest != filepath.Join(destDir, tt.dest) {
+				t.Errorf("Expected %s, got %s", tt.dest, dest)
+			}
+			target, err := os.Readlink(dest)
+			if tt.target == "" {
+				srcInfo, _ := os.Stat(src)
+				destInfo, _ := os.Stat(dest)
+				if err == nil || !os.SameFile(srcInfo, destInfo) {
+					t.Errorf("Expected a hard link to %s", src)
+				}
+			} else if target != tt.target {
+				t.Errorf("Expected a link to %s, got %s", tt.target, target)
+			}
+			if content, _ := os.ReadFile(dest); string(content) != "x" {
+				t.Errorf("Expected the link to read the source, got %q", content)
+			}
+		})
+	}
+
+	if _, err := LinkFile(srcDir, destDir, LinkHard); err == nil {
+		t.Error("Expected an error hard linking a directory")
+	}
+	if _, err := LinkFile(filepath.Join(srcDir, "missing"), destDir, LinkSymlinkRelative); err == nil {
+		t.Error("Expected an error for a missing source")
+	}
+}
+
+func TestGetUniquePath_BrokenSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "link")
+	os.Symlink("missing", path)
+
+	if got := getUniquePath(path); got != path+"_1" {
+		t.Errorf("Expected a broken symlink to count as taken, got %s", got)
+	}
+}
+
 func TestDeleteFile_File(t *testing.T) {
 	dir := t.TempDir()
 
@@ -375,6 +436,68 @@ func TestDeleteFile_NonExistent(t *testing.T) {
 	}
 }
 
+func TestDeleteFile_Symlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	broken := filepath.Join(tmpDir, "broken")
+	os.Symlink("missing", broken)
+	target := filepath.Join(tmpDir, "dir")
+	os.Mkdir(target, 0755)
+	os.WriteFile(filepath.Join(target, "keep.txt"), nil, 0644)
+	dirLink := filepath.Join(tmpDir, "dir-link")
+	os.Symlink("dir", dirLink)
+
+	for _, link := range []string{broken, dirLink} {
+		if err := DeleteFile(link); err != nil {
+			t.Errorf("DeleteFile(%s) failed: %v", link, err)
+		}
+		if _, err := os.Lstat(link); !os.IsNotExist(err) {
+			t.Errorf("Expected %s removed", link)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(target, "keep.txt")); err != nil {
+		t.Error("Expected the link target left alone")
+	}
+}
+
+func TestCopyMoveFile_BrokenSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	destDir := filepath.Join(tmpDir, "dest")
+	os.MkdirAll(srcDir, 0755)
+	os.MkdirAll(destDir, 0755)
+	link := filepath.Join(srcDir, "broken")
+	os.Symlink("missing", link)
+	os.Symlink("missing", filepath.Join(srcDir, "nested"))
+
+	copied, err := CopyFile(link, destDir)
+	if err != nil {
+		t.Fatalf("CopyFile failed: %v", err)
+	}
+	if target, err := os.Readlink(copied); err != nil || target != "missing" {
+		t.Errorf("Expected the link copied as a link, got %q, %v", target, err)
+	}
+
+	// Links inside copied directories are copied as links too
+	dirCopy, err := CopyFile(srcDir, destDir)
+	if err != nil {
+		t.Fatalf("CopyFile of a directory failed: %v", err)
+	}
+	if _, err := os.Readlink(filepath.Join(dirCopy, "nested")); err != nil {
+		t.Errorf("Expected nested link copied: %v", err)
+	}
+
+	moved, err := MoveFile(link, destDir)
+	if err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Error("Expected the source link moved away")
+	}
+	if target, _ := os.Readlink(moved); target != "missing" {
+		t.Errorf("Expected the moved link to keep its target, got %q", target)
+	}
+}
+
 func TestRenameFile(t *testing.T) {
 	dir := t.TempDir()
 
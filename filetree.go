@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
 // FileNode represents a file or directory in the tree
@@ -15,16 +16,22 @@ type FileNode struct {
 	Depth    int
 	Children []*FileNode
 	IsGhost  bool // True for deleted files (ghost entries)
+
+	// Symbolic links: IsDir describes the target
+	IsSymlink  bool
+	LinkTarget string // Link contents as written (may be relative)
+	IsBroken   bool   // Target does not exist
 }
 
-// NewFileNode creates a new FileNode
+// NewFileNode creates a new FileNode. Symlinks are listed as links, including
+// broken ones; nil means path itself does not exist.
 func NewFileNode(path string, depth int) *FileNode {
-	info, err := os.Stat(path)
+	info, err := os.Lstat(path)
 	if err != nil {
 		return nil
 	}
 
-	return &FileNode{
+	node := &FileNode{
 		Path:     path,
 		Name:     filepath.Base(path),
 		IsDir:    info.IsDir(),
@@ -32,6 +39,28 @@ func NewFileNode(path string, depth int) *FileNode {
 		Depth:    depth,
 		Children: make([]*FileNode, 0),
 	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		node.IsSymlink = true
+		node.LinkTarget, _ = os.Readlink(path)
+		if target, err := os.Stat(path); err == nil {
+			node.IsDir = target.IsDir()
+		} else {
+			node.IsBroken = true
+		}
+	}
+	return node
+}
+
+// ResolvedTarget returns the path a symlink points to, relative targets
+// resolved against the link's directory (one level, like readlink)
+func (n *FileNode) ResolvedTarget() string {
+	if !n.IsSymlink || n.LinkTarget == "" {
+		return ""
+	}
+	if filepath.IsAbs(n.LinkTarget) {
+		return filepath.Clean(n.LinkTarget)
+	}
+	return filepath.Join(filepath.Dir(n.Path), n.LinkTarget)
 }
 
 // LoadChildren loads the children of a directory node
@@ -47,26 +76,10 @@ func (n *FileNode) LoadChildren(showHidden bool) error {
 		return err
 	}
 
-	// Filter and collect entries
-	var filtered []os.DirEntry
 	for _, entry := range entries {
 		if !showHidden && entry.Name()[0] == '.' {
 			continue
 		}
-		filtered = append(filtered, entry)
-	}
-
-	// Sort: directories first, then by name
-	sort.Slice(filtered, func(i, j int) bool {
-		iIsDir := filtered[i].IsDir()
-		jIsDir := filtered[j].IsDir()
-		if iIsDir != jIsDir {
-			return iIsDir
-		}
-		return filtered[i].Name() < filtered[j].Name()
-	})
-
-	for _, entry := range filtered {
 		childPath := filepath.Join(n.Path, entry.Name())
 		child := NewFileNode(childPath, n.Depth+1)
 		if child != nil {
@@ -74,6 +87,11 @@ func (n *FileNode) LoadChildren(showHidden bool) error {
 		}
 	}
 
+	// Sort: directories (including links to them) first, then by name
+	sort.Slice(n.Children, func(i, j int) bool {
+		return nodeLess(n.Children[i], n.Children[j])
+	})
+
 	return nil
 }
 
@@ -92,7 +110,7 @@ func NewFileTree(path string, showHidden bool) (*FileTree, error) {
 	}
 
 	root := NewFileNode(absPath, 0)
-	if root == nil {
+	if root == nil || root.IsBroken {
 		return nil, os.ErrNotExist
 	}
 
@@ -183,6 +201,60 @@ func (t *FileTree) Collapse(index int) {
 	t.RebuildFlatList()
 }
 
+// IndexOf returns the index of the visible node at path (-1 if not visible)
+func (t *FileTree) IndexOf(path string) int {
+	for i, n := range t.Nodes {
+		if n.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// Reveal expands every ancestor of path below the root and returns the index
+// of its node, or -1 if path is outside the tree or not listed (e.g., hidden)
+func (t *FileTree) Reveal(path string) (int, error) {
+	if !isWithin(t.Root.Path, path) {
+		return -1, nil
+	}
+	rel, _ := filepath.Rel(t.Root.Path, path)
+	if rel == "." {
+		return 0, nil
+	}
+
+	node := t.Root
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if !node.IsDir {
+			return -1, nil
+		}
+		if !node.Expanded {
+			node.Expanded = true
+			if len(node.Children) == 0 {
+				if err := node.LoadChildren(t.ShowHidden); err != nil {
+					t.RebuildFlatList()
+					return -1, err
+				}
+			}
+		}
+
+		var next *FileNode
+		for _, child := range node.Children {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			t.RebuildFlatList()
+			return -1, nil
+		}
+		node = next
+	}
+
+	t.RebuildFlatList()
+	return t.IndexOf(node.Path), nil
+}
+
 // FindParentIndex finds the index of the parent directory
 func (t *FileTree) FindParentIndex(index int) int {
 	node := t.GetNode(index)
@@ -213,20 +285,33 @@ func (t *FileTree) collapseAllRecursive(node *FileNode) {
 	}
 }
 
-// ExpandAll expands all directories
+// ExpandAll expands all directories. Symlinked directories that lead back
+// to one of their ancestors are left collapsed, so link cycles terminate.
 func (t *FileTree) ExpandAll() error {
-	if err := t.expandAllRecursive(t.Root); err != nil {
+	if err := t.expandAllRecursive(t.Root, nil); err != nil {
 		return err
 	}
 	t.RebuildFlatList()
 	return nil
 }
 
-func (t *FileTree) expandAllRecursive(node *FileNode) error {
+// expandAllRecursive expands node below the real paths of its ancestors
+func (t *FileTree) expandAllRecursive(node *FileNode, ancestors []string) error {
 	if !node.IsDir {
 		return nil
 	}
 
+	real, err := filepath.EvalSymlinks(node.Path)
+	if err != nil {
+		real = node.Path
+	}
+	for _, a := range ancestors {
+		if a == real {
+			return nil // Link cycle
+		}
+	}
+	ancestors = append(ancestors, real)
+
 	node.Expanded = true
 	if len(node.Children) == 0 {
 		if err := node.LoadChildren(t.ShowHidden); err != nil {
@@ -235,7 +320,7 @@ func (t *FileTree) expandAllRecursive(node *FileNode) error {
 	}
 
 	for _, child := range node.Children {
-		if err := t.expandAllRecursive(child); err != nil {
+		if err := t.expandAllRecursive(child, ancestors); err != nil {
 			return err
 		}
 	}
@@ -248,17 +333,14 @@ func (t *FileTree) SetShowHidden(show bool) error {
 	return t.Refresh()
 }
 
-// Refresh reloads the entire tree from disk
+// Refresh reloads the tree from disk, keeping expanded directories expanded
 func (t *FileTree) Refresh() error {
-	rootPath := t.Root.Path
-
-	t.Root = NewFileNode(rootPath, 0)
-	if t.Root == nil {
+	if root := NewFileNode(t.Root.Path, 0); root == nil || root.IsBroken {
 		return os.ErrNotExist
 	}
 
 	t.Root.Expanded = true
-	if err := t.Root.LoadChildren(t.ShowHidden); err != nil {
+	if err := t.reconcile(t.Root); err != nil {
 		return err
 	}
 
@@ -266,6 +348,131 @@ func (t *FileTree) Refresh() error {
 	return nil
 }
 
+// reconcile reloads the children of node, reusing the existing nodes of
+// expanded subdirectories (and reconciling them in turn) so they stay open.
+// Ghost nodes are dropped; AddGhostNodes puts them back.
+func (t *FileTree) reconcile(node *FileNode) error {
+	previous := make(map[string]*FileNode, len(node.Children))
+	for _, child := range node.Children {
+		if !child.IsGhost {
+			previous[child.Name] = child
+		}
+	}
+
+	if err := node.LoadChildren(t.ShowHidden); err != nil {
+		return err
+	}
+
+	for i, child := range node.Children {
+		old, ok := previous[child.Name]
+		if !ok || !old.IsDir || !child.IsDir || !old.Expanded {
+			continue
+		}
+		// The link may have been retargeted since old was loaded
+		old.IsSymlink, old.LinkTarget, old.IsBroken = child.IsSymlink, child.LinkTarget, child.IsBroken
+		node.Children[i] = old
+		if err := t.reconcile(old); err != nil {
+			// No longer readable: show it collapsed
+			old.Expanded = false
+			old.Children = make([]*FileNode, 0)
+		}
+	}
+	return nil
+}
+
+// ApplyChanges inserts created paths and drops removed ones in the
+// directories already loaded, without reloading anything else
+func (t *FileTree) ApplyChanges(changes []FileChange) {
+	for _, change := range changes {
+		switch change.Op {
+		case FileCreated:
+			t.insertPath(change.Path)
+		case FileRemoved:
+			t.removePath(change.Path)
+		}
+	}
+	t.RebuildFlatList()
+}
+
+// insertPath adds a node for path under its parent, if the parent is loaded
+func (t *FileTree) insertPath(path string) {
+	parent := t.findNodeByPath(t.Root, filepath.Dir(path))
+	if parent == nil || !parent.IsDir || (!parent.Expanded && len(parent.Children) == 0) {
+		return // Picked up when the directory is loaded
+	}
+	name := filepath.Base(path)
+	if !t.ShowHidden && name[0] == '.' {
+		return
+	}
+
+	node := NewFileNode(path, parent.Depth+1)
+	if node == nil {
+		// Already gone again (e.g., a temporary file)
+		t.removePath(path)
+		return
+	}
+
+	for i, child := range parent.Children {
+		if child.Name == name {
+			// Known already; replace ghosts and type changes
+			if child.IsGhost || child.IsDir != node.IsDir {
+				parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+				break
+			}
+			return
+		}
+	}
+
+	i := sort.Search(len(parent.Children), func(i int) bool {
+		return !nodeLess(parent.Children[i], node)
+	})
+	parent.Children = append(parent.Children, nil)
+	copy(parent.Children[i+1:], parent.Children[i:])
+	parent.Children[i] = node
+}
+
+// removePath drops the node for path, if it is loaded
+func (t *FileTree) removePath(path string) {
+	parent := t.findNodeByPath(t.Root, filepath.Dir(path))
+	if parent == nil {
+		return
+	}
+	name := filepath.Base(path)
+	for i, child := range parent.Children {
+		if child.Name == name && !child.IsGhost {
+			parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+// nodeLess is the tree order: directories first, then by name
+func nodeLess(a, b *FileNode) bool {
+	if a.IsDir != b.IsDir {
+		return a.IsDir
+	}
+	return a.Name < b.Name
+}
+
+// RemoveGhostNodes drops all ghost entries so they can be re-added from
+// fresh VCS status
+func (t *FileTree) RemoveGhostNodes() {
+	t.removeGhosts(t.Root)
+	t.RebuildFlatList()
+}
+
+func (t *FileTree) removeGhosts(node *FileNode) {
+	kept := node.Children[:0]
+	for _, child := range node.Children {
+		if child.IsGhost {
+			continue
+		}
+		t.removeGhosts(child)
+		kept = append(kept, child)
+	}
+	node.Children = kept
+}
+
 // AddGhostNodes adds ghost entries for deleted files from VCS
 func (t *FileTree) AddGhostNodes(deletedPaths []string) {
 	if len(deletedPaths) == 0 {
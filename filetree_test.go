@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -322,6 +323,125 @@ func TestFileTree_Refresh(t *testing.T) {
 	}
 }
 
+func TestFileTree_Refresh_KeepsExpansion(t *testing.T) {
+	dir := setupTestDir(t)
+	tree, _ := NewFileTree(dir, false)
+
+	nested := filepath.Join(dir, "dir2", "subdir", "nested.txt")
+	tree.Reveal(nested)
+	tree.Reveal(filepath.Join(dir, "dir1", "file1.txt"))
+	dir2 := tree.GetNode(tree.IndexOf(filepath.Join(dir, "dir2")))
+
+	// A new file in an expanded directory, a removed one in another
+	os.WriteFile(filepath.Join(dir, "dir2", "subdir", "added.txt"), nil, 0644)
+	os.Remove(filepath.Join(dir, "dir1", "file2.go"))
+
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.IndexOf(nested) < 0 || tree.IndexOf(filepath.Join(dir, "dir2", "subdir", "added.txt")) < 0 {
+		t.Error("Expected dir2/subdir to stay expanded with the new file listed")
+	}
+	if tree.IndexOf(filepath.Join(dir, "dir1", "file2.go")) >= 0 {
+		t.Error("Expected the removed file to be gone")
+	}
+	if tree.GetNode(tree.IndexOf(filepath.Join(dir, "dir2"))) != dir2 {
+		t.Error("Expected the expanded node to be reused")
+	}
+
+	// A removed expanded directory disappears with its children
+	os.RemoveAll(filepath.Join(dir, "dir2"))
+	tree.Refresh()
+	if tree.IndexOf(nested) >= 0 {
+		t.Error("Expected dir2 and its children to be gone")
+	}
+}
+
+func TestFileTree_Refresh_RetargetedLink(t *testing.T) {
+	dir := setupTestDir(t)
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("dir1", link); err != nil {
+		t.Skipf("Symlinks unavailable: %v", err)
+	}
+	tree, _ := NewFileTree(dir, false)
+	tree.Reveal(filepath.Join(link, "file1.txt"))
+
+	// The expanded link now points at another directory
+	os.Remove(link)
+	os.Symlink("dir2", link)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	node := tree.GetNode(tree.IndexOf(link))
+	if node == nil || !node.IsSymlink || node.LinkTarget != "dir2" || node.IsBroken {
+		t.Errorf("Expected the reused node to follow the new target, got %+v", node)
+	}
+	if tree.IndexOf(filepath.Join(link, "subdir")) < 0 {
+		t.Error("Expected the new target's children")
+	}
+
+	// A real directory replacing the link is no longer shown as one
+	os.Remove(link)
+	os.Mkdir(link, 0755)
+	tree.Refresh()
+	if node := tree.GetNode(tree.IndexOf(link)); node == nil || node.IsSymlink || node.LinkTarget != "" {
+		t.Errorf("Expected a plain directory, got %+v", node)
+	}
+}
+
+func TestFileTree_ApplyChanges(t *testing.T) {
+	dir := setupTestDir(t)
+	tree, _ := NewFileTree(dir, false)
+	tree.Reveal(filepath.Join(dir, "dir1", "file1.txt"))
+
+	created := filepath.Join(dir, "dir1", "a.txt")
+	os.WriteFile(created, nil, 0644)
+	newDir := filepath.Join(dir, "dir1", "zdir")
+	os.Mkdir(newDir, 0755)
+	os.Remove(filepath.Join(dir, "dir1", "file2.go"))
+	os.WriteFile(filepath.Join(dir, "dir2", "subdir", "unloaded.txt"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "dir1", ".hidden.txt"), nil, 0644)
+
+	tree.ApplyChanges([]FileChange{
+		{Path: created, Op: FileCreated},
+		{Path: newDir, Op: FileCreated},
+		{Path: filepath.Join(dir, "dir1", "file2.go"), Op: FileRemoved},
+		{Path: filepath.Join(dir, "dir2", "subdir", "unloaded.txt"), Op: FileCreated},
+		{Path: filepath.Join(dir, "dir1", ".hidden.txt"), Op: FileCreated},
+		{Path: filepath.Join(dir, "dir1", "file1.txt"), Op: FileModified},
+	})
+
+	var names []string
+	for _, child := range tree.GetNode(tree.IndexOf(filepath.Join(dir, "dir1"))).Children {
+		names = append(names, child.Name)
+	}
+	expected := []string{"zdir", "a.txt", "file1.txt"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("dir1 children = %v, expected %v", names, expected)
+	}
+	if tree.IndexOf(created) < 0 {
+		t.Error("Expected the created file in the flat list")
+	}
+	if tree.findNodeByPath(tree.Root, filepath.Join(dir, "dir2", "subdir", "unloaded.txt")) != nil {
+		t.Error("Expected changes in unloaded directories to be skipped")
+	}
+
+	// A created path replaces its ghost
+	ghost := filepath.Join(dir, "dir1", "gone.txt")
+	tree.AddGhostNodes([]string{ghost})
+	os.WriteFile(ghost, nil, 0644)
+	tree.ApplyChanges([]FileChange{{Path: ghost, Op: FileCreated}})
+	if node := tree.findNodeByPath(tree.Root, ghost); node == nil || node.IsGhost {
+		t.Error("Expected the ghost replaced by the real file")
+	}
+
+	tree.AddGhostNodes([]string{filepath.Join(dir, "dir1", "old.txt")})
+	tree.RemoveGhostNodes()
+	if tree.findNodeByPath(tree.Root, filepath.Join(dir, "dir1", "old.txt")) != nil {
+		t.Error("Expected ghosts removed")
+	}
+}
+
 func TestFileTree_FindParentIndex(t *testing.T) {
 	dir := setupTestDir(t)
 	tree, _ := NewFileTree(dir, false)
@@ -352,6 +472,41 @@ func TestFileTree_FindParentIndex(t *testing.T) {
 	}
 }
 
+func TestFileTree_Reveal(t *testing.T) {
+	dir := setupTestDir(t)
+	tree, _ := NewFileTree(dir, false)
+
+	nested := filepath.Join(dir, "dir2", "subdir", "nested.txt")
+	idx, err := tree.Reveal(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node := tree.GetNode(idx); node == nil || node.Path != nested {
+		t.Fatalf("Expected nested.txt at %d, got %v", idx, node)
+	}
+	if idx != tree.IndexOf(nested) {
+		t.Errorf("IndexOf = %d, expected %d", tree.IndexOf(nested), idx)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"root", dir, 0},
+		{"outside", filepath.Dir(dir), -1},
+		{"hidden", filepath.Join(dir, ".hidden", "secret.txt"), -1},
+		{"missing", filepath.Join(dir, "dir1", "nope.txt"), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := tree.Reveal(tt.path); got != tt.want {
+				t.Errorf("Reveal(%s) = %d, expected %d", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
 // Tests for ghost files (deleted files in VCS)
 
 func TestFileTree_AddGhostNodes(t *testing.T) {
@@ -586,3 +741,72 @@ func TestFileTree_AddGhostNodes_DirectoriesFirst(t *testing.T) {
 		t.Errorf("Files should be in alphabetical order: file=%d, ghost=%d", fileIdx, ghostIdx)
 	}
 }
+
+func TestFileTree_Symlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "real"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "real", "file.txt"), nil, 0644)
+	os.Symlink("real", filepath.Join(tmpDir, "z-dir-link"))
+	os.Symlink(filepath.Join(tmpDir, "real", "file.txt"), filepath.Join(tmpDir, "file-link"))
+	os.Symlink("missing.txt", filepath.Join(tmpDir, "broken"))
+
+	tree, err := NewFileTree(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewFileTree failed: %v", err)
+	}
+
+	// Links to directories sort with directories; broken links stay listed
+	var names []string
+	for _, child := range tree.Root.Children {
+		names = append(names, child.Name)
+	}
+	if got := strings.Join(names, ","); got != "real,z-dir-link,broken,file-link" {
+		t.Errorf("Children = %s", got)
+	}
+
+	dirLink := tree.Root.Children[1]
+	if !dirLink.IsSymlink || !dirLink.IsDir || dirLink.IsBroken || dirLink.LinkTarget != "real" {
+		t.Errorf("Unexpected dir link %+v", dirLink)
+	}
+	if dirLink.ResolvedTarget() != filepath.Join(tmpDir, "real") {
+		t.Errorf("ResolvedTarget = %s", dirLink.ResolvedTarget())
+	}
+	broken := tree.Root.Children[2]
+	if !broken.IsSymlink || !broken.IsBroken || broken.IsDir || broken.LinkTarget != "missing.txt" {
+		t.Errorf("Unexpected broken link %+v", broken)
+	}
+	if plain := tree.Root.Children[0]; plain.IsSymlink || plain.ResolvedTarget() != "" {
+		t.Errorf("Expected a plain directory, got %+v", plain)
+	}
+
+	// Links to directories expand to the target's entries
+	tree.Expand(2)
+	if tree.Len() != 6 || tree.GetNode(3).Path != filepath.Join(tmpDir, "z-dir-link", "file.txt") {
+		t.Errorf("Expected the linked directory expanded, got %d nodes", tree.Len())
+	}
+}
+
+func TestFileTree_ExpandAll_SymlinkCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "a", "b"), 0755)
+	os.Symlink("..", filepath.Join(tmpDir, "a", "b", "up"))
+	os.Symlink(filepath.Join(tmpDir, "a"), filepath.Join(tmpDir, "a", "self"))
+
+	tree, err := NewFileTree(tmpDir, false)
+	if err != nil {
+		t.Fatalf("NewFileTree failed: %v", err)
+	}
+	if err := tree.ExpandAll(); err != nil {
+		t.Fatalf("ExpandAll failed: %v", err)
+	}
+
+	// root, a/, b/, up, self: the links lead back to ancestors and stay closed
+	if tree.Len() != 5 {
+		t.Errorf("Expected 5 nodes, got %d", tree.Len())
+	}
+	for _, node := range tree.Nodes {
+		if node.IsSymlink && node.Expanded {
+			t.Errorf("Expected %s collapsed", node.Path)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Hex viewer data inspector
+
+// hexField is one decoded value in the hex inspector
+type hexField struct {
+	Label string
+	Value string
+}
+
+// inspectBytes decodes the bytes at the cursor as integers and floats.
+// Types wider than the available bytes show "-".
+func inspectBytes(b []byte, bigEndian bool) []hexField {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+
+	field := func(label string, n int, format func(v []byte) string) hexField {
+		if len(b) < n {
+			return hexField{Label: label, Value: "-"}
+		}
+		return hexField{Label: label, Value: format(b[:n])}
+	}
+
+	return []hexField{
+		field("i8", 1, func(v []byte) string { return strconv.FormatInt(int64(int8(v[0])), 10) }),
+		field("u8", 1, func(v []byte) string { return strconv.FormatUint(uint64(v[0]), 10) }),
+		field("bin", 1, func(v []byte) string { return fmt.Sprintf("%08b", v[0]) }),
+		field("i16", 2, func(v []byte) string { return strconv.FormatInt(int64(int16(order.Uint16(v))), 10) }),
+		field("u16", 2, func(v []byte) string { return strconv.FormatUint(uint64(order.Uint16(v)), 10) }),
+		field("i32", 4, func(v []byte) string { return strconv.FormatInt(int64(int32(order.Uint32(v))), 10) }),
+		field("u32", 4, func(v []byte) string { return strconv.FormatUint(uint64(order.Uint32(v)), 10) }),
+		field("i64", 8, func(v []byte) string { return strconv.FormatInt(int64(order.Uint64(v)), 10) }),
+		field("u64", 8, func(v []byte) string { return strconv.FormatUint(order.Uint64(v), 10) }),
+		field("f32", 4, func(v []byte) string {
+			return strconv.FormatFloat(float64(math.Float32frombits(order.Uint32(v))), 'g', -1, 32)
+		}),
+		field("f64", 8, func(v []byte) string {
+			return strconv.FormatFloat(math.Float64frombits(order.Uint64(v)), 'g', -1, 64)
+		}),
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestInspectBytes(t *testing.T) {
+	data := []byte{0xfe, 0xff, 0xff, 0xff, 0x00, 0x00, 0x80, 0x3f}
+
+	tests := []struct {
+		bigEndian bool
+		label     string
+		expected  string
+	}{
+		{false, "i8", "-2"},
+		{false, "u8", "254"},
+		{false, "i16", "-2"},
+		{false, "u16", "65534"},
+		{false, "i32", "-2"},
+		{false, "u32", "4294967294"},
+		{false, "i64", "4575657225703391230"},
+		{false, "bin", "11111110"},
+		{true, "i16", "-257"},
+		{true, "u16", "65279"},
+	}
+
+	for _, tt := range tests {
+		fields := inspectBytes(data, tt.bigEndian)
+		found := false
+		for _, f := range fields {
+			if f.Label == tt.label {
+				found = true
+				if f.Value != tt.expected {
+					t.Errorf("%s (bigEndian=%v) = %s, expected %s", tt.label, tt.bigEndian, f.Value, tt.expected)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Missing field %s", tt.label)
+		}
+	}
+
+	// Floats decode from the same bytes
+	fields := inspectBytes([]byte{0x00, 0x00, 0x80, 0x3f}, false)
+	for _, f := range fields {
+		if f.Label == "f32" && f.Value != "1" {
+			t.Errorf("f32 = %s, expected 1", f.Value)
+		}
+		// Too few bytes for 64-bit values
+		if f.Label == "f64" && f.Value != "-" {
+			t.Errorf("f64 = %s, expected -", f.Value)
+		}
+	}
+}
+
+func TestHexViewer_CursorAndLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	data[0] = 0 // Binary
+	os.WriteFile(filepath.Join(tmpDir, "data.bin"), data, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.height = 20
+	model.width = 100
+	model.selected = 1
+	model.openPreview()
+	defer model.closePreview()
+	if !model.isHexViewer() {
+		t.Fatal("Expected hex viewer for a binary file")
+	}
+
+	m := model
+	press := func(keys ...string) {
+		for _, k := range keys {
+			newModel, _ := m.Update(keyMsg(k))
+			m = newModel.(Model)
+		}
+	}
+
+	press("l", "l", "j")
+	if m.previewStream.cursor != 18 {
+		t.Errorf("Expected cursor at 18, got %d", m.previewStream.cursor)
+	}
+
+	// Switching to 32-byte rows keeps the cursor byte
+	press("w")
+	if m.previewStream.rowBytes != 32 {
+		t.Fatalf("Expected 32-byte rows, got %d", m.previewStream.rowBytes)
+	}
+	if m.previewStream.cursor != 18 || len(m.previewBytes) < 32 {
+		t.Errorf("Unexpected state after width change: cursor %d, %d bytes", m.previewStream.cursor, len(m.previewBytes))
+	}
+
+	// The cursor is highlighted in the hex and ASCII columns
+	if spans := m.hexCursorSpans(0); len(spans) != 2 {
+		t.Errorf("Expected 2 cursor spans, got %d", len(spans))
+	}
+
+	// G moves to the last byte and scrolls it into view
+	press("G")
+	if m.previewStream.cursor != 999 {
+		t.Errorf("Expected cursor at 999, got %d", m.previewStream.cursor)
+	}
+	if m.previewStream.top > 999 || 999-m.previewStream.top >= int64(len(m.previewBytes)) {
+		t.Errorf("Cursor not visible: top %d, %d bytes shown", m.previewStream.top, len(m.previewBytes))
+	}
+
+	// Jump to an offset
+	press(":", "@", "5", "0", "0")
+	newModel, _ := m.Update(specialKeyMsg(tea.KeyEnter))
+	m = newModel.(Model)
+	if m.previewStream.cursor != 500 {
+		t.Errorf("Expected cursor at 500 after jump, got %d", m.previewStream.cursor)
+	}
+
+	// Byte pattern search moves the cursor to the next match
+	m.previewSearchQuery = "64 65 66" // bytes 100..102, repeating every 256 bytes
+	m.runStreamSearch(true)
+	if m.previewStream.cursor != 612 {
+		t.Errorf("Expected cursor at the first match after it (612), got %d", m.previewStream.cursor)
+	}
+
+	press("E")
+	if !m.previewStream.bigEndian {
+		t.Error("Expected big-endian inspector after E")
+	}
+	if lines := m.renderHexInspector(); len(lines) != hexInspectorLines {
+		t.Errorf("Expected %d inspector lines, got %d", hexInspectorLines, len(lines))
+	}
+}
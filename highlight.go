@@ -0,0 +1,827 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Syntax highlighting for text preview.
+// A small built-in lexer covers the languages we see most in repos; it only
+// needs to be good enough for reading, not for parsing.
+
+// tokenKind represents the syntactic category of a highlighted span
+type tokenKind int
+
+const (
+	tokenKeyword tokenKind = iota
+	tokenBuiltin
+	tokenString
+	tokenNumber
+	tokenComment
+	tokenFunction
+	tokenKey
+	tokenHeading
+	tokenVariable
+	tokenLink
+)
+
+// style returns the theme style for a token kind
+func (k tokenKind) style() lipgloss.Style {
+	switch k {
+	case tokenKeyword:
+		return syntaxKeywordStyle
+	case tokenBuiltin:
+		return syntaxBuiltinStyle
+	case tokenString:
+		return syntaxStringStyle
+	case tokenNumber:
+		return syntaxNumberStyle
+	case tokenComment:
+		return syntaxCommentStyle
+	case tokenFunction:
+		return syntaxFunctionStyle
+	case tokenKey:
+		return syntaxKeyStyle
+	case tokenHeading:
+		return syntaxHeadingStyle
+	case tokenVariable:
+		return syntaxVariableStyle
+	case tokenLink:
+		return syntaxLinkStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+// codeLang describes a C-like/scripting language for the generic lexer
+type codeLang struct {
+	name         string
+	keywords     map[string]bool
+	builtins     map[string]bool
+	lineComments []string  // e.g., "//", "#"
+	blockComment [2]string // e.g., "/*", "*/" (empty = none)
+	quotes       string    // Single-line string delimiters
+	charQuotes   string    // Short char literals (e.g., 'a' in Go/Rust/C)
+	rawQuotes    []string  // Multi-line string delimiters (longest first)
+	variables    bool      // Shell-style $VAR
+}
+
+// lexState carries multi-line constructs across lines
+type lexState struct {
+	inBlockComment bool
+	rawDelim       string // Non-empty while inside a multi-line string
+	inFence        bool   // Markdown fenced code block
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	langGo = &codeLang{
+		name: "Go",
+		keywords: wordSet(`break case chan const continue default defer else fallthrough for func go goto
+			if import interface map package range return select struct switch type var`),
+		builtins: wordSet(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64
+			rune string uint uint8 uint16 uint32 uint64 uintptr any comparable true false nil iota
+			append cap clear close complex copy delete imag len make max min new panic print println real recover`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		charQuotes:   `'`,
+		rawQuotes:    []string{"`"},
+	}
+
+	langRust = &codeLang{
+		name: "Rust",
+		keywords: wordSet(`as async await break const continue crate dyn else enum extern fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait type unsafe use
+			where while`),
+		builtins: wordSet(`bool char str String i8 i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64
+			Option Some None Result Ok Err Vec Box true false`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		charQuotes:   `'`,
+	}
+
+	langPython = &codeLang{
+		name: "Python",
+		keywords: wordSet(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield match case`),
+		builtins: wordSet(`True False None self cls int str float bool list dict set tuple bytes object type
+			len range print open isinstance super enumerate zip map filter sorted`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		rawQuotes:    []string{`"""`, `'''`},
+	}
+
+	langTypeScript = &codeLang{
+		name: "TypeScript",
+		keywords: wordSet(`abstract as async await break case catch class const continue debugger default
+			delete do else enum export extends finally for from function if implements import in instanceof
+			interface let new of private protected public readonly return static super switch this throw try
+			type typeof var void while yield`),
+		builtins: wordSet(`true false null undefined NaN Infinity any boolean number string symbol unknown never
+			object Array Object Promise Map Set console`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		rawQuotes:    []string{"`"},
+	}
+
+	langC = &codeLang{
+		name: "C",
+		keywords: wordSet(`auto break case const continue default do else enum extern for goto if inline
+			register return sizeof static struct switch typedef union volatile while class namespace template
+			typename public private protected virtual override new delete using try catch throw constexpr
+			#include #define #ifdef #ifndef #endif #if #else #pragma`),
+		builtins: wordSet(`void char short int long float double signed unsigned bool size_t true false NULL nullptr
+			std string vector`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		charQuotes:   `'`,
+	}
+
+	langJava = &codeLang{
+		name: "Java",
+		keywords: wordSet(`abstract assert break case catch class const continue default do else enum extends
+			final finally for if implements import instanceof interface native new package private protected
+			public return static super switch synchronized this throw throws try var void volatile while
+			fun val when object companion data sealed override`),
+		builtins:     wordSet(`boolean byte char double float int long short String Object true false null`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		charQuotes:   `'`,
+		rawQuotes:    []string{`"""`},
+	}
+
+	langRuby = &codeLang{
+		name: "Ruby",
+		keywords: wordSet(`alias and begin break case class def defined? do else elsif end ensure for if in
+			module next not or redo rescue retry return self super then unless until when while yield require`),
+		builtins:     wordSet(`true false nil puts attr_accessor attr_reader`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+	}
+
+	langLua = &codeLang{
+		name: "Lua",
+		keywords: wordSet(`and break do else elseif end for function goto if in local not or repeat return
+			then until while`),
+		builtins:     wordSet(`true false nil self require print pairs ipairs table string`),
+		lineComments: []string{"--"},
+		quotes:       `"'`,
+	}
+
+	langShell = &codeLang{
+		name: "Shell",
+		keywords: wordSet(`if then else elif fi for while until do done case esac in function return local
+			export readonly set unset shift source exit`),
+		builtins:     wordSet(`echo printf cd test read eval exec true false`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		variables:    true,
+	}
+
+	langSQL = &codeLang{
+		name: "SQL",
+		keywords: wordSet(`select from where insert into values update set delete create table drop alter
+			index join left right inner outer on as and or not null is in group by order having limit
+			primary key foreign references SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE
+			TABLE DROP ALTER INDEX JOIN LEFT RIGHT INNER OUTER ON AS AND OR NOT NULL IS IN GROUP BY ORDER
+			HAVING LIMIT PRIMARY KEY FOREIGN REFERENCES`),
+		builtins:     wordSet(`int integer text varchar boolean INT INTEGER TEXT VARCHAR BOOLEAN`),
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `'"`,
+	}
+)
+
+// highlighter is a function that tokenizes one line, updating the carried state
+type highlighter func(line string, state *lexState) []textSpan
+
+// langByExt maps file extensions to language names
+var langByExt = map[string]string{
+	".go":   "go",
+	".rs":   "rust",
+	".py":   "python",
+	".pyi":  "python",
+	".ts":   "typescript",
+	".tsx":  "typescript",
+	".js":   "typescript",
+	".jsx":  "typescript",
+	".mjs":  "typescript",
+	".cjs":  "typescript",
+	".c":    "c",
+	".h":    "c",
+	".cc":   "c",
+	".cpp":  "c",
+	".hpp":  "c",
+	".java": "java",
+	".kt":   "java",
+	".rb":   "ruby",
+	".lua":  "lua",
+	".sh":   "shell",
+	".bash": "shell",
+	".zsh":  "shell",
+	".fish": "shell",
+	".sql":  "sql",
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+	".md":   "markdown",
+}
+
+// langByName maps special file names to language names
+var langByName = map[string]string{
+	"Makefile":   "shell",
+	"Dockerfile": "shell",
+	".bashrc":    "shell",
+	".zshrc":     "shell",
+	".profile":   "shell",
+	"go.mod":     "go",
+	"Gemfile":    "ruby",
+	"Rakefile":   "ruby",
+}
+
+// detectLanguage picks a language name by file name, extension, or shebang
+func detectLanguage(path string, firstLine string) string {
+	name := filepath.Base(path)
+	if lang, ok := langByName[name]; ok {
+		return lang
+	}
+	if lang, ok := langByExt[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+
+	// Shebang: #!/usr/bin/env python3, #!/bin/bash, etc.
+	if strings.HasPrefix(firstLine, "#!") {
+		fields := strings.Fields(firstLine[2:])
+		if len(fields) == 0 {
+			return ""
+		}
+		interp := filepath.Base(fields[0])
+		if interp == "env" && len(fields) > 1 {
+			interp = fields[1]
+		}
+		switch {
+		case strings.HasPrefix(interp, "python"):
+			return "python"
+		case interp == "node" || interp == "deno" || interp == "bun":
+			return "typescript"
+		case interp == "ruby":
+			return "ruby"
+		case interp == "lua":
+			return "lua"
+		case interp == "sh" || interp == "bash" || interp == "zsh" || interp == "dash" || interp == "fish":
+			return "shell"
+		}
+	}
+	return ""
+}
+
+// highlighterFor returns the highlighter for a language name (nil = none)
+func highlighterFor(lang string) highlighter {
+	var code *codeLang
+	switch lang {
+	case "go":
+		code = langGo
+	case "rust":
+		code = langRust
+	case "python":
+		code = langPython
+	case "typescript":
+		code = langTypeScript
+	case "c":
+		code = langC
+	case "java":
+		code = langJava
+	case "ruby":
+		code = langRuby
+	case "lua":
+		code = langLua
+	case "shell":
+		code = langShell
+	case "sql":
+		code = langSQL
+	case "json":
+		return highlightJSONLine
+	case "yaml":
+		return highlightYAMLLine
+	case "toml":
+		return highlightTOMLLine
+	case "markdown":
+		return highlightMarkdownLine
+	default:
+		return nil
+	}
+	return func(line string, state *lexState) []textSpan {
+		return code.highlightLine(line, state)
+	}
+}
+
+// highlightLines returns syntax spans for each line (nil if language is unknown)
+func highlightLines(path string, lines []string) [][]textSpan {
+	firstLine := ""
+	if len(lines) > 0 {
+		firstLine = lines[0]
+	}
+	hl := highlighterFor(detectLanguage(path, firstLine))
+	if hl == nil {
+		return nil
+	}
+
+	var state lexState
+	result := make([][]textSpan, len(lines))
+	for i, line := range lines {
+		result[i] = hl(line, &state)
+	}
+	return result
+}
+
+// Highlight cache (keyed by path, invalidated by mtime and size)
+
+type highlightCacheEntry struct {
+	modTime time.Time
+	size    int64
+	spans   [][]textSpan
+	used    time.Time
+}
+
+type highlightCache struct {
+	mu      sync.Mutex
+	entries map[string]*highlightCacheEntry
+}
+
+var syntaxCache = &highlightCache{entries: make(map[string]*highlightCacheEntry)}
+
+// get returns cached spans for the file, computing them if needed
+func (c *highlightCache) get(path string, lines []string) [][]textSpan {
+	info, err := os.Stat(path)
+	if err != nil {
+		return highlightLines(path, lines)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[path]; ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() && len(e.spans) == len(lines) {
+		e.used = time.Now()
+		return e.spans
+	}
+
+	spans := highlightLines(path, lines)
+
+	// Evict least recently used entry when full
+	if len(c.entries) >= MaxHighlightCacheEntries {
+		var oldestPath string
+		var oldest time.Time
+		for p, e := range c.entries {
+			if oldestPath == "" || e.used.Before(oldest) {
+				oldestPath = p
+				oldest = e.used
+			}
+		}
+		delete(c.entries, oldestPath)
+	}
+
+	c.entries[path] = &highlightCacheEntry{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		spans:   spans,
+		used:    time.Now(),
+	}
+	return spans
+}
+
+// Generic lexer
+
+func isIdentStart(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isIdentChar(b byte) bool {
+	return isIdentStart(b) || (b >= '0' && b <= '9')
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func span(start, end int, kind tokenKind) textSpan {
+	return textSpan{Start: start, End: end, Style: kind.style()}
+}
+
+// scanQuoted returns the end index (exclusive) of a string starting at i,
+// or -1 if the closing quote is not found on this line
+func scanQuoted(line string, i int, quote byte) int {
+	for j := i + 1; j < len(line); j++ {
+		if line[j] == '\\' {
+			j++
+			continue
+		}
+		if line[j] == quote {
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// scanCharLiteral returns the end index of a char literal like 'a' or '\n'
+// starting at i, or -1 if there is none
+func scanCharLiteral(line string, i int) int {
+	if i+1 >= len(line) {
+		return -1
+	}
+	quote := line[i]
+	if line[i+1] == '\\' {
+		end := scanQuoted(line, i, quote)
+		if end > 0 && end-i <= 12 {
+			return end
+		}
+		return -1
+	}
+	_, size := utf8.DecodeRuneInString(line[i+1:])
+	if i+1+size < len(line) && line[i+1+size] == quote {
+		return i + 2 + size
+	}
+	return -1
+}
+
+// scanNumber returns the end index of a numeric literal starting at i
+func scanNumber(line string, i int) int {
+	j := i
+	for j < len(line) && (isIdentChar(line[j]) || line[j] == '.') {
+		j++
+	}
+	return j
+}
+
+func (l *codeLang) highlightLine(line string, state *lexState) []textSpan {
+	var spans []textSpan
+	i := 0
+
+	for i < len(line) {
+		// Continue multi-line block comment
+		if state.inBlockComment {
+			end := strings.Index(line[i:], l.blockComment[1])
+			if end < 0 {
+				spans = append(spans, span(i, len(line), tokenComment))
+				return spans
+			}
+			end = i + end + len(l.blockComment[1])
+			spans = append(spans, span(i, end, tokenComment))
+			state.inBlockComment = false
+			i = end
+			continue
+		}
+
+		// Continue multi-line string
+		if state.rawDelim != "" {
+			end := strings.Index(line[i:], state.rawDelim)
+			if end < 0 {
+				spans = append(spans, span(i, len(line), tokenString))
+				return spans
+			}
+			end = i + end + len(state.rawDelim)
+			spans = append(spans, span(i, end, tokenString))
+			state.rawDelim = ""
+			i = end
+			continue
+		}
+
+		rest := line[i:]
+
+		// Line comment
+		isComment := false
+		for _, lc := range l.lineComments {
+			if strings.HasPrefix(rest, lc) {
+				// Shell: '#' only starts a comment at word boundary ("$#" is not a comment)
+				if l.variables && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+					continue
+				}
+				isComment = true
+				break
+			}
+		}
+		if isComment {
+			spans = append(spans, span(i, len(line), tokenComment))
+			return spans
+		}
+
+		// Block comment start
+		if l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]) {
+			state.inBlockComment = true
+			spans = append(spans, span(i, i+len(l.blockComment[0]), tokenComment))
+			i += len(l.blockComment[0])
+			continue
+		}
+
+		// Multi-line string start
+		isRaw := false
+		for _, delim := range l.rawQuotes {
+			if strings.HasPrefix(rest, delim) {
+				state.rawDelim = delim
+				spans = append(spans, span(i, i+len(delim), tokenString))
+				i += len(delim)
+				isRaw = true
+				break
+			}
+		}
+		if isRaw {
+			continue
+		}
+
+		c := line[i]
+		switch {
+		case strings.IndexByte(l.quotes, c) >= 0:
+			end := scanQuoted(line, i, c)
+			if end < 0 {
+				end = len(line)
+			}
+			spans = append(spans, span(i, end, tokenString))
+			i = end
+
+		case strings.IndexByte(l.charQuotes, c) >= 0:
+			// Char literals hold one rune or a short escape; anything else
+			// (e.g., Rust lifetimes) is not a string
+			if end := scanCharLiteral(line, i); end > 0 {
+				spans = append(spans, span(i, end, tokenString))
+				i = end
+			} else {
+				i++
+			}
+
+		case l.variables && c == '$' && i+1 < len(line):
+			j := i + 1
+			if line[j] == '{' {
+				if end := strings.IndexByte(line[j:], '}'); end >= 0 {
+					j += end + 1
+				}
+			} else {
+				for j < len(line) && isIdentChar(line[j]) {
+					j++
+				}
+			}
+			if j == i+1 {
+				j++ // Special variables like $@, $?, $1
+			}
+			spans = append(spans, span(i, j, tokenVariable))
+			i = j
+
+		case isDigit(c) && (i == 0 || !isIdentChar(line[i-1])):
+			end := scanNumber(line, i)
+			spans = append(spans, span(i, end, tokenNumber))
+			i = end
+
+		case isIdentStart(c) || c == '#' || c == '@':
+			j := i + 1
+			for j < len(line) && (isIdentChar(line[j]) || line[j] == '?') {
+				j++
+			}
+			word := line[i:j]
+			switch {
+			case l.keywords[word]:
+				spans = append(spans, span(i, j, tokenKeyword))
+			case l.builtins[word]:
+				spans = append(spans, span(i, j, tokenBuiltin))
+			case j < len(line) && line[j] == '(' && c != '#' && c != '@':
+				spans = append(spans, span(i, j, tokenFunction))
+			}
+			i = j
+
+		default:
+			i++
+		}
+	}
+
+	return spans
+}
+
+// highlightJSONLine highlights keys, strings, numbers and literals
+func highlightJSONLine(line string, _ *lexState) []textSpan {
+	var spans []textSpan
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := scanQuoted(line, i, '"')
+			if end < 0 {
+				end = len(line)
+			}
+			kind := tokenString
+			// A string followed by ':' is an object key
+			rest := strings.TrimLeft(line[end:], " \t")
+			if strings.HasPrefix(rest, ":") {
+				kind = tokenKey
+			}
+			spans = append(spans, span(i, end, kind))
+			i = end
+		case c == '-' || isDigit(c):
+			end := scanNumber(line, i+1)
+			spans = append(spans, span(i, end, tokenNumber))
+			i = end
+		case isIdentStart(c):
+			j := i
+			for j < len(line) && isIdentChar(line[j]) {
+				j++
+			}
+			switch line[i:j] {
+			case "true", "false", "null":
+				spans = append(spans, span(i, j, tokenKeyword))
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return spans
+}
+
+// highlightScalar highlights a YAML/TOML value
+func highlightScalar(line string, start int, commentChar byte) []textSpan {
+	var spans []textSpan
+	i := start
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	if i >= len(line) {
+		return nil
+	}
+
+	for j := i; j < len(line); {
+		c := line[j]
+		switch {
+		case c == commentChar && (j == 0 || line[j-1] == ' ' || line[j-1] == '\t'):
+			return append(spans, span(j, len(line), tokenComment))
+		case c == '"' || c == '\'':
+			end := scanQuoted(line, j, c)
+			if end < 0 {
+				end = len(line)
+			}
+			spans = append(spans, span(j, end, tokenString))
+			j = end
+		case (c == '-' || isDigit(c)) && (j == i || line[j-1] == ' ' || line[j-1] == '[' || line[j-1] == ','):
+			end := scanNumber(line, j+1)
+			spans = append(spans, span(j, end, tokenNumber))
+			j = end
+		case isIdentStart(c):
+			k := j
+			for k < len(line) && isIdentChar(line[k]) {
+				k++
+			}
+			switch line[j:k] {
+			case "true", "false", "null", "yes", "no", "on", "off", "True", "False", "Null", "NULL", "inf", "nan":
+				spans = append(spans, span(j, k, tokenKeyword))
+			}
+			j = k
+		default:
+			j++
+		}
+	}
+	return spans
+}
+
+// highlightYAMLLine highlights keys, comments and scalar values
+func highlightYAMLLine(line string, _ *lexState) []textSpan {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+
+	if strings.HasPrefix(trimmed, "#") {
+		return []textSpan{span(indent, len(line), tokenComment)}
+	}
+	if trimmed == "---" || trimmed == "..." {
+		return []textSpan{span(indent, len(line), tokenKeyword)}
+	}
+
+	// List item marker
+	pos := indent
+	for strings.HasPrefix(line[pos:], "- ") {
+		pos += 2
+	}
+
+	// key: value
+	if idx := strings.Index(line[pos:], ":"); idx > 0 {
+		keyEnd := pos + idx
+		key := line[pos:keyEnd]
+		if !strings.ContainsAny(key, "\"'{}[]#") || strings.HasPrefix(key, "\"") {
+			if keyEnd+1 == len(line) || line[keyEnd+1] == ' ' {
+				spans := []textSpan{span(pos, keyEnd, tokenKey)}
+				return append(spans, highlightScalar(line, keyEnd+1, '#')...)
+			}
+		}
+	}
+	return highlightScalar(line, pos, '#')
+}
+
+// highlightTOMLLine highlights tables, keys, comments and values
+func highlightTOMLLine(line string, _ *lexState) []textSpan {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+
+	if strings.HasPrefix(trimmed, "#") {
+		return []textSpan{span(indent, len(line), tokenComment)}
+	}
+	if strings.HasPrefix(trimmed, "[") {
+		end := strings.LastIndex(line, "]")
+		if end > indent {
+			spans := []textSpan{span(indent, end+1, tokenKeyword)}
+			return append(spans, highlightScalar(line, end+1, '#')...)
+		}
+	}
+	if idx := strings.Index(line, "="); idx > indent {
+		spans := []textSpan{span(indent, indent+len(strings.TrimRight(line[indent:idx], " \t")), tokenKey)}
+		return append(spans, highlightScalar(line, idx+1, '#')...)
+	}
+	return highlightScalar(line, indent, '#')
+}
+
+// highlightMarkdownLine highlights headings, code, emphasis, links and list markers
+func highlightMarkdownLine(line string, state *lexState) []textSpan {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+
+	// Fenced code blocks
+	if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		state.inFence = !state.inFence
+		return []textSpan{span(indent, len(line), tokenComment)}
+	}
+	if state.inFence {
+		return []textSpan{span(0, len(line), tokenString)}
+	}
+
+	if strings.HasPrefix(trimmed, "#") {
+		return []textSpan{span(indent, len(line), tokenHeading)}
+	}
+	if strings.HasPrefix(trimmed, ">") {
+		return []textSpan{span(indent, len(line), tokenComment)}
+	}
+
+	var spans []textSpan
+
+	// List markers
+	if strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ ") {
+		spans = append(spans, span(indent, indent+1, tokenKeyword))
+	}
+
+	for i := indent; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '`':
+			end := strings.IndexByte(line[i+1:], '`')
+			if end < 0 {
+				i++
+				continue
+			}
+			end = i + 1 + end + 1
+			spans = append(spans, span(i, end, tokenString))
+			i = end
+		case c == '[':
+			// [text](url)
+			closeBracket := strings.Index(line[i:], "](")
+			if closeBracket < 0 {
+				i++
+				continue
+			}
+			closeParen := strings.IndexByte(line[i+closeBracket:], ')')
+			if closeParen < 0 {
+				i++
+				continue
+			}
+			end := i + closeBracket + closeParen + 1
+			spans = append(spans, span(i, end, tokenLink))
+			i = end
+		case c == '*' || c == '_':
+			// **bold** / *em*
+			delim := string(c)
+			if strings.HasPrefix(line[i:], delim+delim) {
+				delim += delim
+			}
+			end := strings.Index(line[i+len(delim):], delim)
+			if end <= 0 {
+				i += len(delim)
+				continue
+			}
+			end = i + len(delim) + end + len(delim)
+			spans = append(spans, span(i, end, tokenKeyword))
+			i = end
+		default:
+			i++
+		}
+	}
+	return spans
+}
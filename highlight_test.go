@@ -0,0 +1,187 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// spanTexts returns the text covered by spans of the given token kind
+func spanTexts(line string, spans []textSpan, kind tokenKind) []string {
+	var texts []string
+	want := kind.style()
+	for _, s := range spans {
+		if s.Style.GetForeground() == want.GetForeground() &&
+			s.Style.GetBold() == want.GetBold() &&
+			s.Style.GetUnderline() == want.GetUnderline() {
+			texts = append(texts, line[s.Start:s.End])
+		}
+	}
+	return texts
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		path      string
+		firstLine string
+		expected  string
+	}{
+		{"main.go", "", "go"},
+		{"lib.rs", "", "rust"},
+		{"app.py", "", "python"},
+		{"index.tsx", "", "typescript"},
+		{"config.json", "", "json"},
+		{"ci.yml", "", "yaml"},
+		{"Cargo.toml", "", "toml"},
+		{"README.md", "", "markdown"},
+		{"build.SH", "", "shell"},
+		{"Makefile", "", "shell"},
+		{"script", "#!/usr/bin/env python3", "python"},
+		{"script", "#!/bin/bash -e", "shell"},
+		{"script", "#!/usr/bin/env node", "typescript"},
+		{"notes.txt", "", ""},
+		{"script", "#!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+tt.firstLine, func(t *testing.T) {
+			if got := detectLanguage(tt.path, tt.firstLine); got != tt.expected {
+				t.Errorf("detectLanguage(%q, %q) = %q, expected %q", tt.path, tt.firstLine, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHighlightLines_Go(t *testing.T) {
+	lines := []string{
+		`package main`,
+		`func main() { // entry`,
+		`	s := "hi" + 42`,
+		"	r := `raw",
+		"still raw`",
+		`	/* block`,
+		`	end */ return`,
+	}
+	spans := highlightLines("main.go", lines)
+	if len(spans) != len(lines) {
+		t.Fatalf("Expected %d span lines, got %d", len(lines), len(spans))
+	}
+
+	if kw := spanTexts(lines[0], spans[0], tokenKeyword); len(kw) != 1 || kw[0] != "package" {
+		t.Errorf("Expected 'package' keyword, got %v", kw)
+	}
+	if fn := spanTexts(lines[1], spans[1], tokenFunction); len(fn) != 1 || fn[0] != "main" {
+		t.Errorf("Expected 'main' function, got %v", fn)
+	}
+	if c := spanTexts(lines[1], spans[1], tokenComment); len(c) != 1 || c[0] != "// entry" {
+		t.Errorf("Expected line comment, got %v", c)
+	}
+	if s := spanTexts(lines[2], spans[2], tokenString); len(s) != 1 || s[0] != `"hi"` {
+		t.Errorf("Expected string literal, got %v", s)
+	}
+	if n := spanTexts(lines[2], spans[2], tokenNumber); len(n) != 1 || n[0] != "42" {
+		t.Errorf("Expected number literal, got %v", n)
+	}
+	// Raw string continues onto the next line
+	if s := spanTexts(lines[4], spans[4], tokenString); len(s) != 1 || s[0] != "still raw`" {
+		t.Errorf("Expected raw string continuation, got %v", s)
+	}
+	// Block comment ends mid-line, keyword after it is highlighted
+	if c := spanTexts(lines[6], spans[6], tokenComment); len(c) != 1 || c[0] != "\tend */" {
+		t.Errorf("Expected block comment end, got %v", c)
+	}
+	if kw := spanTexts(lines[6], spans[6], tokenKeyword); len(kw) != 1 || kw[0] != "return" {
+		t.Errorf("Expected keyword after block comment, got %v", kw)
+	}
+}
+
+func TestHighlightLines_RustLifetimeIsNotString(t *testing.T) {
+	line := `fn f<'a>(x: &'a str) -> char { 'c' }`
+	spans := highlightLines("lib.rs", []string{line})
+	strs := spanTexts(line, spans[0], tokenString)
+	if len(strs) != 1 || strs[0] != "'c'" {
+		t.Errorf("Expected only char literal as string, got %v", strs)
+	}
+}
+
+func TestHighlightLines_ShellVariables(t *testing.T) {
+	line := `echo "$HOME" $PATH ${USER} $? # comment`
+	spans := highlightLines("run.sh", []string{line})
+	vars := spanTexts(line, spans[0], tokenVariable)
+	if len(vars) != 3 {
+		t.Errorf("Expected 3 variables outside strings, got %v", vars)
+	}
+	if c := spanTexts(line, spans[0], tokenComment); len(c) != 1 {
+		t.Errorf("Expected trailing comment, got %v", c)
+	}
+}
+
+func TestHighlightLines_JSONKeys(t *testing.T) {
+	line := `  "name": "bon3", "count": 3, "ok": true`
+	spans := highlightLines("a.json", []string{line})
+	if keys := spanTexts(line, spans[0], tokenKey); len(keys) != 3 {
+		t.Errorf("Expected 3 keys, got %v", keys)
+	}
+	if strs := spanTexts(line, spans[0], tokenString); len(strs) != 1 || strs[0] != `"bon3"` {
+		t.Errorf("Expected 1 string value, got %v", strs)
+	}
+}
+
+func TestHighlightLines_YAML(t *testing.T) {
+	lines := []string{"# comment", "name: ci", "  - run: go test  # inline", "enabled: true"}
+	spans := highlightLines("ci.yml", lines)
+	if c := spanTexts(lines[0], spans[0], tokenComment); len(c) != 1 {
+		t.Errorf("Expected full-line comment, got %v", c)
+	}
+	if k := spanTexts(lines[2], spans[2], tokenKey); len(k) != 1 || k[0] != "run" {
+		t.Errorf("Expected key in list item, got %v", k)
+	}
+	if c := spanTexts(lines[2], spans[2], tokenComment); len(c) != 1 || c[0] != "# inline" {
+		t.Errorf("Expected inline comment, got %v", c)
+	}
+	if kw := spanTexts(lines[3], spans[3], tokenKeyword); len(kw) != 1 || kw[0] != "true" {
+		t.Errorf("Expected boolean literal, got %v", kw)
+	}
+}
+
+func TestHighlightLines_MarkdownFence(t *testing.T) {
+	lines := []string{"# Title", "```go", "func x() {}", "```", "see [docs](http://x)"}
+	spans := highlightLines("README.md", lines)
+	if h := spanTexts(lines[0], spans[0], tokenHeading); len(h) != 1 {
+		t.Errorf("Expected heading, got %v", h)
+	}
+	if s := spanTexts(lines[2], spans[2], tokenString); len(s) != 1 {
+		t.Errorf("Expected fenced code line, got %v", s)
+	}
+	if l := spanTexts(lines[4], spans[4], tokenLink); len(l) != 1 || l[0] != "[docs](http://x)" {
+		t.Errorf("Expected link, got %v", l)
+	}
+}
+
+func TestHighlightLines_UnknownLanguage(t *testing.T) {
+	if spans := highlightLines("notes.txt", []string{"plain"}); spans != nil {
+		t.Errorf("Expected nil spans for unknown language, got %v", spans)
+	}
+}
+
+func TestHighlightCache_InvalidatesOnModTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "main.go")
+	os.WriteFile(path, []byte("package main"), 0644)
+
+	cache := &highlightCache{entries: make(map[string]*highlightCacheEntry)}
+	first := cache.get(path, []string{"package main"})
+	second := cache.get(path, []string{"package main"})
+	if len(first) != 1 || &first[0] != &second[0] {
+		t.Error("Expected cached spans to be reused")
+	}
+
+	// Changing mtime invalidates the entry
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(path, future, future)
+	third := cache.get(path, []string{"package main"})
+	if &third[0] == &first[0] {
+		t.Error("Expected cache to be invalidated after mtime change")
+	}
+}
@@ -12,6 +12,8 @@ type IconSet struct {
 	File         string
 	FileText     string
 	Ghost        string
+	Symlink      string
+	BrokenLink   string
 	// File type icons
 	Go         string
 	Rust       string
@@ -41,6 +43,8 @@ var nerdFontIcons = IconSet{
 	File:         "\uf15b", // nf-fa-file
 	FileText:     "\uf15c", // nf-fa-file_text
 	Ghost:        "\uf4a4", // nf-md-ghost
+	Symlink:      "\uf481", // nf-oct-file_symlink_file
+	BrokenLink:   "\uf127", // nf-fa-chain_broken
 	Go:           "\ue627", // nf-seti-go
 	Rust:         "\ue7a8", // nf-dev-rust
 	Python:       "\ue73c", // nf-dev-python
@@ -69,6 +73,8 @@ var asciiIcons = IconSet{
 	File:         " ",
 	FileText:     " ",
 	Ghost:        "x",
+	Symlink:      "@",
+	BrokenLink:   "!",
 	Go:           " ",
 	Rust:         " ",
 	Python:       " ",
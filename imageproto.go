@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/ansi/iterm2"
+	"github.com/charmbracelet/x/ansi/sixel"
+	"golang.org/x/image/draw"
+)
+
+// Inline image protocols.
+// Images are decoded and scaled in Go and sent to the terminal with the Kitty
+// graphics protocol, Sixel or the iTerm2 inline image protocol; chafa is only
+// used when the terminal's protocol is unknown.
+
+// imageProtocol identifies how images are drawn in the terminal
+type imageProtocol int
+
+const (
+	imageProtocolAuto   imageProtocol = iota // Not detected (chafa, then ASCII art)
+	imageProtocolKitty                       // Kitty graphics protocol
+	imageProtocolSixel                       // DEC Sixel
+	imageProtocolITerm2                      // iTerm2 inline images (also WezTerm)
+	imageProtocolASCII                       // Colored ASCII art only
+)
+
+// String returns the config name of the protocol
+func (p imageProtocol) String() string {
+	switch p {
+	case imageProtocolKitty:
+		return "kitty"
+	case imageProtocolSixel:
+		return "sixel"
+	case imageProtocolITerm2:
+		return "iterm2"
+	case imageProtocolASCII:
+		return "ascii"
+	default:
+		return "auto"
+	}
+}
+
+// isNative reports whether the protocol is encoded by bon3 itself
+func (p imageProtocol) isNative() bool {
+	return p == imageProtocolKitty || p == imageProtocolSixel || p == imageProtocolITerm2
+}
+
+// parseImageProtocol parses a config value ("" means auto)
+func parseImageProtocol(s string) (imageProtocol, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return imageProtocolAuto, nil
+	case "kitty":
+		return imageProtocolKitty, nil
+	case "sixel":
+		return imageProtocolSixel, nil
+	case "iterm2", "iterm":
+		return imageProtocolITerm2, nil
+	case "ascii":
+		return imageProtocolASCII, nil
+	}
+	return imageProtocolAuto, fmt.Errorf("unknown image protocol %q (want auto, kitty, sixel, iterm2 or ascii)", s)
+}
+
+// guessImageProtocol picks a protocol from environment hints.
+// The capability query refines this once the terminal answers.
+func guessImageProtocol(getenv func(string) string) imageProtocol {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case term == "xterm-kitty" || term == "xterm-ghostty" || getenv("KITTY_WINDOW_ID") != "" || program == "ghostty":
+		return imageProtocolKitty
+	case program == "iTerm.app" || program == "WezTerm" || getenv("LC_TERMINAL") == "iTerm2":
+		return imageProtocolITerm2
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return imageProtocolSixel
+	}
+	return imageProtocolAuto
+}
+
+// kittyQueryID is the image ID used for the Kitty graphics support query
+const kittyQueryID = 31
+
+// kittyImageID is the image ID of the preview image (replaced on each draw)
+const kittyImageID = 1
+
+// imageCapabilityQuery asks the terminal for Kitty graphics support, its
+// primary device attributes (4 = Sixel) and its cell size in pixels.
+// Kitty answers the graphics query before DA1, so it wins when both are supported.
+func imageCapabilityQuery(tmux bool) string {
+	query := ansi.KittyGraphics([]byte("AAAA"), fmt.Sprintf("i=%d", kittyQueryID), "s=1", "v=1", "a=q", "t=d", "f=24")
+	if tmux {
+		query = ansi.TmuxPassthrough(query)
+	}
+	return query + ansi.WindowOp(16) + ansi.RequestPrimaryDeviceAttributes
+}
+
+// hasSixel reports whether primary device attributes include Sixel graphics
+func hasSixel(attrs []int) bool {
+	return slices.Contains(attrs, 4)
+}
+
+// fitImage returns the largest size with the image's aspect ratio that fits
+// in maxW x maxH pixels (images are scaled up as well as down)
+func fitImage(imgW, imgH, maxW, maxH int) (w, h int) {
+	if imgW <= 0 || imgH <= 0 || maxW <= 0 || maxH <= 0 {
+		return 0, 0
+	}
+	// Compare imgW/imgH with maxW/maxH without floating point
+	if imgW*maxH > imgH*maxW {
+		w, h = maxW, imgH*maxW/imgW
+	} else {
+		w, h = imgW*maxH/imgH, maxH
+	}
+	return max(w, 1), max(h, 1)
+}
+
+// encodedImage is an image ready to be written at the cursor
+type encodedImage struct {
+	Seq  string // Escape sequence(s) drawing the image
+	Cols int    // Cells covered horizontally
+	Rows int    // Cells covered vertically
+}
+
+// encodeImage scales img to fit cols x rows cells and encodes it for the protocol.
+// In tmux every sequence is wrapped for passthrough.
+func encodeImage(p imageProtocol, img image.Image, cols, rows, cellW, cellH int, tmux bool) (encodedImage, error) {
+	b := img.Bounds()
+	w, h := fitImage(b.Dx(), b.Dy(), cols*cellW, rows*cellH)
+	if w == 0 {
+		return encodedImage{}, fmt.Errorf("empty image")
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, b, draw.Over, nil)
+
+	out := encodedImage{Cols: (w + cellW - 1) / cellW, Rows: (h + cellH - 1) / cellH}
+	var seqs []string
+	switch p {
+	case imageProtocolKitty:
+		chunks, err := encodeKitty(scaled)
+		if err != nil {
+			return encodedImage{}, err
+		}
+		seqs = chunks
+	case imageProtocolSixel:
+		var buf bytes.Buffer
+		if err := (&sixel.Encoder{}).Encode(&buf, scaled); err != nil {
+			return encodedImage{}, err
+		}
+		seqs = []string{ansi.SixelGraphics(0, 1, 0, buf.Bytes())}
+	case imageProtocolITerm2:
+		data, err := encodePNGBase64(scaled)
+		if err != nil {
+			return encodedImage{}, err
+		}
+		seqs = []string{ansi.ITerm2(iterm2.File{
+			Inline:  true,
+			Width:   iterm2.Pixels(w),
+			Height:  iterm2.Pixels(h),
+			Content: data,
+		})}
+	default:
+		return encodedImage{}, fmt.Errorf("no encoder for %s", p)
+	}
+
+	var s strings.Builder
+	for _, seq := range seqs {
+		if tmux {
+			seq = ansi.TmuxPassthrough(seq)
+		}
+		s.WriteString(seq)
+	}
+	out.Seq = s.String()
+	return out, nil
+}
+
+// encodeKitty encodes an image as Kitty graphics chunks (PNG, 4096 base64 bytes each).
+// The previous preview image is deleted first, and the cursor is left in place.
+func encodeKitty(img image.Image) ([]string, error) {
+	data, err := encodePNGBase64(img)
+	if err != nil {
+		return nil, err
+	}
+
+	const chunkSize = 4096
+	seqs := []string{kittyDeleteSequence()}
+	for off := 0; ; off += chunkSize {
+		end := min(off+chunkSize, len(data))
+		more := "m=0"
+		if end < len(data) {
+			more = "m=1"
+		}
+		var opts []string
+		if off == 0 {
+			opts = []string{"a=T", "f=100", fmt.Sprintf("i=%d", kittyImageID), "q=2", "C=1"}
+		}
+		seqs = append(seqs, ansi.KittyGraphics(data[off:end], append(opts, more)...))
+		if end >= len(data) {
+			break
+		}
+	}
+	return seqs, nil
+}
+
+// kittyDeleteSequence deletes all Kitty images and frees their data
+func kittyDeleteSequence() string {
+	return ansi.KittyGraphics(nil, "a=d", "d=A", "q=2")
+}
+
+// encodePNGBase64 encodes an image as base64 PNG data
+func encodePNGBase64(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	data := make([]byte, base64.StdEncoding.EncodedLen(buf.Len()))
+	base64.StdEncoding.Encode(data, buf.Bytes())
+	return data, nil
+}
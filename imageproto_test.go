@@ -0,0 +1,212 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi/kitty"
+)
+
+func TestParseImageProtocol(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected imageProtocol
+		wantErr  bool
+	}{
+		{"", imageProtocolAuto, false},
+		{"auto", imageProtocolAuto, false},
+		{"Kitty", imageProtocolKitty, false},
+		{"sixel", imageProtocolSixel, false},
+		{"iterm2", imageProtocolITerm2, false},
+		{"ascii", imageProtocolASCII, false},
+		{"png", imageProtocolAuto, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := parseImageProtocol(tt.input)
+			if (err != nil) != tt.wantErr || p != tt.expected {
+				t.Errorf("parseImageProtocol(%q) = %v, %v; expected %v", tt.input, p, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGuessImageProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected imageProtocol
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, imageProtocolKitty},
+		{"kitty in tmux", map[string]string{"TERM": "tmux-256color", "KITTY_WINDOW_ID": "1"}, imageProtocolKitty},
+		{"iterm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, imageProtocolITerm2},
+		{"wezterm", map[string]string{"TERM_PROGRAM": "WezTerm"}, imageProtocolITerm2},
+		{"foot", map[string]string{"TERM": "foot"}, imageProtocolSixel},
+		{"unknown", map[string]string{"TERM": "xterm-256color"}, imageProtocolAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guessImageProtocol(func(k string) string { return tt.env[k] }); got != tt.expected {
+				t.Errorf("guessImageProtocol = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	tests := []struct {
+		name                   string
+		imgW, imgH, maxW, maxH int
+		w, h                   int
+	}{
+		{"wide image limited by width", 400, 100, 200, 200, 200, 50},
+		{"tall image limited by height", 100, 400, 200, 200, 50, 200},
+		{"small image scaled up", 10, 10, 300, 200, 200, 200},
+		{"empty", 0, 10, 100, 100, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := fitImage(tt.imgW, tt.imgH, tt.maxW, tt.maxH)
+			if w != tt.w || h != tt.h {
+				t.Errorf("fitImage = %dx%d, expected %dx%d", w, h, tt.w, tt.h)
+			}
+		})
+	}
+}
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * y), uint8(x ^ y), uint8(x*7 + y*13), 255})
+		}
+	}
+	return img
+}
+
+func TestEncodeImage(t *testing.T) {
+	img := testImage(200, 100)
+
+	t.Run("kitty", func(t *testing.T) {
+		enc, err := encodeImage(imageProtocolKitty, img, 40, 20, 10, 20, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 400x200 px box, 2:1 image: 400x200 px = 40x10 cells
+		if enc.Cols != 40 || enc.Rows != 10 {
+			t.Errorf("Expected 40x10 cells, got %dx%d", enc.Cols, enc.Rows)
+		}
+		if !strings.HasPrefix(enc.Seq, kittyDeleteSequence()+"\x1b_Ga=T,f=100,") {
+			t.Errorf("Unexpected Kitty sequence start %q", enc.Seq[:40])
+		}
+		// Chunked: every chunk but the last has m=1
+		if strings.Count(enc.Seq, "m=0") != 1 || !strings.Contains(enc.Seq, "m=1") {
+			t.Error("Expected chunked transmission")
+		}
+	})
+
+	t.Run("sixel", func(t *testing.T) {
+		enc, err := encodeImage(imageProtocolSixel, img, 10, 10, 10, 20, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(enc.Seq, "\x1bP0;1q\"1;1;100;50") || !strings.HasSuffix(enc.Seq, "\x1b\\") {
+			t.Errorf("Unexpected Sixel sequence %q", enc.Seq[:20])
+		}
+	})
+
+	t.Run("iterm2 in tmux", func(t *testing.T) {
+		enc, err := encodeImage(imageProtocolITerm2, img, 10, 10, 10, 20, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(enc.Seq, "\x1bPtmux;\x1b\x1b]1337;File=") || !strings.Contains(enc.Seq, "inline=1") {
+			t.Errorf("Unexpected iTerm2 sequence %q", enc.Seq[:40])
+		}
+	})
+
+	t.Run("no encoder", func(t *testing.T) {
+		if _, err := encodeImage(imageProtocolASCII, img, 10, 10, 10, 20, false); err == nil {
+			t.Error("Expected error for ASCII protocol")
+		}
+	})
+}
+
+func TestImageCapabilityReplies(t *testing.T) {
+	m := Model{}
+	m.updateImageCapability(uv.PrimaryDeviceAttributesEvent{62, 4, 22})
+	if m.imageProtocol != imageProtocolSixel {
+		t.Errorf("Expected sixel from DA1, got %v", m.imageProtocol)
+	}
+	m.updateImageCapability(uv.KittyGraphicsEvent{Options: kitty.Options{ID: kittyQueryID}, Payload: []byte("OK")})
+	if m.imageProtocol != imageProtocolKitty {
+		t.Errorf("Expected kitty from graphics reply, got %v", m.imageProtocol)
+	}
+	m.updateImageCapability(uv.CellSizeEvent{Width: 9, Height: 18})
+	if w, h := m.cellSize(); w != 9 || h != 18 {
+		t.Errorf("cellSize = %dx%d, expected 9x18", w, h)
+	}
+
+	// A protocol set in config is kept
+	forced := Model{}
+	forced.applyConfig(Config{Preview: PreviewConfig{ImageProtocol: "iterm2"}})
+	forced.updateImageCapability(uv.PrimaryDeviceAttributesEvent{4})
+	if forced.imageProtocol != imageProtocolITerm2 || forced.queryImageCapabilities() != nil {
+		t.Errorf("Expected forced iterm2 without query, got %v", forced.imageProtocol)
+	}
+}
+
+func TestPreview_NativeImage(t *testing.T) {
+	tmpDir := t.TempDir()
+	f, err := os.Create(filepath.Join(tmpDir, "pic.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	png.Encode(f, testImage(64, 32))
+	f.Close()
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.width = 80
+	model.height = 24
+	model.selected = 1
+	model.imageProtocol = imageProtocolKitty
+
+	if cmd := model.openPreview(); cmd == nil {
+		t.Fatal("Expected a draw command")
+	}
+	if model.inputMode != ModePreview || !model.previewIsImage || model.previewImageSeq == "" {
+		t.Fatal("Expected native image preview")
+	}
+	// 78x20 cells at 10x20 px: the 2:1 image scales to 780x390 px = 20 rows
+	if len(model.previewContent) != 20 {
+		t.Errorf("Expected 20 reserved rows, got %d", len(model.previewContent))
+	}
+
+	if model.drawImage(imageDrawMsg{id: model.imageDrawID}) == nil {
+		t.Error("Expected current draw to be sent")
+	}
+	if model.drawImage(imageDrawMsg{id: model.imageDrawID - 1}) != nil {
+		t.Error("Expected stale draw to be dropped")
+	}
+	model.closePreview()
+	if model.drawImage(imageDrawMsg{id: model.imageDrawID}) != nil {
+		t.Error("Expected no draw after closing the preview")
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -15,22 +17,114 @@ func main() {
 	// tmux and other terminals may not be detected correctly
 	initColorProfile()
 
-	path := "."
-	if len(os.Args) > 1 {
-		path = os.Args[1]
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		os.Exit(runControl(os.Args[2:], os.Stdout, os.Stderr))
 	}
 
-	model, err := NewModel(path)
+	opts, err := parseArgs(os.Args[1:])
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	if opts.ShellInit != "" {
+		script, err := shellInitScript(opts.ShellInit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Print(script)
+		return
+	}
+
+	cfg, err := LoadConfig(configPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+	root, reveal, err := startupPaths(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	model, err := NewModel(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	model.applyConfig(cfg)
+	model.chooseMode = opts.Choose
+	// Chooser runs start clean so saved marks are not picked by accident
+	model.sessionEnabled = opts.Choose == chooseNone
+	if !opts.Fresh {
+		model.restoreSession()
+	}
+	if reveal != "" {
+		// Init starts the watcher, so the watch command isn't needed here
+		if _, err := model.revealPath(reveal); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		model.message = ""
+		model.centerPending = true
+	}
+
+	// Remote control socket; bon3 keeps working without it
+	control, err := NewControlServer(controlSocketPath(os.Getpid()))
+	if err == nil {
+		model.control = control
+		defer control.Close()
+		// Shell commands and editors started from bon3 talk to this instance
+		os.Setenv(ControlSocketEnv, control.Path())
+	}
+
+	var programOpts []tea.ProgramOption
+	if opts.Choose != chooseNone {
+		// Stdout carries the picked paths, so the TUI talks to the terminal directly
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer tty.Close()
+		programOpts = append(programOpts, tea.WithInput(tty), tea.WithOutput(tty))
+	}
+
+	p := tea.NewProgram(model, programOpts...)
+	final, err := p.Run()
+	control.Close() // os.Exit below skips deferred calls
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	m := final.(Model)
+	if err := m.saveSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saving session: %v\n", err)
+	}
+
+	if opts.CwdFile != "" {
+		if err := writeCwdFile(opts.CwdFile, m.exitDir()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if opts.Choose != chooseNone {
+		chosen := m.chosen
+		if chosen == nil {
+			// Cancelled: nothing printed, non-zero status for scripts
+			os.Exit(1)
+		}
+		if err := writeChosen(opts, chosen); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
 
 // initColorProfile sets the lipgloss color profile based on environment.
@@ -0,0 +1,597 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Markdown rendering for preview.
+// Rendering produces plain text lines plus styled spans (the same shape as syntax
+// highlighting), so search, scrolling and the line renderer keep working unchanged.
+
+// mdHeading is a heading entry for the table of contents
+type mdHeading struct {
+	Level   int    // 1-6
+	Text    string // Heading text (inline markup removed)
+	Line    int    // Line index in the rendered document
+	SrcLine int    // Line index in the raw source
+}
+
+// renderedDoc is a preview document rendered to plain lines and spans
+type renderedDoc struct {
+	Lines    []string
+	Spans    [][]textSpan
+	Headings []mdHeading
+}
+
+// add appends a line with its spans
+func (d *renderedDoc) add(line string, spans []textSpan) {
+	d.Lines = append(d.Lines, line)
+	d.Spans = append(d.Spans, spans)
+}
+
+// addBlank appends an empty line, collapsing consecutive blanks
+func (d *renderedDoc) addBlank() {
+	if len(d.Lines) == 0 || d.Lines[len(d.Lines)-1] == "" {
+		return
+	}
+	d.add("", nil)
+}
+
+var (
+	mdHeadingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListRe      = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdRuleRe      = regexp.MustCompile(`^\s*(-(\s*-){2,}|\*(\s*\*){2,}|_(\s*_){2,})\s*$`)
+	mdTableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdTaskBoxRe   = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	mdFenceOpenRe = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)")
+)
+
+// isMarkdownFile returns true if the path looks like a Markdown document
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".mdown", ".mkd":
+		return true
+	}
+	return false
+}
+
+// renderMarkdown renders Markdown source lines wrapped to width
+func renderMarkdown(src []string, width int) renderedDoc {
+	if width < 20 {
+		width = 20
+	}
+
+	var doc renderedDoc
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text, spans := parseInline(strings.Join(paragraph, " "))
+		lines, lineSpans := wrapStyled(text, spans, width, "", "")
+		for i := range lines {
+			doc.add(lines[i], lineSpans[i])
+		}
+		doc.addBlank()
+		paragraph = nil
+	}
+
+	for i := 0; i < len(src); i++ {
+		line := strings.TrimRight(src[i], " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		// Fenced code block
+		if fence := mdFenceOpenRe.FindStringSubmatch(line); fence != nil {
+			flushParagraph()
+			i = renderCodeBlock(&doc, src, i, fence[1], fence[2], width)
+			doc.addBlank()
+			continue
+		}
+
+		// Blank line ends a paragraph
+		if trimmed == "" {
+			flushParagraph()
+			continue
+		}
+
+		// Heading
+		if h := mdHeadingRe.FindStringSubmatch(line); h != nil {
+			flushParagraph()
+			doc.addBlank()
+			level := len(h[1])
+			text, _ := parseInline(h[2])
+			doc.Headings = append(doc.Headings, mdHeading{Level: level, Text: text, Line: len(doc.Lines), SrcLine: i})
+			lines, _ := wrapStyled(text, nil, width, "", "")
+			for _, l := range lines {
+				doc.add(l, []textSpan{{Start: 0, End: len(l), Style: mdHeadingStyle(level)}})
+			}
+			// Underline top-level headings
+			if level <= 2 {
+				char := "─"
+				if level == 1 {
+					char = "═"
+				}
+				rule := strings.Repeat(char, min(ansi.StringWidth(text), width))
+				doc.add(rule, []textSpan{{Start: 0, End: len(rule), Style: mdHeadingStyle(level)}})
+			}
+			doc.addBlank()
+			continue
+		}
+
+		// Horizontal rule
+		if mdRuleRe.MatchString(line) && len(paragraph) == 0 {
+			rule := strings.Repeat("─", width)
+			doc.add(rule, []textSpan{{Start: 0, End: len(rule), Style: mdRuleStyle}})
+			doc.addBlank()
+			continue
+		}
+
+		// Table: header row followed by a separator row
+		if strings.Contains(line, "|") && i+1 < len(src) && mdTableSepRe.MatchString(src[i+1]) && strings.Contains(src[i+1], "-") {
+			flushParagraph()
+			i = renderTable(&doc, src, i, width)
+			doc.addBlank()
+			continue
+		}
+
+		// Blockquote
+		if strings.HasPrefix(trimmed, ">") {
+			flushParagraph()
+			var quote []string
+			for ; i < len(src); i++ {
+				t := strings.TrimSpace(src[i])
+				if !strings.HasPrefix(t, ">") {
+					break
+				}
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(t, ">")))
+			}
+			i--
+			text, spans := parseInline(strings.Join(quote, " "))
+			spans = append([]textSpan{{Start: 0, End: len(text), Style: mdQuoteStyle}}, spans...)
+			lines, lineSpans := wrapStyled(text, spans, width, "│ ", "│ ")
+			for j := range lines {
+				doc.add(lines[j], append([]textSpan{{Start: 0, End: len("│"), Style: mdRuleStyle}}, lineSpans[j]...))
+			}
+			doc.addBlank()
+			continue
+		}
+
+		// List item (with continuation lines)
+		if item := mdListRe.FindStringSubmatch(line); item != nil {
+			flushParagraph()
+			body := item[3]
+			for i+1 < len(src) {
+				next := src[i+1]
+				nt := strings.TrimSpace(next)
+				if nt == "" || mdListRe.MatchString(next) || mdHeadingRe.MatchString(next) || mdFenceOpenRe.MatchString(next) {
+					break
+				}
+				body += " " + nt
+				i++
+			}
+			renderListItem(&doc, item[1], item[2], body, width)
+			// Blank line after the list (not between items)
+			if i+1 >= len(src) || !mdListRe.MatchString(src[i+1]) {
+				doc.addBlank()
+			}
+			continue
+		}
+
+		paragraph = append(paragraph, trimmed)
+	}
+	flushParagraph()
+
+	// Trim trailing blank line
+	if n := len(doc.Lines); n > 0 && doc.Lines[n-1] == "" {
+		doc.Lines = doc.Lines[:n-1]
+		doc.Spans = doc.Spans[:n-1]
+	}
+	return doc
+}
+
+// renderCodeBlock renders a fenced code block starting at src[start] and
+// returns the index of the closing fence
+func renderCodeBlock(doc *renderedDoc, src []string, start int, fence, lang string, width int) int {
+	hl := highlighterFor(strings.ToLower(lang))
+	if hl == nil {
+		if l, ok := langByExt["."+strings.ToLower(lang)]; ok {
+			hl = highlighterFor(l)
+		}
+	}
+
+	var state lexState
+	i := start + 1
+	for ; i < len(src); i++ {
+		if strings.HasPrefix(strings.TrimSpace(src[i]), fence) {
+			break
+		}
+		code := strings.TrimRight(src[i], "\r")
+		prefix := "  "
+		line := prefix + code
+		spans := []textSpan{{Start: 0, End: len(line), Style: mdCodeBlockStyle}}
+		if hl != nil {
+			for _, s := range hl(code, &state) {
+				s.Start += len(prefix)
+				s.End += len(prefix)
+				s.Style = s.Style.Inherit(mdCodeBlockStyle) // Keep block background
+				spans = append(spans, s)
+			}
+		}
+		// Pad to width so the code background forms a block
+		if pad := width - ansi.StringWidth(line); pad > 0 {
+			line += strings.Repeat(" ", pad)
+			spans[0].End = len(line)
+		}
+		doc.add(line, spans)
+	}
+	return i
+}
+
+// renderListItem renders a list item with a bullet and hanging indent
+func renderListItem(doc *renderedDoc, indent, marker, body string, width int) {
+	depth := len(strings.ReplaceAll(indent, "\t", "  ")) / 2
+	pad := strings.Repeat("  ", depth)
+
+	bullet := "•"
+	switch {
+	case depth%3 == 1:
+		bullet = "◦"
+	case depth%3 == 2:
+		bullet = "▪"
+	}
+	if marker[0] >= '0' && marker[0] <= '9' {
+		bullet = marker
+	}
+
+	// Task list checkboxes
+	if box := mdTaskBoxRe.FindStringSubmatch(body); box != nil {
+		if box[1] == " " {
+			bullet = "☐"
+		} else {
+			bullet = "☑"
+		}
+		body = body[len(box[0]):]
+	}
+
+	first := pad + bullet + " "
+	rest := pad + strings.Repeat(" ", ansi.StringWidth(bullet)+1)
+	text, spans := parseInline(body)
+	lines, lineSpans := wrapStyled(text, spans, width, first, rest)
+	for i := range lines {
+		s := lineSpans[i]
+		if i == 0 {
+			s = append([]textSpan{{Start: len(pad), End: len(pad) + len(bullet), Style: mdBulletStyle}}, s...)
+		}
+		doc.add(lines[i], s)
+	}
+}
+
+// splitTableRow splits a table row into trimmed cells
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// renderTable renders a table starting at src[start] (header row) and returns
+// the index of its last row
+func renderTable(doc *renderedDoc, src []string, start int, width int) int {
+	header := splitTableRow(src[start])
+	rows := [][]string{header}
+	i := start + 2
+	for ; i < len(src); i++ {
+		if !strings.Contains(src[i], "|") || strings.TrimSpace(src[i]) == "" {
+			break
+		}
+		rows = append(rows, splitTableRow(src[i]))
+	}
+
+	cols := len(header)
+	texts := make([][]string, len(rows))
+	cellSpans := make([][][]textSpan, len(rows))
+	widths := make([]int, cols)
+	for r, row := range rows {
+		texts[r] = make([]string, cols)
+		cellSpans[r] = make([][]textSpan, cols)
+		for c := 0; c < cols; c++ {
+			if c < len(row) {
+				texts[r][c], cellSpans[r][c] = parseInline(row[c])
+			}
+			widths[c] = max(widths[c], ansi.StringWidth(texts[r][c]))
+		}
+	}
+
+	// Shrink the widest columns until the table fits (" │ " between columns)
+	available := width - 3*(cols-1)
+	for {
+		total := 0
+		widest := 0
+		for c, w := range widths {
+			total += w
+			if w > widths[widest] {
+				widest = c
+			}
+		}
+		if total <= available || widths[widest] <= 3 {
+			break
+		}
+		widths[widest]--
+	}
+
+	sep := " │ "
+	for r := range rows {
+		var line strings.Builder
+		var spans []textSpan
+		for c := 0; c < cols; c++ {
+			if c > 0 {
+				spans = append(spans, textSpan{Start: line.Len(), End: line.Len() + len(sep), Style: mdRuleStyle})
+				line.WriteString(sep)
+			}
+			cell := texts[r][c]
+			if ansi.StringWidth(cell) > widths[c] {
+				cell = ansi.Truncate(cell, widths[c]-1, "") + "…"
+			}
+			offset := line.Len()
+			if r == 0 {
+				spans = append(spans, textSpan{Start: offset, End: offset + len(cell), Style: mdTableHeaderStyle})
+			}
+			for _, s := range cellSpans[r][c] {
+				if s.Start < len(cell) {
+					spans = append(spans, textSpan{Start: offset + s.Start, End: offset + min(s.End, len(cell)), Style: s.Style})
+				}
+			}
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[c]-ansi.StringWidth(cell)))
+		}
+		doc.add(strings.TrimRight(line.String(), " "), spans)
+
+		// Header separator
+		if r == 0 {
+			parts := make([]string, cols)
+			for c := range parts {
+				parts[c] = strings.Repeat("─", widths[c])
+			}
+			rule := strings.Join(parts, "─┼─")
+			doc.add(rule, []textSpan{{Start: 0, End: len(rule), Style: mdRuleStyle}})
+		}
+	}
+	return i - 1
+}
+
+// parseInline strips inline Markdown markup and returns plain text with spans
+// for code, emphasis and links
+func parseInline(src string) (string, []textSpan) {
+	var b strings.Builder
+	var spans []textSpan
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src) && strings.IndexByte("\\`*_[]()#+-.!|<>", src[i+1]) >= 0:
+			b.WriteByte(src[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(src[i+1:], '`'); end >= 0 {
+				code := src[i+1 : i+1+end]
+				start := b.Len()
+				b.WriteString(code)
+				spans = append(spans, textSpan{Start: start, End: b.Len(), Style: mdInlineCodeStyle})
+				i += end + 2
+				continue
+			}
+
+		case c == '*' || c == '_':
+			delim := string(c)
+			if strings.HasPrefix(src[i:], delim+delim) {
+				delim += delim
+			}
+			rest := src[i+len(delim):]
+			if end := strings.Index(rest, delim); end > 0 && rest[0] != ' ' {
+				inner, innerSpans := parseInline(rest[:end])
+				style := mdEmphasisStyle
+				if len(delim) == 2 {
+					style = mdStrongStyle
+				}
+				start := b.Len()
+				b.WriteString(inner)
+				spans = append(spans, textSpan{Start: start, End: b.Len(), Style: style})
+				spans = append(spans, offsetSpans(innerSpans, start)...)
+				i += len(delim) + end + len(delim)
+				continue
+			}
+
+		case c == '!' && strings.HasPrefix(src[i:], "!["):
+			// Image: render alt text
+			if text, _, n := parseLink(src[i+1:]); n > 0 {
+				start := b.Len()
+				b.WriteString("[image: " + text + "]")
+				spans = append(spans, textSpan{Start: start, End: b.Len(), Style: mdRuleStyle})
+				i += 1 + n
+				continue
+			}
+
+		case c == '[':
+			if text, url, n := parseLink(src[i:]); n > 0 {
+				inner, innerSpans := parseInline(text)
+				start := b.Len()
+				b.WriteString(inner)
+				spans = append(spans, textSpan{Start: start, End: b.Len(), Style: syntaxLinkStyle})
+				spans = append(spans, offsetSpans(innerSpans, start)...)
+				// Show external URLs after the link text
+				if strings.Contains(url, "://") && url != inner {
+					urlStart := b.Len()
+					b.WriteString(" <" + url + ">")
+					spans = append(spans, textSpan{Start: urlStart, End: b.Len(), Style: mdRuleStyle})
+				}
+				i += n
+				continue
+			}
+		}
+
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), spans
+}
+
+// parseLink parses "[text](url)" at the start of s and returns the consumed length
+func parseLink(s string) (text, url string, n int) {
+	if !strings.HasPrefix(s, "[") {
+		return "", "", 0
+	}
+	closeText := strings.Index(s, "](")
+	if closeText < 0 {
+		return "", "", 0
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 0 {
+		return "", "", 0
+	}
+	url = s[closeText+2 : closeText+2+closeURL]
+	if i := strings.IndexByte(url, ' '); i >= 0 {
+		url = url[:i] // Drop link title
+	}
+	return s[1:closeText], url, closeText + 2 + closeURL + 1
+}
+
+// offsetSpans shifts spans by offset bytes
+func offsetSpans(spans []textSpan, offset int) []textSpan {
+	shifted := make([]textSpan, len(spans))
+	for i, s := range spans {
+		shifted[i] = textSpan{Start: s.Start + offset, End: s.End + offset, Style: s.Style}
+	}
+	return shifted
+}
+
+// wrapStyled word-wraps text to width, carrying spans onto the wrapped lines.
+// firstPrefix is prepended to the first line and restPrefix to the others.
+func wrapStyled(text string, spans []textSpan, width int, firstPrefix, restPrefix string) ([]string, [][]textSpan) {
+	// Owning span per byte (later spans win)
+	owner := make([]int, len(text))
+	for i := range owner {
+		owner[i] = -1
+	}
+	for si, s := range spans {
+		for i := max(s.Start, 0); i < min(s.End, len(text)); i++ {
+			owner[i] = si
+		}
+	}
+
+	var lines []string
+	var lineSpans [][]textSpan
+	var cur strings.Builder
+	var curSpans []textSpan
+	curWidth := 0
+	hasWord := false
+
+	newLine := func(prefix string) {
+		cur.Reset()
+		cur.WriteString(prefix)
+		curSpans = nil
+		curWidth = ansi.StringWidth(prefix)
+		hasWord = false
+	}
+	endLine := func() {
+		lines = append(lines, cur.String())
+		lineSpans = append(lineSpans, curSpans)
+	}
+	// appendRange copies text[start:end] into the current line with its spans
+	appendRange := func(start, end int) {
+		for i := start; i < end; {
+			j := i + 1
+			for j < end && owner[j] == owner[i] {
+				j++
+			}
+			offset := cur.Len()
+			cur.WriteString(text[i:j])
+			if owner[i] >= 0 {
+				curSpans = append(curSpans, textSpan{Start: offset, End: cur.Len(), Style: spans[owner[i]].Style})
+			}
+			i = j
+		}
+		curWidth += ansi.StringWidth(text[start:end])
+	}
+
+	newLine(firstPrefix)
+	for i := 0; i < len(text); {
+		// Skip spaces between words
+		if text[i] == ' ' {
+			i++
+			continue
+		}
+		end := strings.IndexByte(text[i:], ' ')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += i
+		}
+		wordWidth := ansi.StringWidth(text[i:end])
+
+		sepWidth := 0
+		if hasWord {
+			sepWidth = 1
+		}
+		if hasWord && curWidth+sepWidth+wordWidth > width {
+			endLine()
+			newLine(restPrefix)
+			sepWidth = 0
+		}
+		if sepWidth > 0 {
+			cur.WriteByte(' ')
+			curWidth++
+		}
+
+		// Hard-break words longer than the line
+		for curWidth+wordWidth > width && width-curWidth > 0 {
+			cut := i
+			w := 0
+			for _, r := range text[i:end] {
+				rw := ansi.StringWidth(string(r))
+				if curWidth+w+rw > width {
+					break
+				}
+				w += rw
+				cut += len(string(r))
+			}
+			if cut == i {
+				break
+			}
+			appendRange(i, cut)
+			endLine()
+			newLine(restPrefix)
+			i = cut
+			wordWidth = ansi.StringWidth(text[i:end])
+		}
+
+		appendRange(i, end)
+		hasWord = true
+		i = end
+	}
+	endLine()
+	return lines, lineSpans
+}
+
+// mdHeadingStyle returns the style for a heading level
+func mdHeadingStyle(level int) lipgloss.Style {
+	switch level {
+	case 1:
+		return mdH1Style
+	case 2:
+		return mdH2Style
+	default:
+		return mdH3Style
+	}
+}
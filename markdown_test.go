@@ -0,0 +1,233 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestIsMarkdownFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"README.md", true},
+		{"notes.Markdown", true},
+		{"main.go", false},
+		{"md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := isMarkdownFile(tt.path); got != tt.expected {
+				t.Errorf("isMarkdownFile(%q) = %v, expected %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		spans    int
+	}{
+		{"plain", "hello world", "hello world", 0},
+		{"strong", "a **bold** word", "a bold word", 1},
+		{"emphasis", "an *italic* word", "an italic word", 1},
+		{"code", "run `go test`", "run go test", 1},
+		{"link", "see [docs](https://example.com)", "see docs <https://example.com>", 1},
+		{"escape", `not \*emphasis\*`, "not *emphasis*", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, spans := parseInline(tt.input)
+			if text != tt.expected {
+				t.Errorf("parseInline(%q) text = %q, expected %q", tt.input, text, tt.expected)
+			}
+			if len(spans) < tt.spans {
+				t.Errorf("parseInline(%q) = %d spans, expected at least %d", tt.input, len(spans), tt.spans)
+			}
+			for _, s := range spans {
+				if s.Start < 0 || s.End > len(text) || s.Start > s.End {
+					t.Errorf("span out of range: %+v for %q", s, text)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapStyled(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	lines, spans := wrapStyled(text, nil, 10, "", "")
+	if len(lines) != len(spans) {
+		t.Fatalf("lines and spans differ in length: %d vs %d", len(lines), len(spans))
+	}
+	if len(lines) < 4 {
+		t.Errorf("Expected text to wrap into several lines, got %v", lines)
+	}
+	for _, l := range lines {
+		if ansi.StringWidth(l) > 10 {
+			t.Errorf("Line exceeds width: %q", l)
+		}
+	}
+	if strings.Join(lines, " ") != text {
+		t.Errorf("Wrapped text lost words: %v", lines)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	src := []string{
+		"# Title",
+		"",
+		"Some *intro* text.",
+		"",
+		"## Usage",
+		"",
+		"- first",
+		"- [x] done",
+		"",
+		"```go",
+		"func main() {}",
+		"```",
+		"",
+		"| Name | Value |",
+		"|------|-------|",
+		"| a    | 1     |",
+	}
+
+	doc := renderMarkdown(src, 40)
+	if len(doc.Lines) != len(doc.Spans) {
+		t.Fatalf("Lines and spans differ in length: %d vs %d", len(doc.Lines), len(doc.Spans))
+	}
+
+	// Headings are recorded with rendered and source positions
+	if len(doc.Headings) != 2 {
+		t.Fatalf("Expected 2 headings, got %d", len(doc.Headings))
+	}
+	if doc.Headings[0].Text != "Title" || doc.Headings[0].SrcLine != 0 {
+		t.Errorf("Unexpected first heading: %+v", doc.Headings[0])
+	}
+	if doc.Headings[1].Level != 2 || doc.Headings[1].SrcLine != 4 {
+		t.Errorf("Unexpected second heading: %+v", doc.Headings[1])
+	}
+	if doc.Lines[doc.Headings[1].Line] != "Usage" {
+		t.Errorf("Heading line = %q, expected %q", doc.Lines[doc.Headings[1].Line], "Usage")
+	}
+
+	out := strings.Join(doc.Lines, "\n")
+	for _, want := range []string{"intro", "• first", "☑ done", "func main() {}", "Name │ Value", "a    │ 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Rendered output missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"# Title", "*intro*", "```"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Rendered output still contains markup %q:\n%s", unwanted, out)
+		}
+	}
+
+	for _, l := range doc.Lines {
+		if ansi.StringWidth(l) > 40 {
+			t.Errorf("Line exceeds width: %q", l)
+		}
+	}
+}
+
+func TestPreview_ToggleRendered(t *testing.T) {
+	tmpDir := t.TempDir()
+	var src []string
+	src = append(src, "# Intro", "")
+	for i := 0; i < 40; i++ {
+		src = append(src, "filler paragraph", "")
+	}
+	src = append(src, "## Details", "")
+	for i := 0; i < 40; i++ {
+		src = append(src, "more filler", "")
+	}
+	os.WriteFile(filepath.Join(tmpDir, "doc.md"), []byte(strings.Join(src, "\n")), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.width = 80
+	model.height = 20
+	model.selected = 1
+	model.openPreview()
+	model.inputMode = ModePreview
+	raw := model.previewContent
+
+	// m switches to the rendered view
+	newModel, _ := model.Update(keyMsg("m"))
+	m := newModel.(Model)
+	if !m.previewRendered {
+		t.Fatal("Expected rendered view after m")
+	}
+	if len(m.previewHeadings) != 2 {
+		t.Fatalf("Expected 2 headings, got %d", len(m.previewHeadings))
+	}
+
+	// ] jumps to the next heading
+	newModel, _ = m.Update(keyMsg("]"))
+	m = newModel.(Model)
+	if m.currentHeadingIndex() != 1 {
+		t.Errorf("Expected second heading after ], got %d", m.currentHeadingIndex())
+	}
+
+	// Clicking a TOC entry jumps to that heading
+	newModel, _ = m.Update(keyMsg("t"))
+	m = newModel.(Model)
+	if !m.previewShowTOC {
+		t.Fatal("Expected TOC after t")
+	}
+	newModel, _ = m.Update(tea.MouseClickMsg{X: 1, Y: 1, Button: tea.MouseLeft})
+	m = newModel.(Model)
+	if m.previewScroll != m.previewHeadings[0].Line {
+		t.Errorf("Expected scroll to first heading after TOC click, got %d", m.previewScroll)
+	}
+
+	// m returns to the raw view
+	newModel, _ = m.Update(keyMsg("m"))
+	m = newModel.(Model)
+	if m.previewRendered || m.previewShowTOC {
+		t.Error("Expected raw view after second m")
+	}
+	if len(m.previewContent) != len(raw) {
+		t.Errorf("Expected raw content restored, got %d lines (want %d)", len(m.previewContent), len(raw))
+	}
+}
+
+func TestPreview_ToggleRenderedNonMarkdown(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.selected = 1
+	model.openPreview()
+	model.togglePreviewRendered()
+	if model.previewRendered {
+		t.Error("Expected no rendered view for non-Markdown files")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// mmapFile is unsupported here; pagedFile falls back to ReadAt
+func mmapFile(f *os.File, size int64) ([]byte, error) {
+	return nil, nil
+}
+
+// munmapFile is a no-op without memory mapping
+func munmapFile(data []byte) error {
+	return nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"os"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
@@ -19,6 +21,14 @@ const (
 	ModeConfirmDelete
 	ModePreview
 	ModeGoTo
+	ModePreviewSearch
+	ModePreviewQuery
+	ModeNewArchive
+	ModePreviewJump
+	ModeShell
+	ModeShellOutput
+	ModeAgentPick
+	ModeRecent
 )
 
 // String returns a string representation of the InputMode
@@ -40,6 +50,22 @@ func (m InputMode) String() string {
 		return "preview"
 	case ModeGoTo:
 		return "goto"
+	case ModePreviewSearch:
+		return "preview_search"
+	case ModePreviewQuery:
+		return "preview_query"
+	case ModeNewArchive:
+		return "newarchive"
+	case ModePreviewJump:
+		return "preview_jump"
+	case ModeShell:
+		return "shell"
+	case ModeShellOutput:
+		return "shell_output"
+	case ModeAgentPick:
+		return "agent_pick"
+	case ModeRecent:
+		return "recent"
 	default:
 		return "unknown"
 	}
@@ -73,6 +99,9 @@ var (
 	cutStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240"))
 
+	symlinkTargetStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("244"))
+
 	inputStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("212")).
@@ -131,6 +160,107 @@ var (
 
 	diffCurrentLineStyle = lipgloss.NewStyle().
 				Background(lipgloss.Color("236"))
+
+	// Search match styles (Preview mode)
+	previewMatchStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("58")).
+				Foreground(lipgloss.Color("229"))
+
+	previewCurrentMatchStyle = lipgloss.NewStyle().
+					Background(lipgloss.Color("214")).
+					Foreground(lipgloss.Color("16")).
+					Bold(true)
+
+	// Syntax highlight styles (Preview mode, tuned to the tree palette)
+	syntaxKeywordStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("212")) // Pink (matches root/title)
+
+	syntaxBuiltinStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("81")) // Cyan
+
+	syntaxStringStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("150")) // Soft green
+
+	syntaxNumberStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("215")) // Orange
+
+	syntaxCommentStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("244")). // Gray
+				Italic(true)
+
+	syntaxFunctionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("111")) // Light blue
+
+	syntaxKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("69")) // Blue (matches directories)
+
+	syntaxHeadingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("212")).
+				Bold(true)
+
+	syntaxVariableStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("179")) // Gold
+
+	syntaxLinkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("75")).
+			Underline(true)
+
+	// Rendered Markdown styles (Preview mode)
+	mdH1Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("212")).
+			Bold(true)
+
+	mdH2Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("111")).
+			Bold(true)
+
+	mdH3Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("81")).
+			Bold(true)
+
+	mdStrongStyle = lipgloss.NewStyle().
+			Bold(true)
+
+	mdEmphasisStyle = lipgloss.NewStyle().
+			Italic(true)
+
+	mdInlineCodeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("215")).
+				Background(lipgloss.Color("236"))
+
+	mdCodeBlockStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("252")).
+				Background(lipgloss.Color("235"))
+
+	mdQuoteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("248")).
+			Italic(true)
+
+	mdBulletStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("212"))
+
+	mdRuleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240"))
+
+	mdTableHeaderStyle = lipgloss.NewStyle().
+				Bold(true)
+
+	// Hex viewer cursor (Preview mode)
+	hexCursorStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("212")).
+			Foreground(lipgloss.Color("16"))
+
+	// Split-pane divider
+	splitDividerStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("240"))
+
+	// Table of contents (Preview mode)
+	tocStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("248"))
+
+	tocCurrentStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("212")).
+			Bold(true)
 )
 
 // Model represents the application state
@@ -176,6 +306,110 @@ type Model struct {
 	previewDiffMap   map[int]DiffLine // Line number -> DiffLine for quick lookup
 	previewDiffIndex int              // Current diff index (-1 = none selected)
 
+	// Preview search
+	previewBytes       []byte         // Raw content for binary (hex) previews
+	previewSearchQuery string         // Current search query
+	previewSearchCase  bool           // Case-sensitive matching
+	previewSearchRegex bool           // Treat query as regular expression
+	previewSearchErr   string         // Error for invalid query (e.g., bad regex)
+	previewMatches     []previewMatch // All matches for the current query
+	previewMatchIndex  int            // Current match index (-1 = none selected)
+
+	// Preview syntax highlighting and horizontal scroll
+	previewSyntax  [][]textSpan // Syntax spans per line (nil = no highlighting)
+	previewHScroll int          // Horizontal scroll offset in columns
+
+	// Preview text encoding, line endings and indentation (zero for non-text previews)
+	previewTextInfo textInfo
+
+	// Rendered preview (Markdown, data, tables); raw state is kept to toggle back
+	previewRendered     bool          // Showing the rendered view
+	previewRaw          []string      // Raw lines while rendered
+	previewRawSyntax    [][]textSpan  // Raw syntax spans while rendered
+	previewRawDiffLines []DiffLine    // Raw diff lines while rendered
+	previewHeadings     []mdHeading   // Headings for table of contents
+	previewShowTOC      bool          // Table of contents sidebar visible
+	previewFormat       previewFormat // Format of the rendered view
+
+	// Structured preview (JSON/YAML/TOML tree, CSV/TSV table)
+	previewDataRoot     *dataNode    // Parsed document
+	previewDataRoots    []*dataNode  // Displayed roots (query results, or the document)
+	previewDataRows     []*dataNode  // Node for each rendered line
+	previewCursor       int          // Cursor line (data tree and archive listing)
+	previewQuery        string       // Path query (empty = whole document)
+	previewQueryErr     string       // Error for an invalid or empty query
+	previewTable        [][]string   // Parsed table records
+	previewHeader       []string     // Frozen header lines above the content (table)
+	previewHeaderSyntax [][]textSpan // Spans for header lines
+
+	// Archive preview
+	previewIsArchive     bool            // Showing an archive listing
+	previewArchive       []archiveEntry  // Archive entries
+	previewArchiveMarked map[string]bool // Marked entry names for extraction
+	archivePaths         []string        // Paths to archive (captured when the name prompt opens)
+
+	// Streaming preview (files too large to load) and jump prompt
+	previewStream    *streamView // Paged view of a large file (nil = fully loaded)
+	previewJumpInput string      // Jump prompt input (line, offset or percentage)
+	previewJumpErr   string      // Error for invalid jump input
+
+	// Split-pane preview (live preview next to the tree)
+	splitPane    bool          // Preview pane shown next to the tree
+	splitPercent int           // Tree width as a percentage of the terminal
+	splitPath    string        // Path the pane follows (the selection)
+	splitID      int           // Generation of the pending load (stale loads are dropped)
+	splitContent *splitContent // Loaded content (nil = not loaded yet)
+	splitScroll  int           // Scroll offset in the pane
+
+	// Inline images (Kitty, Sixel, iTerm2)
+	config          Config        // User settings from config.toml
+	imageProtocol   imageProtocol // Protocol for image previews (auto = chafa or ASCII art)
+	imageForced     bool          // Protocol set in config (capability replies are ignored)
+	cellWidth       int           // Cell width in pixels (0 = not reported)
+	cellHeight      int           // Cell height in pixels (0 = not reported)
+	previewImageSeq string        // Encoded preview image drawn over blank rows
+	imageDrawID     int           // Generation of the pending image draw
+
+	// Shell commands (! prompt and output pane)
+	shellHistory      []string           // Commands run this session (oldest first)
+	shellHistoryIndex int                // History position while browsing (len = new input)
+	shellDraft        string             // Input typed before browsing the history
+	shellCommand      string             // Command shown in the output pane
+	shellScript       string             // Expanded command (rerun with the same paths)
+	shellOutput       []string           // Captured output lines
+	shellScroll       int                // Scroll offset in the output pane
+	shellRunning      bool               // Command still running
+	shellExitCode     int                // Exit status of the last command
+	shellID           int                // Generation of the running command
+	shellCancel       context.CancelFunc // Stops the running command
+
+	// Send to agent (@path references to another pane)
+	agentTarget     *agentTarget  // Target chosen this session (nil = pick on first send)
+	agentCandidates []agentTarget // Targets offered by the picker
+	agentIndex      int           // Selected candidate in the picker
+	agentPending    []string      // Paths to send once a target is picked
+
+	// Chooser mode (--choose): picking paths exits and prints them
+	chooseMode chooseMode // chooseNone = normal file manager
+	chosen     []string   // Picked paths (nil = cancelled)
+
+	// Quit with Q: the shell wrapper changes to the selected directory instead of the root
+	quitToSelection bool
+
+	// Remote control socket (bon3 ctl)
+	control *ControlServer
+
+	// Save and restore per-root state (expanded dirs, selection, marks)
+	sessionEnabled bool
+
+	// Center the selection on the first WindowSizeMsg (bon3 path/to/file)
+	centerPending bool
+
+	// Recently changed files (badges, gr, gR panel)
+	recent      recentChanges  // Changes reported by the watcher, oldest first
+	recentList  []recentChange // Entries shown by the panel, newest first
+	recentIndex int            // Selected entry in the panel
+
 	// Mouse support
 	lastClickTime  time.Time
 	lastClickIndex int
@@ -241,15 +475,22 @@ func NewModel(path string) (Model, error) {
 		watcherEnabled:   watcher != nil,
 		completionIndex:  -1,
 		previewDiffIndex: -1,
+
+		previewMatchIndex: -1,
+		splitPercent:      SplitDefaultPercent,
+		imageProtocol:     guessImageProtocol(os.Getenv),
 	}, nil
 }
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	cmds := []tea.Cmd{tickCmd()}
+	cmds := []tea.Cmd{tickCmd(), m.queryImageCapabilities(), tea.Raw(cwdSequence(m.tree.Root.Path))}
 	if m.watcher != nil && m.watcherEnabled {
 		cmds = append(cmds, m.watcher.Watch())
 	}
+	if m.control != nil {
+		cmds = append(cmds, m.control.Listen())
+	}
 	return tea.Batch(cmds...)
 }
 
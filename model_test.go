@@ -496,6 +496,23 @@ func TestModel_Refresh(t *testing.T) {
 	}
 }
 
+func TestModel_Refresh_KeepsSelectedPath(t *testing.T) {
+	m, dir := setupTestModel(t)
+	m.height = 20
+
+	target := filepath.Join(dir, "file.txt")
+	m.selected = m.tree.IndexOf(target)
+
+	// A new directory sorts above the selection
+	os.Mkdir(filepath.Join(dir, "aaa"), 0755)
+	newM, _ := m.Update(keyMsg("R"))
+	m = newM.(Model)
+
+	if node := m.tree.GetNode(m.selected); node == nil || node.Path != target {
+		t.Errorf("Expected %s still selected, got %v", target, node)
+	}
+}
+
 func TestModel_Preview(t *testing.T) {
 	m, _ := setupTestModel(t)
 
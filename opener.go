@@ -0,0 +1,143 @@
+package main
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// External editor and opener commands.
+// Commands are shell text (they may carry arguments, e.g. "code --wait"),
+// so paths are single-quoted and the whole script runs through sh -c.
+
+// defaultEditor is used when neither $VISUAL nor $EDITOR is set
+const defaultEditor = "vi"
+
+// editorFromEnv returns $VISUAL, $EDITOR or the default editor
+func editorFromEnv(getenv func(string) string) string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(getenv(key)); e != "" {
+			return e
+		}
+	}
+	return defaultEditor
+}
+
+// editorScript builds the shell command that opens paths in editor,
+// at line (1-based) when a single file is given and line > 0
+func editorScript(editor string, paths []string, line int) string {
+	args := make([]string, 0, len(paths)+1)
+	if line > 0 && len(paths) == 1 {
+		name := ""
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			name = filepath.Base(fields[0])
+		}
+		switch name {
+		case "code", "code-insiders", "codium", "cursor":
+			args = append(args, "-g", paths[0]+":"+strconv.Itoa(line))
+		case "subl", "hx", "helix", "zed":
+			args = append(args, paths[0]+":"+strconv.Itoa(line))
+		default:
+			// vi, vim, nvim, nano, emacs, micro, kak, ...
+			args = append(args, "+"+strconv.Itoa(line), paths[0])
+		}
+	} else {
+		args = append(args, paths...)
+	}
+	return editor + " " + shellQuoteAll(args)
+}
+
+// defaultOpener returns the platform's "open with default application" command
+func defaultOpener() string {
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+	return "xdg-open"
+}
+
+// fileMIMEType returns the MIME type of path (without parameters),
+// from its extension or, failing that, its first bytes
+func fileMIMEType(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "inode/directory"
+	}
+
+	t := mime.TypeByExtension(filepath.Ext(path))
+	if t == "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return ""
+		}
+		defer file.Close()
+		head := make([]byte, 512)
+		n, _ := file.Read(head)
+		t = http.DetectContentType(head[:n])
+	}
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = t[:i]
+	}
+	return strings.TrimSpace(t)
+}
+
+// resolveOpener picks the opener for a file: extension (".pdf") first,
+// then MIME type ("image/png"), MIME pattern ("image/*"), catch-all ("*")
+// and finally the platform default
+func resolveOpener(openers map[string]string, path, mimeType string) string {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+		if cmd := openers[ext]; cmd != "" {
+			return cmd
+		}
+	}
+	if mimeType != "" {
+		if cmd := openers[mimeType]; cmd != "" {
+			return cmd
+		}
+		if major, _, ok := strings.Cut(mimeType, "/"); ok {
+			if cmd := openers[major+"/*"]; cmd != "" {
+				return cmd
+			}
+		}
+	}
+	if cmd := openers["*"]; cmd != "" {
+		return cmd
+	}
+	return defaultOpener()
+}
+
+// openerScript builds one shell script that opens paths, passing all files
+// that share an opener to a single invocation (in order of first appearance)
+func openerScript(openers map[string]string, paths []string) string {
+	var order []string
+	groups := make(map[string][]string)
+	for _, p := range paths {
+		cmd := resolveOpener(openers, p, fileMIMEType(p))
+		if _, ok := groups[cmd]; !ok {
+			order = append(order, cmd)
+		}
+		groups[cmd] = append(groups[cmd], p)
+	}
+
+	lines := make([]string, 0, len(order))
+	for _, cmd := range order {
+		lines = append(lines, cmd+" "+shellQuoteAll(groups[cmd]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shellQuote quotes s for POSIX sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellQuoteAll quotes each argument and joins them with spaces
+func shellQuoteAll(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestEditorFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"visual wins", map[string]string{"VISUAL": "nvim", "EDITOR": "nano"}, "nvim"},
+		{"editor", map[string]string{"EDITOR": "code --wait"}, "code --wait"},
+		{"default", map[string]string{"VISUAL": " "}, "vi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editorFromEnv(func(k string) string { return tt.env[k] }); got != tt.expected {
+				t.Errorf("editorFromEnv = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEditorScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		editor   string
+		paths    []string
+		line     int
+		expected string
+	}{
+		{"vim at line", "vim", []string{"/a/b.go"}, 12, "vim '+12' '/a/b.go'"},
+		{"code at line", "/usr/bin/code --wait", []string{"/a/b.go"}, 3, "/usr/bin/code --wait '-g' '/a/b.go:3'"},
+		{"helix at line", "hx", []string{"/a/b.go"}, 7, "hx '/a/b.go:7'"},
+		{"no line", "nano", []string{"/a/b.go"}, 0, "nano '/a/b.go'"},
+		{"multiple files ignore line", "vim", []string{"/a", "/b"}, 5, "vim '/a' '/b'"},
+		{"quotes", "vi", []string{"/it's here"}, 0, `vi '/it'\''s here'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editorScript(tt.editor, tt.paths, tt.line); got != tt.expected {
+				t.Errorf("editorScript = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveOpener(t *testing.T) {
+	openers := map[string]string{
+		".md":             "glow -p",
+		"image/*":         "feh",
+		"application/pdf": "zathura",
+		"*":               "less",
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		mimeType string
+		expected string
+	}{
+		{"extension", "/x/README.MD", "text/markdown", "glow -p"},
+		{"exact mime", "/x/doc.pdf", "application/pdf", "zathura"},
+		{"mime pattern", "/x/pic.png", "image/png", "feh"},
+		{"catch-all", "/x/main.go", "text/plain", "less"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveOpener(openers, tt.path, tt.mimeType); got != tt.expected {
+				t.Errorf("resolveOpener = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	if got := resolveOpener(nil, "/x/a.txt", "text/plain"); got != defaultOpener() {
+		t.Errorf("Expected platform default, got %q", got)
+	}
+}
+
+func TestFileMIMEType(t *testing.T) {
+	tmpDir := t.TempDir()
+	noExt := filepath.Join(tmpDir, "notes")
+	os.WriteFile(noExt, []byte("plain words\n"), 0644)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{filepath.Join(tmpDir, "pic.png"), "image/png"},
+		{noExt, "text/plain"},
+		{tmpDir, "inode/directory"},
+	}
+
+	for _, tt := range tests {
+		if got := fileMIMEType(tt.path); got != tt.expected {
+			t.Errorf("fileMIMEType(%s) = %q, expected %q", filepath.Base(tt.path), got, tt.expected)
+		}
+	}
+}
+
+func TestOpenerScript(t *testing.T) {
+	openers := map[string]string{"image/*": "feh", "*": "less"}
+	script := openerScript(openers, []string{"/x/a.png", "/x/b.txt", "/x/c.png"})
+	expected := "feh '/x/a.png' '/x/c.png'\nless '/x/b.txt'"
+	if script != expected {
+		t.Errorf("openerScript = %q, expected %q", script, expected)
+	}
+}
+
+func TestOpenTargets(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("package x\n\nfunc f() {}\n"), 0644)
+	}
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.height = 20
+
+	model.selected = 2
+	if got := model.openTargets(); len(got) != 1 || filepath.Base(got[0]) != "b.go" {
+		t.Errorf("Expected the selection, got %v", got)
+	}
+
+	// Marked files are passed together, in path order
+	model.marked[filepath.Join(tmpDir, "c.go")] = true
+	model.marked[filepath.Join(tmpDir, "a.go")] = true
+	if got := model.openTargets(); len(got) != 2 || filepath.Base(got[0]) != "a.go" || filepath.Base(got[1]) != "c.go" {
+		t.Errorf("Expected marked files, got %v", got)
+	}
+
+	// Preview edits the previewed file at the top line
+	model.marked = make(map[string]bool)
+	model.selected = 1
+	model.openPreview()
+	model.previewScroll = 2
+	if got := model.openTargets(); len(got) != 1 || filepath.Base(got[0]) != "a.go" {
+		t.Errorf("Expected the previewed file, got %v", got)
+	}
+	if line := model.previewEditLine(); line != 3 {
+		t.Errorf("previewEditLine = %d, expected 3", line)
+	}
+
+	if cmd := model.editSelection(); cmd == nil || !model.execMode {
+		t.Error("Expected an exec command in exec mode")
+	}
+}
+
+func TestOpenerDone_Refreshes(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "edit.txt")
+	os.WriteFile(path, []byte("before\n"), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.height = 20
+	model.selected = 1
+	model.openPreview()
+	model.execMode = true
+
+	// The "editor" changed the file and created another one
+	os.WriteFile(path, []byte("after\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "new.txt"), nil, 0644)
+
+	var next tea.Model
+	next, _ = model.Update(openerDoneMsg{label: "done"})
+	m := next.(Model)
+	if m.execMode {
+		t.Error("Expected exec mode cleared")
+	}
+	if m.tree.Len() != 3 {
+		t.Errorf("Expected refreshed tree with 3 nodes, got %d", m.tree.Len())
+	}
+	if m.previewContent[0] != "after" {
+		t.Errorf("Expected reloaded preview, got %q", m.previewContent[0])
+	}
+
+	next, _ = m.Update(openerDoneMsg{err: errors.New("exit status 1")})
+	if msg := next.(Model).message; !strings.HasPrefix(msg, "Error:") {
+		t.Errorf("Expected error message, got %q", msg)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// ownedByCurrentUser can't be checked here; the mode check has to do
+func ownedByCurrentUser(info os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// previewMatch represents a search match in preview content.
+// For text previews, Start/End are byte offsets within previewContent[Line].
+// For hex previews, Start/End are absolute byte offsets into previewBytes.
+type previewMatch struct {
+	Line  int // Line index in previewContent (0-based)
+	Start int
+	End   int
+}
+
+// textSpan is a styled byte range within a single line
+type textSpan struct {
+	Start int
+	End   int
+	Style lipgloss.Style
+}
+
+// Hex dump layout (must match formatHexLine)
+const (
+	hexBytesPerLine = 16 // Default row width
+	hexColumnStart  = 10 // "%08x  "
+)
+
+// hexASCIIStart returns the column of the ASCII part for a row width
+func hexASCIIStart(width int) int {
+	return hexColumnStart + width*3 - 1 + 2
+}
+
+// Preview search operations
+
+func (m *Model) startPreviewSearch() {
+	if m.previewIsImage {
+		return
+	}
+	m.previewSearchQuery = ""
+	m.previewMatches = nil
+	m.previewMatchIndex = -1
+	m.previewSearchErr = ""
+	m.inputMode = ModePreviewSearch
+}
+
+func (m Model) updatePreviewSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.inputMode = ModePreview
+		if m.previewSearchQuery == "" {
+			m.clearPreviewSearch()
+		} else if m.previewStream != nil && m.previewStream.matchStart < 0 {
+			// Typing only searches near the viewport; confirming searches the whole file
+			m.runStreamSearch(true)
+		}
+		return m, nil
+	case "esc":
+		m.clearPreviewSearch()
+		m.inputMode = ModePreview
+		return m, nil
+	case "ctrl+t":
+		m.previewSearchCase = !m.previewSearchCase
+	case "ctrl+r":
+		m.previewSearchRegex = !m.previewSearchRegex
+	case "backspace":
+		if len(m.previewSearchQuery) > 0 {
+			runes := []rune(m.previewSearchQuery)
+			m.previewSearchQuery = string(runes[:len(runes)-1])
+		}
+	default:
+		key := msg.Key()
+		if key.Text == "" {
+			return m, nil
+		}
+		m.previewSearchQuery += key.Text
+	}
+
+	// Incremental search: recompute on every change
+	m.runPreviewSearch()
+	return m, nil
+}
+
+// runPreviewSearch recomputes matches for the current query and jumps to
+// the first match at or after the current scroll position
+func (m *Model) runPreviewSearch() {
+	m.previewMatches = nil
+	m.previewMatchIndex = -1
+	m.previewSearchErr = ""
+
+	if m.previewStream != nil {
+		m.runStreamSearch(false)
+		return
+	}
+	if m.previewSearchQuery == "" {
+		return
+	}
+
+	var err error
+	if m.previewIsBinary && m.previewBytes != nil {
+		m.previewMatches, err = findHexMatches(m.previewBytes, m.hexRowBytes(), m.previewSearchQuery, m.previewSearchCase, m.previewSearchRegex)
+	} else {
+		m.previewMatches, err = findTextMatches(m.previewContent, m.previewSearchQuery, m.previewSearchCase, m.previewSearchRegex)
+	}
+	if err != nil {
+		m.previewSearchErr = "Invalid regex"
+		return
+	}
+
+	if len(m.previewMatches) == 0 {
+		return
+	}
+
+	m.previewMatchIndex = 0
+	for i, match := range m.previewMatches {
+		if match.Line >= m.previewScroll {
+			m.previewMatchIndex = i
+			break
+		}
+	}
+	m.scrollToPreviewMatch()
+}
+
+// jumpToNextMatch jumps to the next search match in preview
+func (m *Model) jumpToNextMatch() {
+	if m.previewStream != nil {
+		m.findStreamMatch(true)
+		return
+	}
+	if len(m.previewMatches) == 0 {
+		m.message = "No match found"
+		return
+	}
+	m.previewMatchIndex++
+	if m.previewMatchIndex >= len(m.previewMatches) {
+		m.previewMatchIndex = 0 // Wrap around
+	}
+	m.scrollToPreviewMatch()
+}
+
+// jumpToPrevMatch jumps to the previous search match in preview
+func (m *Model) jumpToPrevMatch() {
+	if m.previewStream != nil {
+		m.findStreamMatch(false)
+		return
+	}
+	if len(m.previewMatches) == 0 {
+		m.message = "No match found"
+		return
+	}
+	m.previewMatchIndex--
+	if m.previewMatchIndex < 0 {
+		m.previewMatchIndex = len(m.previewMatches) - 1 // Wrap around
+	}
+	m.scrollToPreviewMatch()
+}
+
+// scrollToPreviewMatch scrolls vertically and horizontally to the current match
+func (m *Model) scrollToPreviewMatch() {
+	match := m.previewMatches[m.previewMatchIndex]
+	m.scrollToPreviewLine(match.Line + 1)
+	m.revealPreviewMatchColumn(match)
+}
+
+// revealPreviewMatchColumn scrolls horizontally so a match is visible
+func (m *Model) revealPreviewMatchColumn(match previewMatch) {
+	// Hex dump lines always fit; only text lines need horizontal adjustment
+	if m.previewIsBinary || match.Line >= len(m.previewContent) {
+		return
+	}
+	line := m.previewContent[match.Line]
+	startCol := displayColumn(line, match.Start)
+	endCol := displayColumn(line, match.End)
+	visibleWidth := m.width - 8
+	if visibleWidth < 1 {
+		visibleWidth = 1
+	}
+	if startCol < m.previewHScroll || endCol > m.previewHScroll+visibleWidth-1 {
+		m.previewHScroll = startCol - visibleWidth/4
+		if m.previewHScroll < 0 {
+			m.previewHScroll = 0
+		}
+	}
+}
+
+// displayColumn returns the display column of a byte offset (tabs expanded)
+func displayColumn(line string, offset int) int {
+	col := 0
+	for i, r := range line {
+		if i >= offset {
+			break
+		}
+		switch r {
+		case '\t':
+			col += PreviewTabWidth - col%PreviewTabWidth
+		case '\r':
+		default:
+			col += ansi.StringWidth(string(r))
+		}
+	}
+	return col
+}
+
+// hasPreviewSearch returns true if a preview search query is active
+func (m *Model) hasPreviewSearch() bool {
+	return m.previewSearchQuery != ""
+}
+
+// clearPreviewSearch clears the preview search state (toggles are kept)
+func (m *Model) clearPreviewSearch() {
+	m.previewSearchQuery = ""
+	m.previewMatches = nil
+	m.previewMatchIndex = -1
+	m.previewSearchErr = ""
+}
+
+// compileSearchPattern builds a regexp for the query honoring case/regex toggles
+func compileSearchPattern(query string, caseSensitive, useRegex bool) (*regexp.Regexp, error) {
+	pattern := query
+	if !useRegex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// findTextMatches finds all matches of the query in the given lines
+func findTextMatches(lines []string, query string, caseSensitive, useRegex bool) ([]previewMatch, error) {
+	re, err := compileSearchPattern(query, caseSensitive, useRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []previewMatch
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[0] == loc[1] {
+				continue // Skip empty matches (e.g., "a*")
+			}
+			matches = append(matches, previewMatch{Line: i, Start: loc[0], End: loc[1]})
+			if len(matches) >= MaxPreviewMatches {
+				return matches, nil
+			}
+		}
+	}
+	return matches, nil
+}
+
+// findHexMatches finds matches in raw binary content laid out in rows of rowBytes.
+// A query that parses as hex bytes (e.g., "de ad be ef" or "0xcafe") is searched
+// as a byte pattern; anything else is searched as text.
+func findHexMatches(data []byte, rowBytes int, query string, caseSensitive, useRegex bool) ([]previewMatch, error) {
+	var locs [][]int
+
+	if pattern, ok := parseHexPattern(query); ok && !useRegex {
+		for offset := 0; offset < len(data); {
+			idx := bytes.Index(data[offset:], pattern)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			locs = append(locs, []int{start, start + len(pattern)})
+			if len(locs) >= MaxPreviewMatches {
+				break
+			}
+			offset = start + 1
+		}
+	} else {
+		re, err := compileSearchPattern(query, caseSensitive, useRegex)
+		if err != nil {
+			return nil, err
+		}
+		locs = re.FindAllIndex(data, MaxPreviewMatches)
+	}
+
+	var matches []previewMatch
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue
+		}
+		matches = append(matches, previewMatch{
+			Line:  loc[0] / rowBytes,
+			Start: loc[0],
+			End:   loc[1],
+		})
+	}
+	return matches, nil
+}
+
+// parseHexPattern parses an explicit hex byte pattern: "0xdeadbeef" or
+// space-separated bytes like "de ad be ef". Bare "cafe" stays a text search.
+func parseHexPattern(query string) ([]byte, bool) {
+	s := strings.TrimSpace(query)
+	if rest, ok := strings.CutPrefix(s, "0x"); ok {
+		s = rest
+	} else if rest, ok := strings.CutPrefix(s, "0X"); ok {
+		s = rest
+	} else {
+		fields := strings.Fields(s)
+		if len(fields) < 2 {
+			return nil, false
+		}
+		for _, f := range fields {
+			if len(f) != 2 {
+				return nil, false
+			}
+		}
+		s = strings.Join(fields, "")
+	}
+	if s == "" || len(s)%2 != 0 {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// previewMatchSpans returns highlight spans for search matches on a preview line
+func (m Model) previewMatchSpans(lineIdx int) []textSpan {
+	if len(m.previewMatches) == 0 {
+		return nil
+	}
+
+	// Matches are sorted, so start at the first one that can reach this line
+	binary := m.previewIsBinary && m.previewBytes != nil
+	width := m.hexRowBytes()
+	first := sort.Search(len(m.previewMatches), func(i int) bool {
+		if binary {
+			return m.previewMatches[i].End > lineIdx*width
+		}
+		return m.previewMatches[i].Line >= lineIdx
+	})
+
+	var spans []textSpan
+	for i := first; i < len(m.previewMatches); i++ {
+		match := m.previewMatches[i]
+		style := previewMatchStyle
+		if i == m.previewMatchIndex {
+			style = previewCurrentMatchStyle
+		}
+
+		if binary {
+			if match.Start >= (lineIdx+1)*width {
+				break
+			}
+			spans = append(spans, m.hexByteSpans(lineIdx, match.Start, match.End, style)...)
+			continue
+		}
+
+		if match.Line != lineIdx {
+			break
+		}
+		spans = append(spans, textSpan{Start: match.Start, End: match.End, Style: style})
+	}
+	return spans
+}
+
+// hexByteSpans returns spans covering the byte range [start, end) of previewBytes
+// on a hex dump line, in both the hex and the ASCII column
+func (m Model) hexByteSpans(lineIdx, start, end int, style lipgloss.Style) []textSpan {
+	width := m.hexRowBytes()
+	lineStart := lineIdx * width
+	lineEnd := lineStart + width
+	if end <= lineStart || start >= lineEnd {
+		return nil
+	}
+
+	// Offsets beyond 32 bits widen the offset column
+	shift := 0
+	if lineIdx < len(m.previewContent) {
+		shift = max(strings.IndexByte(m.previewContent[lineIdx], ' ')-8, 0)
+	}
+	from := max(start, lineStart) - lineStart
+	to := min(end, lineEnd) - lineStart
+	return []textSpan{
+		{Start: shift + hexColumnStart + from*3, End: shift + hexColumnStart + to*3 - 1, Style: style},
+		{Start: shift + hexASCIIStart(width) + from, End: shift + hexASCIIStart(width) + to, Style: style},
+	}
+}
+
+// previewSearchStatus returns the match counter for the preview status line
+func (m Model) previewSearchStatus() string {
+	if m.previewSearchErr != "" {
+		return fmt.Sprintf(" [%s]", m.previewSearchErr)
+	}
+	if !m.hasPreviewSearch() {
+		return ""
+	}
+	if m.previewStream != nil {
+		return m.streamSearchStatus()
+	}
+	if len(m.previewMatches) == 0 {
+		return " [no matches]"
+	}
+	if m.previewMatchIndex >= 0 {
+		return fmt.Sprintf(" [%d/%d matches]", m.previewMatchIndex+1, len(m.previewMatches))
+	}
+	return fmt.Sprintf(" [%d matches]", len(m.previewMatches))
+}
+
+// previewSearchFlags returns indicators for the case/regex toggles
+func (m Model) previewSearchFlags() string {
+	caseFlag := "aa"
+	if m.previewSearchCase {
+		caseFlag = "Aa"
+	}
+	regexFlag := "abc"
+	if m.previewSearchRegex {
+		regexFlag = ".*"
+	}
+	return fmt.Sprintf("[%s][%s]", caseFlag, regexFlag)
+}
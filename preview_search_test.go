@@ -0,0 +1,361 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestFindTextMatches(t *testing.T) {
+	lines := []string{"Hello world", "hello again", "nothing here", "HELLO hello"}
+
+	tests := []struct {
+		name          string
+		query         string
+		caseSensitive bool
+		useRegex      bool
+		expected      int
+	}{
+		{"case insensitive", "hello", false, false, 4},
+		{"case sensitive", "hello", true, false, 2},
+		{"regex", "h.llo", false, true, 4},
+		{"regex anchored", "^hello", true, true, 1},
+		{"literal dot without regex", "h.llo", false, false, 0},
+		{"empty matches skipped", "x*", false, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := findTextMatches(lines, tt.query, tt.caseSensitive, tt.useRegex)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(matches) != tt.expected {
+				t.Errorf("findTextMatches(%q) = %d matches, expected %d", tt.query, len(matches), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindTextMatches_InvalidRegex(t *testing.T) {
+	_, err := findTextMatches([]string{"abc"}, "(", false, true)
+	if err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestFindTextMatches_Offsets(t *testing.T) {
+	matches, _ := findTextMatches([]string{"abc foo foo"}, "foo", false, false)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Start != 4 || matches[0].End != 7 {
+		t.Errorf("Unexpected first match: %+v", matches[0])
+	}
+	if matches[1].Start != 8 || matches[1].End != 11 {
+		t.Errorf("Unexpected second match: %+v", matches[1])
+	}
+}
+
+func TestParseHexPattern(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []byte
+		ok       bool
+	}{
+		{"de ad be ef", []byte{0xde, 0xad, 0xbe, 0xef}, true},
+		{"0xCAFE", []byte{0xca, 0xfe}, true},
+		{"0xdeadbeef", []byte{0xde, 0xad, 0xbe, 0xef}, true},
+		{"deadbeef", nil, false},
+		{"cafe", nil, false},
+		{"ab", nil, false},
+		{"de adbe", nil, false},
+		{"0xabc", nil, false},
+		{"abc", nil, false},
+		{"hello", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, ok := parseHexPattern(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("parseHexPattern(%q) ok = %v, expected %v", tt.input, ok, tt.ok)
+			}
+			if string(result) != string(tt.expected) {
+				t.Errorf("parseHexPattern(%q) = %x, expected %x", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindHexMatches_BytePattern(t *testing.T) {
+	data := make([]byte, 40)
+	// Pattern spans the boundary between line 0 and line 1
+	data[15] = 0xde
+	data[16] = 0xad
+	data[30] = 0xde
+	data[31] = 0xad
+
+	matches, err := findHexMatches(data, hexBytesPerLine, "de ad", false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Line != 0 || matches[0].Start != 15 || matches[0].End != 17 {
+		t.Errorf("Unexpected first match: %+v", matches[0])
+	}
+	if matches[1].Line != 1 {
+		t.Errorf("Expected second match on line 1, got %d", matches[1].Line)
+	}
+
+	// Lines follow the active row width
+	matches, _ = findHexMatches(data, 8, "de ad", false, false)
+	if len(matches) != 2 || matches[0].Line != 1 || matches[1].Line != 3 {
+		t.Errorf("Expected matches on 8-byte lines 1 and 3, got %+v", matches)
+	}
+}
+
+func TestFindHexMatches_TextFallback(t *testing.T) {
+	data := append([]byte{0x00, 0x01}, []byte("MAGIC cafe")...)
+
+	matches, err := findHexMatches(data, hexBytesPerLine, "magic", false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Start != 2 {
+		t.Errorf("Expected one text match at offset 2, got %+v", matches)
+	}
+
+	// Hex-looking text without 0x or spaces is still text
+	matches, err = findHexMatches(data, hexBytesPerLine, "cafe", false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Start != 8 {
+		t.Errorf("Expected one text match at offset 8, got %+v", matches)
+	}
+}
+
+func TestPreviewSearch_IncrementalAndNavigation(t *testing.T) {
+	tmpDir := t.TempDir()
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.inputMode = ModePreview
+	model.height = 10
+	model.previewContent = make([]string, 100)
+	for i := range model.previewContent {
+		model.previewContent[i] = "line"
+	}
+	model.previewContent[10] = "needle"
+	model.previewContent[60] = "another NEEDLE"
+
+	// '/' enters search input
+	newModel, _ := model.Update(keyMsg("/"))
+	m := newModel.(Model)
+	if m.inputMode != ModePreviewSearch {
+		t.Fatalf("Expected ModePreviewSearch, got %v", m.inputMode)
+	}
+
+	// Typing searches incrementally
+	for _, ch := range "needle" {
+		newModel, _ = m.Update(keyMsg(string(ch)))
+		m = newModel.(Model)
+	}
+	if len(m.previewMatches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(m.previewMatches))
+	}
+	if m.previewMatchIndex != 0 {
+		t.Errorf("Expected first match selected, got %d", m.previewMatchIndex)
+	}
+
+	// Enter confirms and returns to preview
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	m = newModel.(Model)
+	if m.inputMode != ModePreview {
+		t.Fatalf("Expected ModePreview after Enter, got %v", m.inputMode)
+	}
+
+	// n moves to next match and scrolls to it
+	newModel, _ = m.Update(keyMsg("n"))
+	m = newModel.(Model)
+	if m.previewMatchIndex != 1 {
+		t.Errorf("Expected match index 1 after n, got %d", m.previewMatchIndex)
+	}
+	if m.previewScroll == 0 {
+		t.Error("Expected preview to scroll to second match")
+	}
+
+	// N wraps back
+	newModel, _ = m.Update(keyMsg("N"))
+	m = newModel.(Model)
+	if m.previewMatchIndex != 0 {
+		t.Errorf("Expected match index 0 after N, got %d", m.previewMatchIndex)
+	}
+
+	// Esc clears the search but stays in preview
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEscape))
+	m = newModel.(Model)
+	if m.inputMode != ModePreview {
+		t.Errorf("Expected to stay in preview after clearing search, got %v", m.inputMode)
+	}
+	if m.hasPreviewSearch() || m.previewMatches != nil {
+		t.Error("Expected search to be cleared")
+	}
+
+	// Second Esc closes the preview
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEscape))
+	m = newModel.(Model)
+	if m.inputMode != ModeNormal {
+		t.Errorf("Expected ModeNormal after second Esc, got %v", m.inputMode)
+	}
+}
+
+func TestPreviewSearch_Toggles(t *testing.T) {
+	tmpDir := t.TempDir()
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.inputMode = ModePreviewSearch
+	model.previewContent = []string{"Foo", "foo"}
+	model.previewSearchQuery = "foo"
+	model.runPreviewSearch()
+	if len(model.previewMatches) != 2 {
+		t.Fatalf("Expected 2 case-insensitive matches, got %d", len(model.previewMatches))
+	}
+
+	// Ctrl+T toggles case sensitivity
+	newModel, _ := model.Update(tea.KeyPressMsg{Code: 't', Mod: tea.ModCtrl})
+	m := newModel.(Model)
+	if !m.previewSearchCase {
+		t.Fatal("Expected case-sensitive search after ctrl+t")
+	}
+	if len(m.previewMatches) != 1 {
+		t.Errorf("Expected 1 case-sensitive match, got %d", len(m.previewMatches))
+	}
+
+	// Ctrl+R toggles regex; invalid pattern reports an error
+	m.previewSearchQuery = "f(o"
+	newModel, _ = m.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+	m = newModel.(Model)
+	if !m.previewSearchRegex {
+		t.Fatal("Expected regex search after ctrl+r")
+	}
+	if m.previewSearchErr == "" {
+		t.Error("Expected invalid regex error")
+	}
+	if !strings.Contains(m.previewSearchStatus(), "Invalid regex") {
+		t.Errorf("Expected status to show regex error, got %q", m.previewSearchStatus())
+	}
+}
+
+func TestPreviewSearch_HexPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	binFile := filepath.Join(tmpDir, "data.bin")
+	data := []byte{0x00, 0x01, 0x02, 0xca, 0xfe, 0xba, 0xbe, 0x00}
+	os.WriteFile(binFile, data, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.selected = 1
+	model.openPreview()
+	if !model.previewIsBinary {
+		t.Fatal("Expected binary preview")
+	}
+
+	model.previewSearchQuery = "0xcafebabe"
+	model.runPreviewSearch()
+	if len(model.previewMatches) != 1 {
+		t.Fatalf("Expected 1 hex match, got %d", len(model.previewMatches))
+	}
+	if model.previewMatches[0].Start != 3 {
+		t.Errorf("Expected match at offset 3, got %d", model.previewMatches[0].Start)
+	}
+
+	// Highlight covers the hex column and the ASCII column
+	spans := model.previewMatchSpans(0)
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	line := model.previewContent[0]
+	if got := line[spans[0].Start:spans[0].End]; got != "ca fe ba be" {
+		t.Errorf("Hex span = %q, expected %q", got, "ca fe ba be")
+	}
+}
+
+func TestPreviewMatchSpans_PerLine(t *testing.T) {
+	model := Model{
+		previewContent:    []string{"ab ab", "none", "ab"},
+		previewMatches:    []previewMatch{{Line: 0, Start: 0, End: 2}, {Line: 0, Start: 3, End: 5}, {Line: 2, Start: 0, End: 2}},
+		previewMatchIndex: 2,
+	}
+
+	tests := []struct {
+		line     int
+		expected int
+	}{
+		{0, 2},
+		{1, 0},
+		{2, 1},
+		{3, 0},
+	}
+	for _, tt := range tests {
+		if spans := model.previewMatchSpans(tt.line); len(spans) != tt.expected {
+			t.Errorf("previewMatchSpans(%d) returned %d spans, expected %d", tt.line, len(spans), tt.expected)
+		}
+	}
+}
+
+func TestPreviewSearch_ScrollsHorizontallyToMatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.inputMode = ModePreview
+	model.width = 40
+	model.previewContent = []string{strings.Repeat("x", 200) + "needle"}
+	model.previewSearchQuery = "needle"
+	model.runPreviewSearch()
+
+	if model.previewHScroll == 0 {
+		t.Error("Expected horizontal scroll to reveal the match")
+	}
+	if model.previewHScroll > 200 {
+		t.Errorf("Horizontal scroll overshoots the match: %d", model.previewHScroll)
+	}
+}
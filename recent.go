@@ -0,0 +1,145 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Recently changed files: watcher changes are remembered so the tree can badge
+// fresh edits (an agent at work) and list them newest first
+
+// recentChange is the last change the watcher reported for a path
+type recentChange struct {
+	Path string
+	Op   FileChangeOp
+	Time time.Time
+}
+
+// recentChanges holds one entry per path, oldest first
+type recentChanges []recentChange
+
+// recentFade is the badge color from fresh to almost faded
+var recentFade = []string{"214", "208", "172", "136", "101", "59"}
+
+// record adds a batch of changes seen at now, moving repeated paths to the end
+func (r recentChanges) record(changes []FileChange, now time.Time) recentChanges {
+	for _, change := range changes {
+		for i, c := range r {
+			if c.Path == change.Path {
+				r = append(r[:i:i], r[i+1:]...)
+				break
+			}
+		}
+		r = append(r, recentChange{Path: change.Path, Op: change.Op, Time: now})
+	}
+	if len(r) > MaxRecentChanges {
+		r = append(recentChanges(nil), r[len(r)-MaxRecentChanges:]...)
+	}
+	return r
+}
+
+// lookup returns the change recorded for path
+func (r recentChanges) lookup(path string) (recentChange, bool) {
+	for i := len(r) - 1; i >= 0; i-- {
+		if r[i].Path == path {
+			return r[i], true
+		}
+	}
+	return recentChange{}, false
+}
+
+// latestWithin returns the newest change to a path under dir
+func (r recentChanges) latestWithin(dir string) (recentChange, bool) {
+	for i := len(r) - 1; i >= 0; i-- {
+		if r[i].Op != FileRemoved && strings.HasPrefix(r[i].Path, dir+string(filepath.Separator)) {
+			return r[i], true
+		}
+	}
+	return recentChange{}, false
+}
+
+// under returns the changes inside root, newest first
+func (r recentChanges) under(root string) []recentChange {
+	var list []recentChange
+	for i := len(r) - 1; i >= 0; i-- {
+		if isWithin(root, r[i].Path) {
+			list = append(list, r[i])
+		}
+	}
+	return list
+}
+
+// recordRecent remembers the changes to paths the tree lists. VCS metadata
+// (.git/index after git add) and hidden files while hidden are skipped.
+func (m *Model) recordRecent(changes []FileChange, now time.Time) {
+	root := m.tree.Root.Path
+	var listed []FileChange
+	for _, change := range changes {
+		if !isWithin(root, change.Path) || isVCSMetadata(root, change.Path) {
+			continue
+		}
+		if !m.showHidden && isHiddenPath(root, change.Path) {
+			continue
+		}
+		listed = append(listed, change)
+	}
+	m.recent = m.recent.record(listed, now)
+}
+
+// isVCSMetadata reports whether path is inside a .git or .jj directory below root
+func isVCSMetadata(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if name == ".git" || name == ".jj" {
+			return true
+		}
+	}
+	return false
+}
+
+// recentPeriod is how long a change keeps its badge ([watch] recent_seconds)
+func (m Model) recentPeriod() time.Duration {
+	switch seconds := m.config.Watch.RecentSeconds; {
+	case seconds < 0:
+		return 0
+	case seconds == 0:
+		return DefaultRecentSeconds * time.Second
+	default:
+		return time.Duration(seconds) * time.Second
+	}
+}
+
+// recentBadge returns the fading "●" for a node changed within the period,
+// or "" if it has none. Collapsed directories show their newest change inside.
+func (m Model) recentBadge(node *FileNode, now time.Time) string {
+	period := m.recentPeriod()
+	if period == 0 || node.IsGhost {
+		return ""
+	}
+	change, ok := m.recent.lookup(node.Path)
+	if !ok || change.Op == FileRemoved {
+		if !node.IsDir || node.Expanded {
+			return ""
+		}
+		if change, ok = m.recent.latestWithin(node.Path); !ok {
+			return ""
+		}
+	}
+
+	age := now.Sub(change.Time)
+	if age < 0 || age >= period {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(recentColor(age, period))).Render(" ●")
+}
+
+// recentColor picks the badge color for a change age into the period
+func recentColor(age, period time.Duration) string {
+	return recentFade[int(age*time.Duration(len(recentFade))/period)]
+}
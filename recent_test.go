@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestRecentChanges(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	var r recentChanges
+	r = r.record([]FileChange{{"/root/a.go", FileCreated}, {"/root/pkg/b.go", FileModified}}, t0)
+	r = r.record([]FileChange{{"/root/a.go", FileModified}, {"/elsewhere/c.go", FileModified}}, t0.Add(time.Second))
+
+	// Repeated paths move to the end with their latest change
+	if len(r) != 3 || r[2].Path != "/elsewhere/c.go" || r[1].Path != "/root/a.go" || r[1].Op != FileModified {
+		t.Errorf("Unexpected entries %+v", r)
+	}
+	if c, ok := r.lookup("/root/a.go"); !ok || !c.Time.Equal(t0.Add(time.Second)) {
+		t.Errorf("lookup = %+v, %v", c, ok)
+	}
+	if c, ok := r.latestWithin("/root/pkg"); !ok || c.Path != "/root/pkg/b.go" {
+		t.Errorf("latestWithin = %+v, %v", c, ok)
+	}
+	if _, ok := r.latestWithin("/root/pk"); ok {
+		t.Error("Expected no change within a name prefix")
+	}
+
+	under := r.under("/root")
+	if len(under) != 2 || under[0].Path != "/root/a.go" || under[1].Path != "/root/pkg/b.go" {
+		t.Errorf("Expected changes under /root newest first, got %+v", under)
+	}
+
+	// Only the newest entries are kept
+	for i := 0; i < MaxRecentChanges+10; i++ {
+		r = r.record([]FileChange{{filepath.Join("/many", string(rune('a'+i%26)), string(rune('a'+i/26))), FileModified}}, t0)
+	}
+	if len(r) != MaxRecentChanges {
+		t.Errorf("Expected %d entries, got %d", MaxRecentChanges, len(r))
+	}
+}
+
+func TestRecentBadge(t *testing.T) {
+	tmpDir := t.TempDir()
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	now := time.Now()
+	file := &FileNode{Path: "/r/pkg/a.go", Name: "a.go"}
+	dir := &FileNode{Path: "/r/pkg", Name: "pkg", IsDir: true}
+	model.recent = model.recent.record([]FileChange{{file.Path, FileModified}}, now.Add(-time.Second))
+
+	fresh := model.recentBadge(file, now)
+	if !strings.Contains(fresh, "●") {
+		t.Errorf("Expected a badge for a fresh change, got %q", fresh)
+	}
+	period := model.recentPeriod()
+	if first, last := recentColor(0, period), recentColor(period-time.Second, period); first != recentFade[0] || last != recentFade[len(recentFade)-1] {
+		t.Errorf("Expected the badge to fade from %s to %s, got %s to %s", recentFade[0], recentFade[len(recentFade)-1], first, last)
+	}
+	if badge := model.recentBadge(file, now.Add(DefaultRecentSeconds*time.Second)); badge != "" {
+		t.Errorf("Expected no badge after the period, got %q", badge)
+	}
+
+	// Collapsed directories show changes inside them
+	if badge := model.recentBadge(dir, now); !strings.Contains(badge, "●") {
+		t.Error("Expected a badge on the collapsed parent")
+	}
+	dir.Expanded = true
+	if badge := model.recentBadge(dir, now); badge != "" {
+		t.Errorf("Expected no badge on an expanded parent, got %q", badge)
+	}
+
+	model.config.Watch.RecentSeconds = -1
+	if badge := model.recentBadge(file, now); badge != "" {
+		t.Errorf("Expected badges disabled, got %q", badge)
+	}
+}
+
+func TestRecentJumpAndList(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "pkg"), 0755)
+	older := filepath.Join(tmpDir, "old.go")
+	newer := filepath.Join(tmpDir, "pkg", "new.go")
+	os.WriteFile(older, nil, 0644)
+	os.WriteFile(newer, nil, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if model.watcher != nil {
+		model.watcher.Close()
+		model.watcher = nil
+	}
+	model.watcherEnabled = true
+
+	var m tea.Model = model
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("r"))
+	if msg := m.(Model).message; msg != "No recent changes" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	m, _ = m.Update(FileChangeMsg{Changes: []FileChange{{Path: older, Op: FileModified}}})
+	m, _ = m.Update(FileChangeMsg{Changes: []FileChange{{Path: newer, Op: FileCreated}}})
+
+	// VCS metadata and hidden files are not recorded
+	os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".env"), nil, 0644)
+	m, _ = m.Update(FileChangeMsg{Changes: []FileChange{
+		{Path: filepath.Join(tmpDir, ".git", "index"), Op: FileModified},
+		{Path: filepath.Join(tmpDir, ".env"), Op: FileModified},
+	}})
+	if got := len(m.(Model).recent); got != 2 {
+		t.Errorf("Expected 2 recorded changes, got %d", got)
+	}
+
+	// gr reveals the newest change, even inside a collapsed directory
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("r"))
+	if node := m.(Model).tree.GetNode(m.(Model).selected); node == nil || node.Path != newer {
+		t.Errorf("Expected %s selected, got %v", newer, node)
+	}
+	if m.(Model).showHidden {
+		t.Error("Expected gr to leave hidden files off")
+	}
+
+	// gR lists both, newest first; Enter reveals the picked one
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("R"))
+	if m.(Model).inputMode != ModeRecent || len(m.(Model).recentList) != 2 {
+		t.Fatalf("Expected the recent changes panel, got mode %v with %d entries", m.(Model).inputMode, len(m.(Model).recentList))
+	}
+	if view := m.(Model).renderTreeView(); !strings.Contains(view, "Recent changes") || !strings.Contains(view, "+ "+filepath.Join("pkg", "new.go")) {
+		t.Error("Expected the panel to list pkg/new.go as created")
+	}
+	m, _ = m.Update(keyMsg("j"))
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	if m.(Model).inputMode != ModeNormal {
+		t.Error("Expected the panel closed")
+	}
+	if node := m.(Model).tree.GetNode(m.(Model).selected); node == nil || node.Path != older {
+		t.Errorf("Expected %s selected, got %v", older, node)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Per-root session state: what was expanded, selected and marked the last
+// time a root was open, restored on the next launch.
+
+// sessionState is saved as JSON, one file per root
+type sessionState struct {
+	Root       string   `json:"root"`
+	Expanded   []string `json:"expanded"`
+	Selected   string   `json:"selected"`
+	Scroll     int      `json:"scroll"`
+	ShowHidden bool     `json:"show_hidden"`
+	VCSType    string   `json:"vcs_type"` // "auto", "git" or "jj"
+	Marked     []string `json:"marked"`
+}
+
+// stateDir returns $XDG_STATE_HOME/bon3 (default ~/.local/state/bon3)
+func stateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "bon3")
+}
+
+// sessionPath returns the state file for root, named after a hash of its path
+func sessionPath(root string) string {
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// loadSession reads the saved state for root; nil when there is none
+func loadSession(root string) (*sessionState, error) {
+	path := sessionPath(root)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var state sessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Root != root {
+		// Hash collision or a hand-edited file
+		return nil, nil
+	}
+	state.prune()
+	return &state, nil
+}
+
+// saveSession writes the state atomically
+func saveSession(state *sessionState) error {
+	path := sessionPath(state.Root)
+	if path == "" {
+		return errors.New("no state directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// prune drops paths that no longer exist
+func (s *sessionState) prune() {
+	exists := func(path string) bool {
+		_, err := os.Lstat(path)
+		return err == nil
+	}
+	keep := func(paths []string) []string {
+		kept := paths[:0]
+		for _, path := range paths {
+			if isWithin(s.Root, path) && exists(path) {
+				kept = append(kept, path)
+			}
+		}
+		return kept
+	}
+
+	s.Expanded = keep(s.Expanded)
+	s.Marked = keep(s.Marked)
+	if s.Selected != "" && !exists(s.Selected) {
+		s.Selected = ""
+	}
+}
+
+// expandedDirs lists the expanded directories that are visible in the tree,
+// parents before children
+func expandedDirs(tree *FileTree) []string {
+	var dirs []string
+	for _, node := range tree.Nodes {
+		if node != tree.Root && node.IsDir && node.Expanded {
+			dirs = append(dirs, node.Path)
+		}
+	}
+	return dirs
+}
+
+// vcsTypeName and parseVCSType convert VCSType for the state file
+func vcsTypeName(t VCSType) string {
+	return strings.ToLower(t.String())
+}
+
+func parseVCSType(name string) VCSType {
+	switch name {
+	case "git":
+		return VCSTypeGit
+	case "jj":
+		return VCSTypeJJ
+	}
+	return VCSTypeAuto
+}
+
+// sortedPaths returns the keys of a path set in order
+func sortedPaths(set map[string]bool) []string {
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSessionSaveLoad(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a", "b"), 0755)
+	os.WriteFile(filepath.Join(root, "a", "x.txt"), nil, 0644)
+
+	state := &sessionState{
+		Root:     root,
+		Expanded: []string{filepath.Join(root, "a"), filepath.Join(root, "gone")},
+		Selected: filepath.Join(root, "gone.txt"),
+		Scroll:   3,
+		VCSType:  "git",
+		Marked:   []string{filepath.Join(root, "a", "x.txt"), filepath.Join(root, "gone.txt"), "/elsewhere"},
+	}
+	if err := saveSession(state); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(sessionPath(root)) != filepath.Join(os.Getenv("XDG_STATE_HOME"), "bon3") {
+		t.Errorf("Unexpected state file %s", sessionPath(root))
+	}
+
+	loaded, err := loadSession(root)
+	if err != nil || loaded == nil {
+		t.Fatalf("loadSession = %v, %v", loaded, err)
+	}
+	// Missing and foreign paths are pruned
+	if !reflect.DeepEqual(loaded.Expanded, []string{filepath.Join(root, "a")}) {
+		t.Errorf("Expanded = %v", loaded.Expanded)
+	}
+	if !reflect.DeepEqual(loaded.Marked, []string{filepath.Join(root, "a", "x.txt")}) {
+		t.Errorf("Marked = %v", loaded.Marked)
+	}
+	if loaded.Selected != "" || loaded.Scroll != 3 || parseVCSType(loaded.VCSType) != VCSTypeGit {
+		t.Errorf("Unexpected state %+v", loaded)
+	}
+
+	if got, err := loadSession(filepath.Join(root, "a")); got != nil || err != nil {
+		t.Errorf("Expected no state for another root, got %v, %v", got, err)
+	}
+
+	os.WriteFile(sessionPath(root), []byte("{not json"), 0600)
+	if _, err := loadSession(root); err == nil {
+		t.Error("Expected an error for a corrupt state file")
+	}
+}
+
+func TestSessionRestore(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "a", "b"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "other"), 0755)
+	target := filepath.Join(tmpDir, "a", "b", "deep.txt")
+	os.WriteFile(target, nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".env"), nil, 0644)
+
+	newSessionModel := func(t *testing.T, root string) Model {
+		model, err := NewModel(root)
+		if err != nil {
+			t.Fatalf("Failed to create model: %v", err)
+		}
+		t.Cleanup(func() {
+			if model.watcher != nil {
+				model.watcher.Close()
+			}
+		})
+		model.height = 20
+		model.sessionEnabled = true
+		return model
+	}
+
+	model := newSessionModel(t, tmpDir)
+	if _, err := model.revealPath(target); err != nil {
+		t.Fatal(err)
+	}
+	model.showHidden = true
+	model.tree.SetShowHidden(true)
+	model.tree.Reveal(target)
+	model.selected = model.tree.IndexOf(target)
+	model.vcsForceType = VCSTypeGit
+	model.marked[filepath.Join(tmpDir, ".env")] = true
+	if err := model.saveSession(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newSessionModel(t, tmpDir)
+	restored.restoreSession()
+	if node := restored.tree.GetNode(restored.selected); node == nil || node.Path != target {
+		t.Errorf("Expected %s selected after restore, got %v", target, node)
+	}
+	if !restored.showHidden || restored.vcsForceType != VCSTypeGit || !restored.marked[filepath.Join(tmpDir, ".env")] {
+		t.Errorf("Expected settings and marks restored, got hidden=%v vcs=%v marked=%v", restored.showHidden, restored.vcsForceType, restored.marked)
+	}
+
+	// Leaving a root saves it; coming back restores it
+	restored.changeRoot(filepath.Join(tmpDir, "other"))
+	restored.changeRoot(tmpDir)
+	if node := restored.tree.GetNode(restored.selected); node == nil || node.Path != target {
+		t.Errorf("Expected %s selected after returning to the root, got %v", target, node)
+	}
+
+	// Deleted directories are skipped
+	os.RemoveAll(filepath.Join(tmpDir, "a"))
+	pruned := newSessionModel(t, tmpDir)
+	pruned.restoreSession()
+	if pruned.selected != 0 || pruned.tree.Len() != 3 { // root, other/, .env
+		t.Errorf("Expected a fresh tree after pruning, got %d nodes, selected %d", pruned.tree.Len(), pruned.selected)
+	}
+}
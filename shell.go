@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Shell commands from the ! prompt.
+// Placeholders are replaced with shell-quoted paths before the command runs
+// through sh -c, so paths with spaces or quotes are safe.
+
+// shellContext holds the paths placeholders expand to
+type shellContext struct {
+	Selected string   // %f: selected file or directory
+	Marked   []string // %F: marked paths (the selection when nothing is marked)
+	Dir      string   // %d: selected directory, or the directory of the selected file
+	Root     string   // %r: repository root (the tree root outside a repository)
+	WorkDir  string   // Directory the command runs in
+}
+
+// expandShellCommand replaces %f, %F, %d, %r and %% in command.
+// Paths under the working directory are made relative ("./pkg"),
+// so `go test %d/...` runs as `go test './pkg'/...`.
+func expandShellCommand(command string, ctx shellContext) string {
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		if command[i] != '%' || i+1 >= len(command) {
+			b.WriteByte(command[i])
+			continue
+		}
+		switch command[i+1] {
+		case 'f':
+			b.WriteString(shellQuote(relToWorkDir(ctx.Selected, ctx.WorkDir)))
+		case 'F':
+			paths := make([]string, len(ctx.Marked))
+			for j, p := range ctx.Marked {
+				paths[j] = relToWorkDir(p, ctx.WorkDir)
+			}
+			b.WriteString(shellQuoteAll(paths))
+		case 'd':
+			b.WriteString(shellQuote(relToWorkDir(ctx.Dir, ctx.WorkDir)))
+		case 'r':
+			b.WriteString(shellQuote(ctx.Root))
+		case '%':
+			b.WriteByte('%')
+		default:
+			// Unknown placeholder: keep as typed (e.g., date +%Y)
+			b.WriteString(command[i : i+2])
+		}
+		i++
+	}
+	return b.String()
+}
+
+// relToWorkDir returns path relative to dir ("./a/b", or "." for dir itself),
+// or path unchanged when it lies outside dir
+func relToWorkDir(path, dir string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	if rel == "." {
+		return rel
+	}
+	return "." + string(filepath.Separator) + rel
+}
+
+// addShellHistory appends command to history, moving a repeated command
+// to the end and dropping the oldest entries beyond MaxShellHistory
+func addShellHistory(history []string, command string) []string {
+	out := make([]string, 0, len(history)+1)
+	for _, h := range history {
+		if h != command {
+			out = append(out, h)
+		}
+	}
+	out = append(out, command)
+	if len(out) > MaxShellHistory {
+		out = out[len(out)-MaxShellHistory:]
+	}
+	return out
+}
+
+// runShellScript runs script with sh -c in dir and returns its combined output
+// and exit status (-1 when it could not run or was killed). Cancelling ctx
+// kills the shell's whole process group, grandchildren included.
+func runShellScript(ctx context.Context, script, dir string) (string, int, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Dir = dir
+	killProcessGroup(cmd)
+	cmd.WaitDelay = ShellWaitDelayMs * time.Millisecond
+	out, err := cmd.CombinedOutput()
+	return string(out), exitStatus(err), startError(err)
+}
+
+// exitStatus extracts the exit status from the error of a finished command
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// startError returns err unless it only reports a non-zero exit status
+func startError(err error) error {
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
+// shellOutputLines splits command output into display lines: escape sequences
+// are removed, carriage-return progress updates collapse to their final text
+// and only the last MaxShellOutputLines lines are kept
+func shellOutputLines(output string) []string {
+	output = strings.TrimSuffix(ansi.Strip(output), "\n")
+	if output == "" {
+		return nil
+	}
+
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = line
+	}
+	if omitted := len(lines) - MaxShellOutputLines; omitted > 0 {
+		lines = append([]string{fmt.Sprintf("… %d lines omitted", omitted)}, lines[omitted:]...)
+	}
+	return lines
+}
+
+// exitStatusText describes how a command ended, e.g. "exit 0" or "killed"
+func exitStatusText(code int) string {
+	if code < 0 {
+		return "killed"
+	}
+	return fmt.Sprintf("exit %d", code)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// killProcessGroup is unsupported here; cancel kills the shell only and
+// WaitDelay stops waiting for its children
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestExpandShellCommand(t *testing.T) {
+	ctx := shellContext{
+		Selected: "/repo/pkg/a.go",
+		Marked:   []string{"/repo/a b.txt", "/elsewhere/c"},
+		Dir:      "/repo/pkg",
+		Root:     "/repo",
+		WorkDir:  "/repo",
+	}
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"go test %d/...", "go test './pkg'/..."},
+		{"wc -l %F", "wc -l './a b.txt' '/elsewhere/c'"},
+		{"cat %f", "cat './pkg/a.go'"},
+		{"cd %r && make", "cd '/repo' && make"},
+		{"date +%Y 100%%", "date +%Y 100%"},
+		{"echo %", "echo %"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := expandShellCommand(tt.command, ctx); got != tt.expected {
+				t.Errorf("expandShellCommand = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	rootCtx := shellContext{Dir: "/repo", WorkDir: "/repo"}
+	if got := expandShellCommand("go test %d/...", rootCtx); got != "go test '.'/..." {
+		t.Errorf("Expected the root as '.', got %q", got)
+	}
+}
+
+func TestAddShellHistory(t *testing.T) {
+	h := addShellHistory(nil, "make")
+	h = addShellHistory(h, "go test ./...")
+	h = addShellHistory(h, "make")
+	if strings.Join(h, ",") != "go test ./...,make" {
+		t.Errorf("Expected repeated command moved to the end, got %v", h)
+	}
+
+	for i := 0; i < MaxShellHistory+5; i++ {
+		h = addShellHistory(h, fmt.Sprintf("cmd %d", i))
+	}
+	if len(h) != MaxShellHistory || h[len(h)-1] != fmt.Sprintf("cmd %d", MaxShellHistory+4) {
+		t.Errorf("Expected history capped at %d, got %d", MaxShellHistory, len(h))
+	}
+}
+
+func TestShellOutputLines(t *testing.T) {
+	lines := shellOutputLines("\x1b[32mok\x1b[0m  pkg\r\n10%\r50%\r100%\ndone\n")
+	expected := []string{"ok  pkg", "100%", "done"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("shellOutputLines = %q, expected %q", lines, expected)
+	}
+	if shellOutputLines("") != nil {
+		t.Error("Expected no lines for empty output")
+	}
+}
+
+func TestRunShellScript(t *testing.T) {
+	dir := t.TempDir()
+	out, code, err := runShellScript(context.Background(), "pwd; echo oops >&2; exit 3", dir)
+	if err != nil || code != 3 {
+		t.Fatalf("runShellScript = %d, %v", code, err)
+	}
+	if !strings.Contains(out, "oops") || !strings.Contains(out, filepath.Base(dir)) {
+		t.Errorf("Expected stdout and stderr, got %q", out)
+	}
+	if exitStatusText(code) != "exit 3" || exitStatusText(-1) != "killed" {
+		t.Error("Unexpected exit status text")
+	}
+}
+
+func TestRunShellScript_CancelKillsChildren(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, code, _ := runShellScript(ctx, "sleep 30 & wait", t.TempDir())
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancel to return promptly, took %v", elapsed)
+	}
+	if code != -1 {
+		t.Errorf("Expected a killed status, got %d", code)
+	}
+}
+
+func TestShellPrompt_RunsAndRefreshes(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Mkdir(filepath.Join(tmpDir, "pkg"), 0755)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.width = 80
+	model.height = 20
+	model.selected = 1 // pkg/
+
+	var m tea.Model = model
+	m, _ = m.Update(keyMsg("!"))
+	if m.(Model).inputMode != ModeShell {
+		t.Fatalf("Expected shell prompt, got %v", m.(Model).inputMode)
+	}
+	for _, r := range "touch %d/new.go; echo made" {
+		m, _ = m.Update(keyMsg(string(r)))
+	}
+	m, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+	if m.(Model).inputMode != ModeShellOutput || !m.(Model).shellRunning || cmd == nil {
+		t.Fatal("Expected the command running in the output pane")
+	}
+	if !strings.Contains(m.(Model).renderShellOutput(), "Running") {
+		t.Error("Expected running status")
+	}
+
+	m, _ = m.Update(cmd())
+	got := m.(Model)
+	if got.shellRunning || got.shellExitCode != 0 || len(got.shellOutput) != 1 || got.shellOutput[0] != "made" {
+		t.Fatalf("Unexpected result: running=%v exit=%d output=%q", got.shellRunning, got.shellExitCode, got.shellOutput)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "pkg", "new.go")); err != nil {
+		t.Errorf("Expected %%d to expand to the selected folder: %v", err)
+	}
+	if !strings.Contains(got.renderShellOutput(), "exit 0") {
+		t.Error("Expected exit status in the status bar")
+	}
+
+	// History recalls the command; down restores the draft
+	m, _ = m.Update(keyMsg("q"))
+	m, _ = m.Update(keyMsg("!"))
+	m, _ = m.Update(keyMsg("x"))
+	m, _ = m.Update(specialKeyMsg(tea.KeyUp))
+	if m.(Model).inputBuffer != "touch %d/new.go; echo made" {
+		t.Errorf("Expected history entry, got %q", m.(Model).inputBuffer)
+	}
+	m, _ = m.Update(specialKeyMsg(tea.KeyDown))
+	if m.(Model).inputBuffer != "x" {
+		t.Errorf("Expected draft restored, got %q", m.(Model).inputBuffer)
+	}
+
+	// Output of a replaced run is dropped
+	stale := m.(Model)
+	stale.shellOutput = nil
+	m, _ = stale.Update(shellDoneMsg{id: stale.shellID - 1, output: "old"})
+	if m.(Model).shellOutput != nil {
+		t.Error("Expected stale output dropped")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Shell integration: wrapper functions that cd to the directory bon3 exits in
+// (via --cwd-file), and OSC 7 so terminals follow the tree root.
+
+const posixShellInit = `# bon3: cd to the last directory on exit
+# Add to ~/.%[1]src: eval "$(bon3 --shell-init %[1]s)"
+bon3() {
+  local tmp dir ret
+  case "$1" in
+    # Subcommands and the init script itself don't start the TUI
+    ctl|--shell-init|--shell-init=*) command bon3 "$@"; return ;;
+  esac
+  tmp="$(mktemp -t bon3-cwd.XXXXXX)" || return
+  command bon3 --cwd-file="$tmp" "$@"
+  ret=$?
+  dir="$(cat -- "$tmp" 2>/dev/null)"
+  rm -f -- "$tmp"
+  if [ -n "$dir" ] && [ "$dir" != "$PWD" ] && [ -d "$dir" ]; then
+    cd -- "$dir" || return
+  fi
+  return $ret
+}
+`
+
+const fishShellInit = `# bon3: cd to the last directory on exit
+# Add to ~/.config/fish/config.fish: bon3 --shell-init fish | source
+function bon3
+    # Subcommands and the init script itself don't start the TUI
+    switch "$argv[1]"
+        case ctl --shell-init '--shell-init=*'
+            command bon3 $argv
+            return
+    end
+    set -l tmp (mktemp -t bon3-cwd.XXXXXX); or return
+    command bon3 --cwd-file=$tmp $argv
+    set -l ret $status
+    set -l dir (cat -- $tmp 2>/dev/null)
+    rm -f -- $tmp
+    if test -n "$dir" -a "$dir" != "$PWD" -a -d "$dir"
+        cd -- $dir
+    end
+    return $ret
+end
+`
+
+// shellInitScript returns the wrapper function for bash, zsh or fish
+func shellInitScript(shell string) (string, error) {
+	switch shell {
+	case "bash", "zsh":
+		return fmt.Sprintf(posixShellInit, shell), nil
+	case "fish":
+		return fishShellInit, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (bash, zsh or fish)", shell)
+}
+
+// writeCwdFile records the directory for the shell wrapper to cd to
+func writeCwdFile(path, dir string) error {
+	return os.WriteFile(path, []byte(dir), 0644)
+}
+
+// cwdSequence is the OSC 7 sequence that tells the terminal the working directory
+func cwdSequence(dir string) string {
+	host, _ := os.Hostname()
+	return ansi.NotifyWorkingDirectory(host, dir)
+}
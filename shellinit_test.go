@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestShellInitScript(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			script, err := shellInitScript(shell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(script, "command bon3 --cwd-file=") {
+				t.Error("Expected the wrapper to pass --cwd-file")
+			}
+			// Syntax check with the shell itself when it is installed
+			if _, err := exec.LookPath(shell); err == nil {
+				cmd := exec.Command(shell, "-n")
+				cmd.Stdin = strings.NewReader(script)
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("%s -n: %v\n%s", shell, err, out)
+				}
+			}
+		})
+	}
+
+	if _, err := shellInitScript("tcsh"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
+
+func TestShellInitScript_PassesSubcommands(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	// A stand-in bon3 that prints its arguments
+	binDir := t.TempDir()
+	os.WriteFile(filepath.Join(binDir, "bon3"), []byte("#!/bin/sh\necho \"$@\"\n"), 0755)
+	script, _ := shellInitScript("bash")
+
+	tests := []struct {
+		args     string
+		expected string
+	}{
+		{"ctl selection", "ctl selection"},
+		{"--shell-init zsh", "--shell-init zsh"},
+		{"src", "--cwd-file="},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command("bash", "-c", script+"\nbon3 "+tt.args)
+		cmd.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bon3 %s: %v\n%s", tt.args, err, out)
+		}
+		if !strings.HasPrefix(string(out), tt.expected) {
+			t.Errorf("bon3 %s ran with %q, expected %q first", tt.args, out, tt.expected)
+		}
+	}
+}
+
+func TestCwdSequence(t *testing.T) {
+	seq := cwdSequence("/tmp/a b")
+	if !strings.HasPrefix(seq, "\x1b]7;file://") || !strings.HasSuffix(seq, "/tmp/a%20b\x07") {
+		t.Errorf("cwdSequence = %q", seq)
+	}
+}
+
+// collectMsgs runs cmd and any batched commands, returning their messages
+func collectMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, collectMsgs(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+func TestCwdOnExitAndRootChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	sub := filepath.Join(tmpDir, "sub")
+	os.Mkdir(sub, 0755)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	// No watching: collectMsgs would block on the watcher's command
+	if model.watcher != nil {
+		model.watcher.Close()
+		model.watcher = nil
+	}
+	model.watcherEnabled = false
+	model.height = 20
+	model.selected = 1 // sub/
+
+	if got := model.exitDir(); got != model.tree.Root.Path {
+		t.Errorf("Expected the root on q, got %s", got)
+	}
+	next, _ := model.Update(keyMsg("Q"))
+	quit := next.(Model)
+	if got := quit.exitDir(); got != sub {
+		t.Errorf("Expected the selected directory on Q, got %s", got)
+	}
+
+	// Changing the root emits OSC 7
+	var m tea.Model = model
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("n"))
+	for _, r := range "sub" {
+		m, _ = m.Update(keyMsg(string(r)))
+	}
+	m, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+	if m.(Model).tree.Root.Path != sub {
+		t.Fatalf("Expected root changed to %s", sub)
+	}
+	found := false
+	for _, msg := range collectMsgs(cmd) {
+		if strings.Contains(fmt.Sprint(msg), "\x1b]7;file://") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected OSC 7 after the root change")
+	}
+}
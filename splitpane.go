@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Split-pane preview content.
+// Loading runs off the UI goroutine, so it only touches the file system;
+// VCS status for directory entries is looked up when the pane is drawn.
+
+// splitEntry is a directory entry shown in the split pane
+type splitEntry struct {
+	Name  string
+	Path  string
+	IsDir bool
+}
+
+// splitContent is the loaded preview of one path
+type splitContent struct {
+	Path    string
+	Info    string       // Kind and size shown in the pane title
+	Lines   []string     // File lines (text, hex dump, archive or image info)
+	Spans   [][]textSpan // Syntax spans per line (nil = plain)
+	Entries []splitEntry // Directory listing (nil for files)
+	IsDir   bool
+	Err     error
+}
+
+// loadSplitContent reads enough of path to fill maxLines rows
+func loadSplitContent(path string, showHidden bool, maxLines int) splitContent {
+	c := splitContent{Path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+
+	if info.IsDir() {
+		c.IsDir = true
+		c.Entries, c.Err = readSplitEntries(path, showHidden)
+		c.Info = fmt.Sprintf("%d items", len(c.Entries))
+		return c
+	}
+
+	switch {
+	case isArchiveFile(path):
+		entries, err := listArchive(path)
+		if err != nil {
+			c.Err = err
+			return c
+		}
+		c.Info = fmt.Sprintf("archive, %d entries", len(entries))
+		for _, e := range entries {
+			if len(c.Lines) >= maxLines {
+				break
+			}
+			name := e.Name
+			if e.IsDir {
+				name += "/"
+			}
+			c.Lines = append(c.Lines, fmt.Sprintf("%8s  %s", formatFileSize(e.Size), name))
+		}
+		return c
+
+	case isImageFile(path):
+		w, h, format, size, err := getImageInfo(path)
+		if err != nil {
+			c.Err = err
+			return c
+		}
+		c.Info = "image"
+		c.Lines = []string{
+			fmt.Sprintf("Format: %s", format),
+			fmt.Sprintf("Size:   %dx%d", w, h),
+			fmt.Sprintf("File:   %s", formatFileSize(size)),
+		}
+		return c
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	defer file.Close()
+
+	head := make([]byte, SplitPreviewBytes)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		c.Err = err
+		return c
+	}
+	head = head[:n]
+
+	if isBinaryContent(head) {
+		c.Info = fmt.Sprintf("binary, %s", formatFileSize(info.Size()))
+		if limit := maxLines * hexBytesPerLine; len(head) > limit {
+			head = head[:limit]
+		}
+		c.Lines = formatHexDump(head, 0, hexBytesPerLine)
+		return c
+	}
+
+	text, enc := decodeText(head)
+	c.Info = formatFileSize(info.Size())
+	if enc.Name != encodingUTF8.Name {
+		c.Info += ", " + enc.Name
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	} else if int64(n) < info.Size() && len(lines) > 1 {
+		// The last line was cut off by the read limit
+		lines = lines[:len(lines)-1]
+	}
+	c.Lines = lines
+	c.Spans = highlightLines(path, lines)
+	return c
+}
+
+// readSplitEntries lists a directory in tree order (directories first, then by name)
+func readSplitEntries(dir string, showHidden bool) ([]splitEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]splitEntry, 0, len(dirEntries))
+	for _, e := range dirEntries {
+		if !showHidden && e.Name()[0] == '.' {
+			continue
+		}
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, e.Name())); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		entries = append(entries, splitEntry{Name: e.Name(), Path: filepath.Join(dir, e.Name()), IsDir: isDir})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// vcsSummary counts VCS statuses of the entries, e.g. "2 modified, 1 untracked"
+func vcsSummary(entries []splitEntry, status func(path string) VCSStatus) string {
+	counts := make(map[VCSStatus]int)
+	for _, e := range entries {
+		if s := status(e.Path); s != VCSStatusNone {
+			counts[s]++
+		}
+	}
+
+	var parts []string
+	for _, s := range []VCSStatus{VCSStatusConflict, VCSStatusModified, VCSStatusAdded, VCSStatusRenamed, VCSStatusDeleted, VCSStatusUntracked} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], vcsStatusName(s)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// vcsStatusName returns the lowercase name of a VCS status for summaries
+func vcsStatusName(s VCSStatus) string {
+	switch s {
+	case VCSStatusModified:
+		return "modified"
+	case VCSStatusAdded:
+		return "added"
+	case VCSStatusDeleted:
+		return "deleted"
+	case VCSStatusRenamed:
+		return "renamed"
+	case VCSStatusUntracked:
+		return "untracked"
+	case VCSStatusIgnored:
+		return "ignored"
+	case VCSStatusConflict:
+		return "conflict"
+	default:
+		return ""
+	}
+}
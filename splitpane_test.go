@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestLoadSplitContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Mkdir(filepath.Join(tmpDir, "zdir"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "a.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".hidden"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "data.bin"), []byte{0x00, 0x01, 0x02, 0xff}, 0644)
+
+	t.Run("directory", func(t *testing.T) {
+		c := loadSplitContent(tmpDir, false, 20)
+		if !c.IsDir || c.Err != nil {
+			t.Fatalf("Expected directory listing, got %+v", c)
+		}
+		var names []string
+		for _, e := range c.Entries {
+			names = append(names, e.Name)
+		}
+		if got := strings.Join(names, ","); got != "zdir,a.go,data.bin" {
+			t.Errorf("Entries = %s, expected directories first and no hidden files", got)
+		}
+		if c := loadSplitContent(tmpDir, true, 20); len(c.Entries) != 4 {
+			t.Errorf("Expected hidden file with showHidden, got %d entries", len(c.Entries))
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		c := loadSplitContent(filepath.Join(tmpDir, "a.go"), false, 2)
+		if len(c.Lines) != 2 || c.Lines[0] != "package main" {
+			t.Errorf("Lines = %q, expected the first 2 lines", c.Lines)
+		}
+		if len(c.Spans) != 2 || len(c.Spans[0]) == 0 {
+			t.Error("Expected syntax spans for Go source")
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		c := loadSplitContent(filepath.Join(tmpDir, "data.bin"), false, 20)
+		if len(c.Lines) != 1 || !strings.HasPrefix(c.Lines[0], "00000000  00 01 02 ff") {
+			t.Errorf("Expected hex dump, got %q", c.Lines)
+		}
+		if !strings.HasPrefix(c.Info, "binary") {
+			t.Errorf("Info = %q", c.Info)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if c := loadSplitContent(filepath.Join(tmpDir, "nope"), false, 20); c.Err == nil {
+			t.Error("Expected error for missing path")
+		}
+	})
+}
+
+func TestVCSSummary(t *testing.T) {
+	entries := []splitEntry{{Path: "a"}, {Path: "b"}, {Path: "c"}, {Path: "d"}}
+	statuses := map[string]VCSStatus{"a": VCSStatusModified, "b": VCSStatusUntracked, "c": VCSStatusModified}
+	got := vcsSummary(entries, func(p string) VCSStatus { return statuses[p] })
+	if got != "2 modified, 1 untracked" {
+		t.Errorf("vcsSummary = %q", got)
+	}
+	if got := vcsSummary(entries, func(string) VCSStatus { return VCSStatusNone }); got != "" {
+		t.Errorf("Expected empty summary for clean entries, got %q", got)
+	}
+}
+
+func TestSplitPane_FollowsSelection(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("alpha\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("bravo\n"), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.height = 10
+	model.width = 60
+	model.selected = 1
+
+	newModel, cmd := model.Update(keyMsg("P"))
+	m := newModel.(Model)
+	if !m.splitPane || cmd == nil || m.splitPath != filepath.Join(tmpDir, "a.txt") {
+		t.Fatalf("Expected split pane to schedule a load for a.txt, got %q", m.splitPath)
+	}
+	firstTick := splitPreviewTickMsg{id: m.splitID}
+
+	// Moving on makes the pending load stale
+	newModel, cmd = m.Update(keyMsg("j"))
+	m = newModel.(Model)
+	if cmd == nil || m.splitPath != filepath.Join(tmpDir, "b.txt") {
+		t.Fatalf("Expected a new load for b.txt, got %q", m.splitPath)
+	}
+	if m.stepSplitPreview(firstTick) != nil {
+		t.Error("Expected stale debounce tick to be dropped")
+	}
+
+	load := m.stepSplitPreview(splitPreviewTickMsg{id: m.splitID})
+	if load == nil {
+		t.Fatal("Expected load after debounce")
+	}
+	newModel, _ = m.Update(load())
+	m = newModel.(Model)
+	if m.splitContent == nil || m.splitContent.Lines[0] != "bravo" {
+		t.Fatalf("Expected b.txt content in the pane, got %+v", m.splitContent)
+	}
+
+	view := ansi.Strip(m.renderTreeView())
+	if !strings.Contains(view, "│") || !strings.Contains(view, "bravo") {
+		t.Errorf("Expected divider and preview in view:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n")[1:9] {
+		if w := ansi.StringWidth(line); w != 60 {
+			t.Errorf("Expected split rows to fill the width, got %d: %q", w, line)
+		}
+	}
+
+	// Resize is clamped
+	for i := 0; i < 20; i++ {
+		newModel, _ = m.Update(keyMsg(">"))
+		m = newModel.(Model)
+	}
+	if m.splitPercent != SplitMaxPercent {
+		t.Errorf("Expected tree width clamped to %d%%, got %d%%", SplitMaxPercent, m.splitPercent)
+	}
+
+	// P again hides the pane; loads in flight are ignored
+	newModel, _ = m.Update(keyMsg("P"))
+	m = newModel.(Model)
+	m.applySplitPreview(splitPreviewLoadedMsg{id: m.splitID - 1})
+	if m.splitPane || m.splitContent != nil {
+		t.Error("Expected split pane closed")
+	}
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Streaming preview for files too large to load into memory.
+// The file is memory-mapped and only the visible rows are read, so scrolling
+// costs the same regardless of file size. Text rows are lines (split at
+// MaxStreamLineBytes); hex rows are rowBytes bytes.
+
+// streamWindowBytes is the read size when walking rows
+const streamWindowBytes = 64 * 1024
+
+// pagedFile is a read-only, memory-mapped view of a file that may grow
+type pagedFile struct {
+	file *os.File
+	data []byte // Mapping (nil if empty or mapping is unavailable)
+	size int64
+}
+
+func openPagedFile(path string) (*pagedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p := &pagedFile{file: f, size: info.Size()}
+	// Without a mapping, reads fall back to ReadAt
+	p.data, _ = mmapFile(f, p.size)
+	return p, nil
+}
+
+// remap maps the file again if its size changed. Returns the previous size.
+func (p *pagedFile) remap() (int64, error) {
+	info, err := p.file.Stat()
+	if err != nil {
+		return p.size, err
+	}
+	old := p.size
+	if info.Size() == old {
+		return old, nil
+	}
+	munmapFile(p.data)
+	p.size = info.Size()
+	p.data, _ = mmapFile(p.file, p.size)
+	return old, nil
+}
+
+// read returns a copy of the bytes in [off, end), clamped to the file size.
+// A file truncated behind the mapping yields nil instead of crashing.
+func (p *pagedFile) read(off, end int64) (b []byte) {
+	end = min(end, p.size)
+	if off < 0 || off >= end {
+		return nil
+	}
+	if p.data == nil {
+		b = make([]byte, end-off)
+		n, _ := p.file.ReadAt(b, off)
+		return b[:n]
+	}
+
+	defer func() {
+		if recover() != nil {
+			b = nil
+		}
+	}()
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	return bytes.Clone(p.data[off:end])
+}
+
+func (p *pagedFile) Close() error {
+	munmapFile(p.data)
+	p.data = nil
+	return p.file.Close()
+}
+
+// splitStreamLines calls fn with the bounds of each line in buf.
+// A line ends after '\n' or after MaxStreamLineBytes; a trailing line without
+// either is only reported at EOF. fn returns false to stop after that line.
+// Returns the number of bytes consumed.
+func splitStreamLines(buf []byte, atEOF bool, fn func(start, end int) bool) int {
+	pos := 0
+	for pos < len(buf) {
+		limit := min(pos+MaxStreamLineBytes, len(buf))
+		end := limit
+		if i := bytes.IndexByte(buf[pos:limit], '\n'); i >= 0 {
+			end = pos + i + 1
+		} else if limit-pos < MaxStreamLineBytes && !atEOF {
+			break
+		}
+		if !fn(pos, end) {
+			return end
+		}
+		pos = end
+	}
+	return pos
+}
+
+// walkLines calls fn for each line from offset from (which must be a line start),
+// reading chunk bytes at a time, until fn returns false or the file ends
+func (p *pagedFile) walkLines(from, chunk int64, fn func(start, end int64) bool) {
+	for from < p.size {
+		buf := p.read(from, from+chunk)
+		atEOF := from+int64(len(buf)) >= p.size
+		stopped := false
+		base := from
+		n := splitStreamLines(buf, atEOF, func(s, e int) bool {
+			if !fn(base+int64(s), base+int64(e)) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped || n == 0 {
+			return
+		}
+		from += int64(n)
+	}
+}
+
+// lineIndex is a sparse line index built incrementally from the start of the file.
+// Only every StreamIndexInterval-th line start is stored; lines in between are
+// found by scanning forward from the nearest checkpoint.
+type lineIndex struct {
+	checkpoints []int64 // Start offset of every StreamIndexInterval-th line
+	lines       int64   // Number of lines found so far
+	next        int64   // Offset where scanning continues (start of the next line)
+	lastStart   int64   // Start of the last line found
+	partial     bool    // Last line ended at EOF without a newline (may still grow)
+	eof         bool    // Whole file scanned
+}
+
+func (ix *lineIndex) add(start, end int64, partial bool) {
+	if ix.lines%StreamIndexInterval == 0 {
+		ix.checkpoints = append(ix.checkpoints, start)
+	}
+	ix.lines++
+	ix.lastStart = start
+	ix.next = end
+	ix.partial = partial
+}
+
+// extend scans forward until done returns true, budget bytes were scanned, or EOF
+func (ix *lineIndex) extend(p *pagedFile, budget int64, done func() bool) {
+	if ix.eof || done() {
+		return
+	}
+	limit := ix.next + min(budget, math.MaxInt64-ix.next)
+	p.walkLines(ix.next, streamWindowBytes, func(start, end int64) bool {
+		partial := false
+		if end == p.size && end-start < MaxStreamLineBytes {
+			last := p.read(end-1, end)
+			partial = len(last) == 1 && last[0] != '\n'
+		}
+		ix.add(start, end, partial)
+		return end < limit && !done()
+	})
+	if ix.next >= p.size {
+		ix.eof = true
+	}
+}
+
+// lineStart returns the start offset of line n (0-based).
+// Lines past the indexed range clamp to the last line indexed so far.
+func (ix *lineIndex) lineStart(p *pagedFile, n int64) int64 {
+	if ix.lines == 0 {
+		return 0
+	}
+	n = min(max(n, 0), ix.lines-1)
+
+	line := n / StreamIndexInterval * StreamIndexInterval
+	pos := ix.checkpoints[n/StreamIndexInterval]
+	p.walkLines(pos, streamWindowBytes, func(start, _ int64) bool {
+		if line == n {
+			pos = start
+			return false
+		}
+		line++
+		return true
+	})
+	return pos
+}
+
+// lineOf returns the line (0-based) containing off; false if not indexed yet
+func (ix *lineIndex) lineOf(p *pagedFile, off int64) (int64, bool) {
+	if off < 0 || off >= ix.next || len(ix.checkpoints) == 0 {
+		return 0, false
+	}
+	j := sort.Search(len(ix.checkpoints), func(i int) bool { return ix.checkpoints[i] > off }) - 1
+	line := int64(j) * StreamIndexInterval
+	p.walkLines(ix.checkpoints[j], streamWindowBytes, func(_, end int64) bool {
+		if end > off {
+			return false
+		}
+		line++
+		return true
+	})
+	return line, true
+}
+
+// resize updates the index after the file changed size
+func (ix *lineIndex) resize(oldSize, newSize int64) {
+	if newSize < oldSize {
+		*ix = lineIndex{}
+		return
+	}
+	// An unterminated last line may continue in the new data
+	if ix.eof && ix.partial {
+		ix.lines--
+		if ix.lines%StreamIndexInterval == 0 {
+			ix.checkpoints = ix.checkpoints[:len(ix.checkpoints)-1]
+		}
+		ix.next = ix.lastStart
+	}
+	ix.eof = false
+	ix.partial = false
+}
+
+// streamView is the viewport state of a streaming preview
+type streamView struct {
+	file     *pagedFile
+	index    lineIndex
+	hex      bool  // Rows are hex dump lines instead of text lines
+	top      int64 // Offset of the first visible row
+	topLine  int64 // Line (or hex row) number of top (-1 = not indexed yet)
+	follow   bool  // Stay at the end as the file grows
+	followID int   // Generation of the follow timer (stale ticks are dropped)
+	indexing bool  // A background index step is scheduled
+	pending  int64 // Line a jump waits for the index to reach (-1 = none)
+
+	// Hex viewer
+	rowBytes  int   // Bytes per hex row (8, 16 or 32)
+	cursor    int64 // Offset of the byte under the cursor
+	bigEndian bool  // Inspector decodes big-endian values
+
+	// Rows read by the last window call
+	rows      []int64 // Start offset of each row
+	windowEnd int64   // End offset of the last row
+
+	// Current search match (absolute offsets; matchStart < 0 = none)
+	matchStart  int64
+	matchEnd    int64
+	searchedAll bool // Last search covered the whole file
+}
+
+func newStreamView(p *pagedFile, hex bool) *streamView {
+	return &streamView{file: p, hex: hex, topLine: -1, pending: -1, rowBytes: hexBytesPerLine, matchStart: -1, matchEnd: -1}
+}
+
+func (s *streamView) size() int64 {
+	return s.file.size
+}
+
+// walkRows calls fn for each row from off until it returns false or the file ends
+func (s *streamView) walkRows(off int64, fn func(start, end int64) bool) {
+	if s.hex {
+		rb := int64(s.rowBytes)
+		for ; off < s.size(); off += rb {
+			if !fn(off, min(off+rb, s.size())) {
+				return
+			}
+		}
+		return
+	}
+	s.file.walkLines(off, streamWindowBytes, fn)
+}
+
+// rowStart returns the start of the row containing off.
+// Outside the indexed range, text lines longer than streamWindowBytes are
+// split at an approximate position.
+func (s *streamView) rowStart(off int64) int64 {
+	if s.size() == 0 {
+		return 0
+	}
+	off = min(max(off, 0), s.size()-1)
+	if s.hex {
+		return off / int64(s.rowBytes) * int64(s.rowBytes)
+	}
+	if line, ok := s.index.lineOf(s.file, off); ok {
+		return s.index.lineStart(s.file, line)
+	}
+
+	from := max(off-streamWindowBytes, 0)
+	start := from
+	if i := bytes.LastIndexByte(s.file.read(from, off), '\n'); i >= 0 {
+		start = from + int64(i) + 1
+	}
+	s.file.walkLines(start, streamWindowBytes, func(st, end int64) bool {
+		start = st
+		return end <= off
+	})
+	return start
+}
+
+// scroll moves the viewport by delta rows, keeping the last row at or below
+// the bottom of a viewport of visible rows
+func (s *streamView) scroll(delta, visible int) {
+	if delta > 0 {
+		s.walkRows(s.top, func(_, end int64) bool {
+			if end >= s.size() {
+				return false
+			}
+			s.top = end
+			delta--
+			return delta > 0
+		})
+	}
+	for ; delta < 0 && s.top > 0; delta++ {
+		s.top = s.rowStart(s.top - 1)
+	}
+	s.top = min(s.top, s.lastTop(visible))
+}
+
+// lastTop returns the top offset that shows the last row at the bottom
+func (s *streamView) lastTop(visible int) int64 {
+	top := s.size()
+	for i := 0; i < max(visible, 1) && top > 0; i++ {
+		top = s.rowStart(top - 1)
+	}
+	return top
+}
+
+// jumpLine moves the top to line (or hex row) n, 0-based. Text lines are indexed
+// at most StreamIndexStepBytes further; a line beyond that stays pending (false)
+// until the background index reaches it.
+func (s *streamView) jumpLine(n int64) bool {
+	if s.hex {
+		s.top = s.rowStart(max(n, 0) * int64(s.rowBytes))
+		return true
+	}
+	s.index.extend(s.file, StreamIndexStepBytes, func() bool { return s.index.lines > n })
+	if s.index.lines <= n && !s.index.eof {
+		s.pending = n
+		return false
+	}
+	s.pending = -1
+	s.top = s.index.lineStart(s.file, n)
+	return true
+}
+
+// jumpOffset moves the top to the row containing off
+func (s *streamView) jumpOffset(off int64) {
+	s.top = s.rowStart(off)
+}
+
+// center moves the top row to the middle of a viewport of visible rows
+func (s *streamView) center(visible int) {
+	s.scroll(-visible/2, visible)
+}
+
+// window reads up to rows rows from the top. For hex views data holds the raw bytes.
+func (s *streamView) window(rows int) (lines []string, data []byte) {
+	var starts, ends []int64
+	s.walkRows(s.top, func(start, end int64) bool {
+		starts = append(starts, start)
+		ends = append(ends, end)
+		return len(starts) < rows
+	})
+	s.rows = starts
+	s.windowEnd = s.top
+	if len(starts) == 0 {
+		return nil, nil
+	}
+	s.windowEnd = ends[len(ends)-1]
+
+	base := starts[0]
+	buf := s.file.read(base, ends[len(ends)-1])
+	if s.hex {
+		return formatHexDump(buf, base, s.rowBytes), buf
+	}
+
+	lines = make([]string, 0, len(starts))
+	for i := range starts {
+		from, to := starts[i]-base, ends[i]-base
+		if to > int64(len(buf)) {
+			break
+		}
+		lines = append(lines, strings.TrimSuffix(string(buf[from:to]), "\n"))
+	}
+	return lines, nil
+}
+
+// updateTopLine recomputes the line number of the top row
+func (s *streamView) updateTopLine() {
+	if s.hex {
+		s.topLine = s.top / int64(s.rowBytes)
+		return
+	}
+	s.topLine = -1
+	if s.top == 0 {
+		s.topLine = 0
+	} else if line, ok := s.index.lineOf(s.file, s.top); ok {
+		s.topLine = line
+	}
+}
+
+// streamMatcher returns a function that finds query matches in a chunk.
+// Hex views search "0xcafe" or "de ad be ef" as bytes.
+func streamMatcher(query string, hex, caseSensitive, useRegex bool) (func([]byte) [][]int, error) {
+	if pattern, ok := parseHexPattern(query); ok && hex && !useRegex {
+		return func(buf []byte) [][]int {
+			var locs [][]int
+			for offset := 0; offset < len(buf); {
+				idx := bytes.Index(buf[offset:], pattern)
+				if idx < 0 {
+					break
+				}
+				start := offset + idx
+				locs = append(locs, []int{start, start + len(pattern)})
+				offset = start + 1
+			}
+			return locs
+		}, nil
+	}
+
+	re, err := compileSearchPattern(query, caseSensitive, useRegex)
+	if err != nil {
+		return nil, err
+	}
+	return func(buf []byte) [][]int {
+		return re.FindAllIndex(buf, -1)
+	}, nil
+}
+
+// find searches forward from from (matches starting at or after it) or backward
+// (matches starting before it), scanning at most limit bytes (< 0 = no limit).
+// Chunks overlap by MaxStreamLineBytes so matches across chunk borders are found.
+func (s *streamView) find(match func([]byte) [][]int, from int64, forward bool, limit int64) (int64, int64, bool) {
+	const overlap = MaxStreamLineBytes
+	within := func(pos int64) bool {
+		return limit < 0 || (forward && pos < from+limit) || (!forward && pos > from-limit)
+	}
+
+	if forward {
+		for pos := max(from, 0); pos < s.size() && within(pos); pos += StreamChunkBytes {
+			buf := s.file.read(pos, pos+StreamChunkBytes+overlap)
+			for _, loc := range match(buf) {
+				if loc[0] < loc[1] && loc[0] < StreamChunkBytes {
+					return pos + int64(loc[0]), pos + int64(loc[1]), true
+				}
+			}
+		}
+		return 0, 0, false
+	}
+
+	for end := min(from, s.size()); end > 0 && within(end); end -= StreamChunkBytes {
+		start := max(end-StreamChunkBytes, 0)
+		buf := s.file.read(start, end+overlap)
+		found := false
+		var bestStart, bestEnd int64
+		for _, loc := range match(buf) {
+			if loc[0] < loc[1] && start+int64(loc[0]) < end {
+				bestStart, bestEnd, found = start+int64(loc[0]), start+int64(loc[1]), true
+			}
+		}
+		if found {
+			return bestStart, bestEnd, true
+		}
+	}
+	return 0, 0, false
+}
+
+// position describes the text viewport for the status bar
+func (s *streamView) position() string {
+	percent := 0
+	if s.size() > 0 {
+		percent = int(s.top * 100 / s.size())
+	}
+	line := "?"
+	if s.topLine >= 0 {
+		line = fmt.Sprintf("%d", s.topLine+1)
+	}
+	total := fmt.Sprintf("%d", s.index.lines)
+	if !s.index.eof {
+		total += "+"
+	}
+	position := fmt.Sprintf("Line %s/%s (%d%%) Offset 0x%x", line, total, percent, s.top)
+	if s.pending >= 0 && s.size() > 0 {
+		position += fmt.Sprintf(" [JUMP TO %d: %d%% indexed]", s.pending+1, s.index.next*100/s.size())
+	}
+	return position
+}
+
+// jumpKind is the kind of target entered in the jump prompt
+type jumpKind int
+
+const (
+	jumpLine    jumpKind = iota // Line number (1-based; hex row in hex views)
+	jumpOffset                  // Byte offset
+	jumpPercent                 // Percentage of the file
+)
+
+// parseJumpTarget parses a jump prompt: "120" (line), "0x1f00" or "@8192" (byte offset), "50%"
+func parseJumpTarget(input string) (jumpKind, int64, error) {
+	s := strings.TrimSpace(input)
+	switch {
+	case strings.HasSuffix(s, "%"):
+		v, err := strconv.ParseInt(strings.TrimSuffix(s, "%"), 10, 64)
+		if err != nil || v < 0 || v > 100 {
+			return 0, 0, fmt.Errorf("invalid percentage: %s", s)
+		}
+		return jumpPercent, v, nil
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		v, err := strconv.ParseInt(s[2:], 16, 64)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid offset: %s", s)
+		}
+		return jumpOffset, v, nil
+	case strings.HasPrefix(s, "@"):
+		v, err := strconv.ParseInt(s[1:], 0, 64)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid offset: %s", s)
+		}
+		return jumpOffset, v, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 1 {
+		return 0, 0, fmt.Errorf("invalid line: %s", s)
+	}
+	return jumpLine, v, nil
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// writeLines writes n numbered lines ("line 0".."line n-1") and returns the path
+func writeLines(t *testing.T, dir string, n int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	path := filepath.Join(dir, "big.log")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func openTestStream(t *testing.T, path string, hex bool) *streamView {
+	t.Helper()
+	p, err := openPagedFile(path)
+	if err != nil {
+		t.Fatalf("openPagedFile: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return newStreamView(p, hex)
+}
+
+func TestSplitStreamLines(t *testing.T) {
+	long := strings.Repeat("x", MaxStreamLineBytes+10)
+	tests := []struct {
+		name     string
+		input    string
+		atEOF    bool
+		expected []string
+	}{
+		{"newlines", "a\nbb\n", false, []string{"a\n", "bb\n"}},
+		{"partial line waits", "a\nbb", false, []string{"a\n"}},
+		{"partial line at EOF", "a\nbb", true, []string{"a\n", "bb"}},
+		{"long line split", long, true, []string{long[:MaxStreamLineBytes], long[MaxStreamLineBytes:]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			splitStreamLines([]byte(tt.input), tt.atEOF, func(s, e int) bool {
+				got = append(got, tt.input[s:e])
+				return true
+			})
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("got %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLineIndex(t *testing.T) {
+	path := writeLines(t, t.TempDir(), 5000)
+	s := openTestStream(t, path, false)
+
+	// Line 3000 lies between checkpoints
+	s.index.extend(s.file, 1<<40, func() bool { return s.index.lines > 3000 })
+	off := s.index.lineStart(s.file, 3000)
+	if got := string(s.file.read(off, off+9)); got != "line 3000" {
+		t.Errorf("lineStart(3000) points at %q", got)
+	}
+	if line, ok := s.index.lineOf(s.file, off+3); !ok || line != 3000 {
+		t.Errorf("lineOf = %d, %v; expected 3000", line, ok)
+	}
+	if s.index.eof {
+		t.Error("Expected index to stop short of EOF")
+	}
+
+	// Offsets past the indexed range are unknown until indexing continues
+	if _, ok := s.index.lineOf(s.file, s.size()-1); ok {
+		t.Error("Expected unindexed offset to be unknown")
+	}
+	s.index.extend(s.file, 1<<40, func() bool { return false })
+	if !s.index.eof || s.index.lines != 5000 {
+		t.Errorf("Expected 5000 lines at EOF, got %d (eof=%v)", s.index.lines, s.index.eof)
+	}
+
+	// Past the end clamps to the last line
+	off = s.index.lineStart(s.file, 99999)
+	if got := string(s.file.read(off, s.size())); got != "line 4999\n" {
+		t.Errorf("lineStart past end points at %q", got)
+	}
+}
+
+func TestLineIndex_Growth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grow.log")
+	os.WriteFile(path, []byte("one\ntw"), 0644)
+	s := openTestStream(t, path, false)
+	s.index.extend(s.file, 1<<40, func() bool { return false })
+	if s.index.lines != 2 {
+		t.Fatalf("Expected 2 lines, got %d", s.index.lines)
+	}
+
+	// The unterminated last line continues in the appended data
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("o\nthree\n")
+	f.Close()
+	old, err := s.file.remap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.index.resize(old, s.size())
+	s.index.extend(s.file, 1<<40, func() bool { return false })
+	if s.index.lines != 3 {
+		t.Errorf("Expected 3 lines after growth, got %d", s.index.lines)
+	}
+	off := s.index.lineStart(s.file, 1)
+	if got := string(s.file.read(off, off+3)); got != "two" {
+		t.Errorf("Line 1 = %q, expected two", got)
+	}
+}
+
+func TestStreamView_ScrollAndWindow(t *testing.T) {
+	path := writeLines(t, t.TempDir(), 1000)
+	s := openTestStream(t, path, false)
+
+	s.scroll(10, 20)
+	lines, _ := s.window(3)
+	if len(lines) != 3 || lines[0] != "line 10" || lines[2] != "line 12" {
+		t.Errorf("window after scroll = %q", lines)
+	}
+
+	// Scrolling back works without the line index
+	s.scroll(-4, 20)
+	lines, _ = s.window(1)
+	if lines[0] != "line 6" {
+		t.Errorf("window after scrolling up = %q", lines)
+	}
+
+	// The last row stays at the bottom
+	s.scroll(5000, 20)
+	lines, _ = s.window(20)
+	if len(lines) != 20 || lines[19] != "line 999" {
+		t.Errorf("Expected last page to end at line 999, got %q", lines[len(lines)-1])
+	}
+}
+
+func TestStreamView_Hex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	data := make([]byte, 4096)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	copy(data[2000:], []byte{0xde, 0xad, 0xbe, 0xef})
+	os.WriteFile(path, data, 0644)
+	s := openTestStream(t, path, true)
+
+	s.jumpOffset(0x105)
+	if s.top != 0x100 {
+		t.Errorf("Expected top at 0x100, got %#x", s.top)
+	}
+	lines, window := s.window(2)
+	if !strings.HasPrefix(lines[0], "00000100  00 01 02") || len(window) != 32 {
+		t.Errorf("Unexpected hex window %q (%d bytes)", lines[0], len(window))
+	}
+
+	match, _ := streamMatcher("de ad be ef", true, false, false)
+	start, end, ok := s.find(match, 0, true, -1)
+	if !ok || start != 2000 || end != 2004 {
+		t.Errorf("find = %d-%d %v, expected 2000-2004", start, end, ok)
+	}
+	if _, _, ok := s.find(match, 2001, true, -1); ok {
+		t.Error("Expected no match after the only occurrence")
+	}
+	if start, _, ok := s.find(match, 4096, false, -1); !ok || start != 2000 {
+		t.Errorf("Backward find = %d %v, expected 2000", start, ok)
+	}
+}
+
+func TestStreamView_FindAcrossChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.txt")
+	data := []byte(strings.Repeat("a", StreamChunkBytes-2) + "needle" + strings.Repeat("b", 100))
+	os.WriteFile(path, data, 0644)
+	s := openTestStream(t, path, false)
+
+	match, _ := streamMatcher("needle", false, false, false)
+	start, _, ok := s.find(match, 0, true, -1)
+	if !ok || start != StreamChunkBytes-2 {
+		t.Errorf("find = %d %v, expected match across chunk border", start, ok)
+	}
+}
+
+func TestParseJumpTarget(t *testing.T) {
+	tests := []struct {
+		input   string
+		kind    jumpKind
+		value   int64
+		wantErr bool
+	}{
+		{"120", jumpLine, 120, false},
+		{"0x1f", jumpOffset, 31, false},
+		{"@8192", jumpOffset, 8192, false},
+		{"@0x10", jumpOffset, 16, false},
+		{"50%", jumpPercent, 50, false},
+		{"0", 0, 0, true},
+		{"150%", 0, 0, true},
+		{"abc", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			kind, v, err := parseJumpTarget(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJumpTarget(%q) error = %v", tt.input, err)
+			}
+			if !tt.wantErr && (kind != tt.kind || v != tt.value) {
+				t.Errorf("parseJumpTarget(%q) = %v, %d", tt.input, kind, v)
+			}
+		})
+	}
+}
+
+func TestPreview_LargeFileStreams(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeLines(t, tmpDir, 100000) // ~1.1MB, above MaxPreviewBytes
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.height = 24
+	model.width = 80
+	model.selected = 1
+	model.openPreview()
+	defer model.closePreview()
+	if model.previewStream == nil {
+		t.Fatal("Expected streaming preview for a large file")
+	}
+	if len(model.previewContent) != 22 {
+		t.Errorf("Expected only the visible rows to be loaded, got %d", len(model.previewContent))
+	}
+
+	// Jump to a line with the prompt
+	m := model
+	newModel, _ := m.Update(keyMsg(":"))
+	m = newModel.(Model)
+	if m.inputMode != ModePreviewJump {
+		t.Fatalf("Expected ModePreviewJump, got %v", m.inputMode)
+	}
+	for _, r := range "90001" {
+		newModel, _ = m.Update(keyMsg(string(r)))
+		m = newModel.(Model)
+	}
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	m = newModel.(Model)
+	found := false
+	for _, line := range m.previewContent {
+		if line == "line 90000" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected line 90000 in view, got %q..%q", m.previewContent[0], m.previewContent[len(m.previewContent)-1])
+	}
+
+	// Search forward from the viewport, then back to earlier matches
+	m.previewSearchQuery = "line 95000"
+	m.runStreamSearch(true)
+	if m.previewMatchIndex < 0 {
+		t.Fatalf("Expected a highlighted match, status %q", m.previewSearchStatus())
+	}
+	m.previewSearchQuery = "line 5\n"
+	m.runStreamSearch(true) // Wraps around to the start
+	if m.previewStream.matchStart >= m.previewStream.size()/2 {
+		t.Errorf("Expected wrapped match near the start, got %#x", m.previewStream.matchStart)
+	}
+
+	// G shows the last line at the bottom
+	m.clearPreviewSearch()
+	newModel, _ = m.Update(keyMsg("G"))
+	m = newModel.(Model)
+	if last := m.previewContent[len(m.previewContent)-1]; last != "line 99999" {
+		t.Errorf("Expected last row line 99999, got %q", last)
+	}
+}
+
+func TestPreview_JumpWaitsForIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Three index steps of 1KB lines
+	line := strings.Repeat("x", 1023) + "\n"
+	lines := 3 * StreamIndexStepBytes / len(line)
+	if err := os.WriteFile(filepath.Join(tmpDir, "huge.log"), []byte(strings.Repeat(line, lines)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.height = 24
+	model.width = 80
+	model.selected = 1
+	model.openPreview()
+	defer model.closePreview()
+	s := model.previewStream
+	if s == nil {
+		t.Fatal("Expected streaming preview for a large file")
+	}
+
+	// The last line is past one index step: the jump waits and shows progress
+	model.jumpPreview(jumpLine, int64(lines))
+	if s.pending != int64(lines-1) || s.top != 0 {
+		t.Fatalf("Expected a pending jump at the top, got pending %d top %d", s.pending, s.top)
+	}
+	if !strings.Contains(s.position(), fmt.Sprintf("[JUMP TO %d:", lines)) {
+		t.Errorf("Expected jump progress in %q", s.position())
+	}
+
+	// Background steps land it
+	for i := 0; i < 5 && s.pending >= 0; i++ {
+		model.stepStreamIndex(streamIndexMsg{stream: s})
+	}
+	if s.pending >= 0 {
+		t.Fatal("Expected the jump to land once indexed")
+	}
+	if s.topLine < int64(lines-model.height) {
+		t.Errorf("Expected the last line in view, top line %d of %d", s.topLine, lines)
+	}
+
+	// Scrolling cancels a pending jump
+	model.jumpPreview(jumpOffset, 0)
+	s.index = lineIndex{}
+	model.jumpPreview(jumpLine, int64(lines))
+	model.Update(keyMsg("j"))
+	if s.pending >= 0 {
+		t.Error("Expected scrolling to cancel the pending jump")
+	}
+}
+
+func TestPreview_FollowMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "app.log")
+	os.WriteFile(path, []byte("start\n"), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.height = 10
+	model.selected = 1
+	model.openPreview()
+	defer model.closePreview()
+	if model.previewStream != nil {
+		t.Fatal("Expected small file to be loaded fully")
+	}
+
+	// F switches to streaming and follows appended data
+	newModel, cmd := model.Update(keyMsg("F"))
+	m := newModel.(Model)
+	if m.previewStream == nil || !m.previewStream.follow || cmd == nil {
+		t.Fatal("Expected follow mode to start")
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("appended\n")
+	f.Close()
+
+	s := m.previewStream
+	newModel, _ = m.Update(streamFollowMsg{stream: s, id: s.followID})
+	m = newModel.(Model)
+	if last := m.previewContent[len(m.previewContent)-1]; last != "appended" {
+		t.Errorf("Expected appended line shown, got %q", m.previewContent)
+	}
+
+	// Ticks from an earlier follow session are ignored
+	if cmd := m.stepStreamFollow(streamFollowMsg{stream: s, id: s.followID - 1}); cmd != nil {
+		t.Error("Expected stale follow tick to be dropped")
+	}
+}
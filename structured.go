@@ -0,0 +1,722 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"gopkg.in/yaml.v3"
+)
+
+// Structured previews.
+// JSON/YAML/TOML are parsed into a dataNode tree shown with folding; CSV/TSV are
+// laid out as an aligned table. Both produce plain lines plus spans like the
+// Markdown renderer, so search and the line renderer work unchanged.
+
+// dataKind is the type of a value in a structured document
+type dataKind int
+
+const (
+	dataObject dataKind = iota
+	dataArray
+	dataString
+	dataNumber
+	dataBool
+	dataNull
+)
+
+// dataNode is a value in a structured document
+type dataNode struct {
+	Key       string // Object key, or "[i]" for array items ("" for the root)
+	Path      string // jq-like path from the document root
+	Kind      dataKind
+	Text      string // Display text for scalars
+	Children  []*dataNode
+	Parent    *dataNode
+	Collapsed bool
+}
+
+// isContainer returns true for objects and arrays
+func (n *dataNode) isContainer() bool {
+	return n.Kind == dataObject || n.Kind == dataArray
+}
+
+// summary returns a short description of a container's size
+func (n *dataNode) summary() string {
+	if n.Kind == dataObject {
+		if len(n.Children) == 1 {
+			return "{1 key}"
+		}
+		return fmt.Sprintf("{%d keys}", len(n.Children))
+	}
+	if len(n.Children) == 1 {
+		return "[1 item]"
+	}
+	return fmt.Sprintf("[%d items]", len(n.Children))
+}
+
+// maxDataDepth guards against runaway recursion (e.g., YAML alias cycles)
+const maxDataDepth = 256
+
+// previewFormat identifies the rendered view of a preview
+type previewFormat int
+
+const (
+	formatNone previewFormat = iota
+	formatMarkdown
+	formatData
+	formatTable
+)
+
+// previewFormatFor returns the rendered view available for a path
+func previewFormatFor(path string) previewFormat {
+	if isMarkdownFile(path) {
+		return formatMarkdown
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return formatData
+	case ".csv", ".tsv", ".tab":
+		return formatTable
+	}
+	return formatNone
+}
+
+// parseDataFile parses JSON, YAML or TOML content by extension
+func parseDataFile(path string, data []byte) (*dataNode, error) {
+	var root *dataNode
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		root, err = parseJSONData(data)
+	case ".yaml", ".yml":
+		root, err = parseYAMLData(data)
+	case ".toml":
+		root, err = parseTOMLData(data)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	assignDataPaths(root, nil, ".")
+	return root, nil
+}
+
+// parseJSONData parses JSON preserving object key order
+func parseJSONData(data []byte) (*dataNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeJSONValue(dec, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level value")
+	}
+	return root, nil
+}
+
+func decodeJSONValue(dec *json.Decoder, depth int) (*dataNode, error) {
+	if depth > maxDataDepth {
+		return nil, errors.New("document nested too deeply")
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		node := &dataNode{Kind: dataObject}
+		if v == '[' {
+			node.Kind = dataArray
+		}
+		for dec.More() {
+			key := ""
+			if node.Kind == dataObject {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyTok.(string)
+			}
+			child, err := decodeJSONValue(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			child.Key = key
+			node.Children = append(node.Children, child)
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &dataNode{Kind: dataString, Text: strconv.Quote(v)}, nil
+	case json.Number:
+		return &dataNode{Kind: dataNumber, Text: v.String()}, nil
+	case bool:
+		return &dataNode{Kind: dataBool, Text: strconv.FormatBool(v)}, nil
+	default:
+		return &dataNode{Kind: dataNull, Text: "null"}, nil
+	}
+}
+
+// parseYAMLData parses the first YAML document preserving key order
+func parseYAMLData(data []byte) (*dataNode, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &dataNode{Kind: dataNull, Text: "null"}, nil
+	}
+	return yamlToData(doc.Content[0], 0)
+}
+
+func yamlToData(n *yaml.Node, depth int) (*dataNode, error) {
+	if depth > maxDataDepth {
+		return nil, errors.New("document nested too deeply")
+	}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlToData(n.Alias, depth+1)
+	case yaml.MappingNode:
+		node := &dataNode{Kind: dataObject}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			child, err := yamlToData(n.Content[i+1], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			child.Key = n.Content[i].Value
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	case yaml.SequenceNode:
+		node := &dataNode{Kind: dataArray}
+		for _, item := range n.Content {
+			child, err := yamlToData(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	}
+
+	switch n.ShortTag() {
+	case "!!int", "!!float":
+		return &dataNode{Kind: dataNumber, Text: n.Value}, nil
+	case "!!bool":
+		return &dataNode{Kind: dataBool, Text: n.Value}, nil
+	case "!!null":
+		return &dataNode{Kind: dataNull, Text: "null"}, nil
+	default:
+		return &dataNode{Kind: dataString, Text: strconv.Quote(n.Value)}, nil
+	}
+}
+
+// parseTOMLData parses TOML, restoring key order from the decoder metadata
+func parseTOMLData(data []byte) (*dataNode, error) {
+	var v map[string]any
+	md, err := toml.Decode(string(data), &v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Child key order per table, keyed by the table's key path (array indices omitted)
+	order := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, key := range md.Keys() {
+		full := strings.Join(key, "\x00")
+		if seen[full] {
+			continue
+		}
+		seen[full] = true
+		parent := strings.Join(key[:len(key)-1], "\x00")
+		order[parent] = append(order[parent], key[len(key)-1])
+	}
+
+	return tomlToData(v, "", order, 0)
+}
+
+func tomlToData(v any, keyPath string, order map[string][]string, depth int) (*dataNode, error) {
+	if depth > maxDataDepth {
+		return nil, errors.New("document nested too deeply")
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		node := &dataNode{Kind: dataObject}
+		for _, key := range orderedKeys(val, order[keyPath]) {
+			childPath := key
+			if keyPath != "" {
+				childPath = keyPath + "\x00" + key
+			}
+			child, err := tomlToData(val[key], childPath, order, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			child.Key = key
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	case []map[string]any:
+		node := &dataNode{Kind: dataArray}
+		for _, item := range val {
+			child, err := tomlToData(item, keyPath, order, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	case []any:
+		node := &dataNode{Kind: dataArray}
+		for _, item := range val {
+			child, err := tomlToData(item, keyPath, order, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	case string:
+		return &dataNode{Kind: dataString, Text: strconv.Quote(val)}, nil
+	case bool:
+		return &dataNode{Kind: dataBool, Text: strconv.FormatBool(val)}, nil
+	default:
+		// Numbers and date/time values
+		return &dataNode{Kind: dataNumber, Text: fmt.Sprint(val)}, nil
+	}
+}
+
+// orderedKeys returns map keys in the given order, followed by any others sorted
+func orderedKeys(m map[string]any, order []string) []string {
+	keys := make([]string, 0, len(m))
+	used := make(map[string]bool, len(m))
+	for _, k := range order {
+		if _, ok := m[k]; ok && !used[k] {
+			keys = append(keys, k)
+			used[k] = true
+		}
+	}
+	var rest []string
+	for k := range m {
+		if !used[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+var dataIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// assignDataPaths sets parent links, array item labels and jq-like paths
+func assignDataPaths(n, parent *dataNode, path string) {
+	n.Parent = parent
+	n.Path = path
+	for i, child := range n.Children {
+		var childPath string
+		if n.Kind == dataArray {
+			child.Key = fmt.Sprintf("[%d]", i)
+			childPath = joinDataPath(path, child.Key)
+		} else if dataIdentRe.MatchString(child.Key) {
+			childPath = joinDataPath(path, "."+child.Key)
+		} else {
+			childPath = joinDataPath(path, "["+strconv.Quote(child.Key)+"]")
+		}
+		assignDataPaths(child, n, childPath)
+	}
+}
+
+// joinDataPath appends a path segment ("." + key or "[...]") to a path
+func joinDataPath(path, segment string) string {
+	if path == "." {
+		if strings.HasPrefix(segment, ".") {
+			return segment
+		}
+		return "." + segment
+	}
+	return path + segment
+}
+
+// expandDataTree expands containers shallower than depth and collapses the rest
+func expandDataTree(n *dataNode, depth int) {
+	n.Collapsed = depth <= 0
+	for _, child := range n.Children {
+		expandDataTree(child, depth-1)
+	}
+}
+
+// setDataCollapsed sets the collapsed state of a node and all descendants
+func setDataCollapsed(n *dataNode, collapsed bool) {
+	n.Collapsed = collapsed
+	for _, child := range n.Children {
+		setDataCollapsed(child, collapsed)
+	}
+}
+
+// Path queries
+
+// dataQueryStep is one step of a path query
+type dataQueryStep struct {
+	key     string
+	index   int
+	isIndex bool
+	iterate bool
+}
+
+// parseDataQuery parses a jq-like path: ., .key, ."key", .["key"], [0], [-1], []
+func parseDataQuery(query string) ([]dataQueryStep, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || query == "." {
+		return nil, nil
+	}
+	if query[0] != '.' && query[0] != '[' {
+		return nil, errors.New("query must start with '.'")
+	}
+
+	var steps []dataQueryStep
+	i := 0
+	for i < len(query) {
+		switch query[i] {
+		case '.':
+			i++
+			if i >= len(query) || query[i] == '[' {
+				continue
+			}
+			if query[i] == '"' {
+				key, n, err := parseQueryString(query[i:])
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, dataQueryStep{key: key})
+				i += n
+				continue
+			}
+			start := i
+			for i < len(query) && (isIdentChar(query[i]) || query[i] == '-') {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unexpected %q at %d", query[i], i)
+			}
+			steps = append(steps, dataQueryStep{key: query[start:i]})
+		case '[':
+			i++
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				return nil, errors.New("missing ']'")
+			}
+			inner := strings.TrimSpace(query[i : i+end])
+			switch {
+			case inner == "":
+				steps = append(steps, dataQueryStep{iterate: true})
+			case inner[0] == '"':
+				key, n, err := parseQueryString(inner)
+				if err != nil || n != len(inner) {
+					return nil, fmt.Errorf("invalid key %s", inner)
+				}
+				steps = append(steps, dataQueryStep{key: key})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %s", inner)
+				}
+				steps = append(steps, dataQueryStep{index: index, isIndex: true})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", query[i], i)
+		}
+	}
+	return steps, nil
+}
+
+// parseQueryString parses a leading quoted string, returning its value and length
+func parseQueryString(s string) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '"' {
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return value, i + 1, nil
+		}
+	}
+	return "", 0, errors.New("unterminated string")
+}
+
+// evalDataQuery returns the nodes matched by the query steps.
+// Steps that don't apply to a node (e.g., a key on an array) drop it.
+func evalDataQuery(root *dataNode, steps []dataQueryStep) []*dataNode {
+	current := []*dataNode{root}
+	for _, step := range steps {
+		var next []*dataNode
+		for _, n := range current {
+			switch {
+			case step.iterate:
+				next = append(next, n.Children...)
+			case step.isIndex:
+				if n.Kind != dataArray {
+					continue
+				}
+				index := step.index
+				if index < 0 {
+					index += len(n.Children)
+				}
+				if index >= 0 && index < len(n.Children) {
+					next = append(next, n.Children[index])
+				}
+			default:
+				if n.Kind != dataObject {
+					continue
+				}
+				for _, child := range n.Children {
+					if child.Key == step.key {
+						next = append(next, child)
+						break
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// Layout
+
+// renderDataTree lays out the visible nodes of the tree.
+// rows maps each output line to its node. Roots are labeled by path when labelPaths is set.
+func renderDataTree(roots []*dataNode, labelPaths bool) (lines []string, spans [][]textSpan, rows []*dataNode) {
+	var walk func(n *dataNode, label string, depth int)
+	walk = func(n *dataNode, label string, depth int) {
+		var b strings.Builder
+		var s []textSpan
+
+		b.WriteString(strings.Repeat("  ", depth))
+		switch {
+		case !n.isContainer() || len(n.Children) == 0:
+			b.WriteString("  ")
+		case n.Collapsed:
+			start := b.Len()
+			b.WriteString("▸ ")
+			s = append(s, textSpan{Start: start, End: b.Len(), Style: tocStyle})
+		default:
+			start := b.Len()
+			b.WriteString("▾ ")
+			s = append(s, textSpan{Start: start, End: b.Len(), Style: tocStyle})
+		}
+
+		if label != "" {
+			start := b.Len()
+			b.WriteString(label)
+			s = append(s, textSpan{Start: start, End: b.Len(), Style: syntaxKeyStyle})
+			b.WriteString(": ")
+		}
+
+		start := b.Len()
+		if n.isContainer() {
+			b.WriteString(n.summary())
+			s = append(s, textSpan{Start: start, End: b.Len(), Style: syntaxCommentStyle})
+		} else {
+			b.WriteString(n.Text)
+			s = append(s, textSpan{Start: start, End: b.Len(), Style: dataValueStyle(n.Kind)})
+		}
+
+		lines = append(lines, b.String())
+		spans = append(spans, s)
+		rows = append(rows, n)
+
+		if n.isContainer() && !n.Collapsed {
+			for _, child := range n.Children {
+				walk(child, child.Key, depth+1)
+			}
+		}
+	}
+
+	for _, root := range roots {
+		label := root.Key
+		if labelPaths {
+			label = root.Path
+		}
+		walk(root, label, 0)
+	}
+	return lines, spans, rows
+}
+
+// dataValueStyle returns the style for a scalar value
+func dataValueStyle(kind dataKind) lipgloss.Style {
+	switch kind {
+	case dataString:
+		return syntaxStringStyle
+	case dataNumber:
+		return syntaxNumberStyle
+	default:
+		return syntaxBuiltinStyle
+	}
+}
+
+// Tables
+
+// parseTableData parses CSV (or TSV by extension) into records
+func parseTableData(path string, data []byte) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty table")
+	}
+	return records, nil
+}
+
+// tableSeparator separates table columns
+const tableSeparator = " │ "
+
+// fitTableColumns returns column widths for records, shrinking the widest
+// columns until the table fits width (or every column is at the minimum)
+func fitTableColumns(records [][]string, width int) []int {
+	cols := tableColumnCount(records)
+	widths := make([]int, cols)
+	for _, rec := range records {
+		for c, cell := range rec {
+			widths[c] = max(widths[c], ansi.StringWidth(cleanTableCell(cell)))
+		}
+	}
+	total := 0
+	for c := range widths {
+		widths[c] = min(max(widths[c], 1), MaxTableColumnWidth)
+		total += widths[c]
+	}
+	total += (cols - 1) * ansi.StringWidth(tableSeparator)
+
+	for total > width {
+		widest := -1
+		for c, w := range widths {
+			if w > MinTableColumnWidth && (widest < 0 || w > widths[widest]) {
+				widest = c
+			}
+		}
+		if widest < 0 {
+			break // Can't shrink further; horizontal scroll takes over
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// tableColumnCount returns the number of columns in the widest record
+func tableColumnCount(records [][]string) int {
+	cols := 0
+	for _, rec := range records {
+		cols = max(cols, len(rec))
+	}
+	return cols
+}
+
+// layoutTable renders records as an aligned table.
+// The first record becomes the header (with a rule below it), returned separately so it can stay frozen.
+func layoutTable(records [][]string, width int) (header []string, headerSpans [][]textSpan, lines []string, spans [][]textSpan) {
+	widths := fitTableColumns(records, width)
+
+	// Right-align columns where every body cell is numeric
+	numeric := make([]bool, len(widths))
+	for c := range widths {
+		numeric[c] = len(records) > 1
+		for _, rec := range records[1:] {
+			if c < len(rec) && rec[c] != "" {
+				if _, err := strconv.ParseFloat(strings.TrimSpace(rec[c]), 64); err != nil {
+					numeric[c] = false
+					break
+				}
+			}
+		}
+	}
+
+	row := func(rec []string, cellStyle *lipgloss.Style) (string, []textSpan) {
+		var b strings.Builder
+		var s []textSpan
+		for c, w := range widths {
+			if c > 0 {
+				start := b.Len()
+				b.WriteString(tableSeparator)
+				s = append(s, textSpan{Start: start, End: b.Len(), Style: mdRuleStyle})
+			}
+			cell := ""
+			if c < len(rec) {
+				cell = cleanTableCell(rec[c])
+			}
+			if ansi.StringWidth(cell) > w {
+				cell = ansi.Truncate(cell, w-1, "") + "…"
+			}
+			pad := strings.Repeat(" ", w-ansi.StringWidth(cell))
+			if numeric[c] {
+				b.WriteString(pad)
+			}
+			start := b.Len()
+			b.WriteString(cell)
+			if cellStyle != nil {
+				s = append(s, textSpan{Start: start, End: b.Len(), Style: *cellStyle})
+			}
+			if !numeric[c] {
+				b.WriteString(pad)
+			}
+		}
+		return strings.TrimRight(b.String(), " "), s
+	}
+
+	line, s := row(records[0], &mdTableHeaderStyle)
+	header = append(header, line)
+	headerSpans = append(headerSpans, s)
+
+	rule := make([]string, len(widths))
+	for c, w := range widths {
+		rule[c] = strings.Repeat("─", w)
+	}
+	ruleLine := strings.Join(rule, "─┼─")
+	header = append(header, ruleLine)
+	headerSpans = append(headerSpans, []textSpan{{Start: 0, End: len(ruleLine), Style: mdRuleStyle}})
+
+	for _, rec := range records[1:] {
+		line, s := row(rec, nil)
+		lines = append(lines, line)
+		spans = append(spans, s)
+	}
+	return header, headerSpans, lines, spans
+}
+
+// cleanTableCell flattens a cell to a single line
+func cleanTableCell(cell string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(cell)
+}
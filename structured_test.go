@@ -0,0 +1,336 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestPreviewFormatFor(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected previewFormat
+	}{
+		{"README.md", formatMarkdown},
+		{"package.json", formatData},
+		{"ci.yml", formatData},
+		{"Cargo.toml", formatData},
+		{"data.csv", formatTable},
+		{"data.TSV", formatTable},
+		{"main.go", formatNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := previewFormatFor(tt.path); got != tt.expected {
+				t.Errorf("previewFormatFor(%q) = %v, expected %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+// dataKeys returns the keys of a node's children in order
+func dataKeys(n *dataNode) string {
+	var keys []string
+	for _, child := range n.Children {
+		keys = append(keys, child.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestParseDataFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+	}{
+		{"json", "a.json", `{"zeta": 1, "alpha": {"x": [true, null]}, "my key": "v"}`},
+		{"yaml", "a.yaml", "zeta: 1\nalpha:\n  x:\n    - true\n    - null\nmy key: v\n"},
+		{"toml", "a.toml", "zeta = 1\n\"my key\" = \"v\"\n[alpha]\nx = [true, false]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseDataFile(tt.path, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if root.Kind != dataObject || len(root.Children) != 3 {
+				t.Fatalf("Expected object with 3 keys, got kind %v with %d children", root.Kind, len(root.Children))
+			}
+			// Source key order is preserved
+			if root.Children[0].Key != "zeta" {
+				t.Errorf("Expected first key zeta, got %q", dataKeys(root))
+			}
+
+			results := evalDataQuery(root, mustParseQuery(t, ".alpha.x[0]"))
+			if len(results) != 1 || results[0].Text != "true" {
+				t.Fatalf("Expected .alpha.x[0] = true, got %+v", results)
+			}
+			if results[0].Path != ".alpha.x[0]" {
+				t.Errorf("Path = %q, expected %q", results[0].Path, ".alpha.x[0]")
+			}
+
+			quoted := evalDataQuery(root, mustParseQuery(t, `.["my key"]`))
+			if len(quoted) != 1 || quoted[0].Text != `"v"` {
+				t.Errorf("Expected quoted key lookup to find \"v\", got %+v", quoted)
+			}
+		})
+	}
+}
+
+func TestParseDataFile_Invalid(t *testing.T) {
+	for _, path := range []string{"a.json", "a.yaml", "a.toml"} {
+		if _, err := parseDataFile(path, []byte("{[: = ]")); err == nil {
+			t.Errorf("Expected error for invalid %s", path)
+		}
+	}
+}
+
+func mustParseQuery(t *testing.T, query string) []dataQueryStep {
+	t.Helper()
+	steps, err := parseDataQuery(query)
+	if err != nil {
+		t.Fatalf("parseDataQuery(%q): %v", query, err)
+	}
+	return steps
+}
+
+func TestDataQuery(t *testing.T) {
+	root, err := parseDataFile("a.json", []byte(`{"items": [{"name": "a"}, {"name": "b"}, {"id": 3}], "n": 1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"", 1},
+		{".", 1},
+		{".items", 1},
+		{".items[]", 3},
+		{".items[].name", 2},
+		{".items[-1].id", 1},
+		{".items[5]", 0},
+		{".n.x", 0},
+		{`."items"[0]`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := evalDataQuery(root, mustParseQuery(t, tt.query))
+			if len(results) != tt.expected {
+				t.Errorf("query %q = %d results, expected %d", tt.query, len(results), tt.expected)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"items", ".items[", ".items[x]", `.["a]`} {
+		if _, err := parseDataQuery(invalid); err == nil {
+			t.Errorf("Expected error for query %q", invalid)
+		}
+	}
+}
+
+func TestRenderDataTree_Folding(t *testing.T) {
+	root, _ := parseDataFile("a.json", []byte(`{"a": {"b": 1}, "c": [1, 2]}`))
+
+	lines, spans, rows := renderDataTree([]*dataNode{root}, false)
+	if len(lines) != 6 || len(spans) != 6 || len(rows) != 6 {
+		t.Fatalf("Expected 6 rows for expanded tree, got %d: %v", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "{2 keys}") {
+		t.Errorf("Expected root summary, got %q", lines[0])
+	}
+
+	root.Children[0].Collapsed = true
+	lines, _, _ = renderDataTree([]*dataNode{root}, false)
+	if len(lines) != 5 {
+		t.Errorf("Expected 5 rows with a collapsed, got %d: %v", len(lines), lines)
+	}
+	if !strings.Contains(lines[1], "▸ a: {1 key}") {
+		t.Errorf("Expected collapsed marker, got %q", lines[1])
+	}
+}
+
+func TestLayoutTable(t *testing.T) {
+	records := [][]string{
+		{"name", "count"},
+		{"apple", "3"},
+		{"banana", "12"},
+	}
+
+	header, headerSpans, lines, spans := layoutTable(records, 80)
+	if len(header) != 2 || len(headerSpans) != 2 {
+		t.Fatalf("Expected header and rule, got %v", header)
+	}
+	if header[0] != "name   │ count" {
+		t.Errorf("Header = %q", header[0])
+	}
+	if len(lines) != 2 || len(spans) != 2 {
+		t.Fatalf("Expected 2 body rows, got %v", lines)
+	}
+	// Numeric column is right-aligned
+	if lines[0] != "apple  │     3" || lines[1] != "banana │    12" {
+		t.Errorf("Unexpected rows: %q", lines)
+	}
+}
+
+func TestFitTableColumns(t *testing.T) {
+	records := [][]string{{strings.Repeat("a", 30), strings.Repeat("b", 30), "c"}}
+
+	widths := fitTableColumns(records, 40)
+	total := widths[0] + widths[1] + widths[2] + 2*len([]rune(tableSeparator))
+	if total > 40 {
+		t.Errorf("Expected table to fit 40 columns, got %d (%v)", total, widths)
+	}
+	if widths[2] != 1 {
+		t.Errorf("Narrow column should keep its width, got %d", widths[2])
+	}
+
+	// Too narrow to fit: columns stop at the minimum width
+	widths = fitTableColumns(records, 5)
+	if widths[0] != MinTableColumnWidth || widths[1] != MinTableColumnWidth {
+		t.Errorf("Expected minimum widths, got %v", widths)
+	}
+}
+
+func TestPreview_DataTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "config.json"), []byte(`{"server": {"host": "localhost", "port": 8080}, "debug": true}`), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.width = 80
+	model.height = 20
+	model.selected = 1
+	model.openPreview()
+
+	// Data files open in the tree view
+	if !model.isDataTreePreview() {
+		t.Fatal("Expected tree view for JSON file")
+	}
+	if len(model.previewContent) != 5 {
+		t.Fatalf("Expected 5 rows, got %v", model.previewContent)
+	}
+
+	// j moves the cursor, h collapses the node under it
+	newModel, _ := model.Update(keyMsg("j"))
+	m := newModel.(Model)
+	if m.previewDataNode().Path != ".server" {
+		t.Fatalf("Expected cursor on .server, got %q", m.previewDataNode().Path)
+	}
+	newModel, _ = m.Update(keyMsg("h"))
+	m = newModel.(Model)
+	if len(m.previewContent) != 3 {
+		t.Errorf("Expected 3 rows after collapsing .server, got %v", m.previewContent)
+	}
+
+	// : opens the query line; typing filters the tree
+	newModel, _ = m.Update(keyMsg(":"))
+	m = newModel.(Model)
+	if m.inputMode != ModePreviewQuery {
+		t.Fatalf("Expected ModePreviewQuery, got %v", m.inputMode)
+	}
+	for _, ch := range ".server.port" {
+		newModel, _ = m.Update(keyMsg(string(ch)))
+		m = newModel.(Model)
+	}
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	m = newModel.(Model)
+	if len(m.previewContent) != 1 || !strings.Contains(m.previewContent[0], "8080") {
+		t.Errorf("Expected query result .server.port, got %v", m.previewContent)
+	}
+
+	// Esc clears the query before closing
+	newModel, _ = m.Update(specialKeyMsg(tea.KeyEscape))
+	m = newModel.(Model)
+	if m.inputMode != ModePreview || m.hasPreviewQuery() {
+		t.Errorf("Expected query cleared and preview open, got mode %v query %q", m.inputMode, m.previewQuery)
+	}
+
+	// m shows the raw text
+	newModel, _ = m.Update(keyMsg("m"))
+	m = newModel.(Model)
+	if m.previewRendered || !strings.Contains(m.previewContent[0], `"server"`) {
+		t.Errorf("Expected raw view after m, got %v", m.previewContent)
+	}
+}
+
+func TestPreview_DataTreeInvalidFallsBackToRaw(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "broken.json"), []byte(`{"a": `), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.selected = 1
+	model.openPreview()
+	if model.previewRendered {
+		t.Error("Expected raw view for invalid JSON")
+	}
+	if model.inputMode != ModePreview {
+		t.Errorf("Expected preview to open, got %v", model.inputMode)
+	}
+}
+
+func TestPreview_TableFrozenHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+	var rows []string
+	rows = append(rows, "id,name")
+	for i := 0; i < 50; i++ {
+		rows = append(rows, "1,row")
+	}
+	os.WriteFile(filepath.Join(tmpDir, "data.csv"), []byte(strings.Join(rows, "\n")), 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.width = 80
+	model.height = 20
+	model.selected = 1
+	model.openPreview()
+	if model.previewFormat != formatTable {
+		t.Fatal("Expected table view for CSV file")
+	}
+	if len(model.previewContent) != 50 {
+		t.Errorf("Expected 50 body rows, got %d", len(model.previewContent))
+	}
+
+	// Header stays visible after scrolling
+	newModel, _ := model.Update(keyMsg("G"))
+	m := newModel.(Model)
+	if m.previewScroll == 0 {
+		t.Fatal("Expected scroll after G")
+	}
+	lines := strings.Split(m.renderPreview(), "\n")
+	if !strings.Contains(lines[1], "id") || !strings.Contains(lines[1], "name") {
+		t.Errorf("Expected frozen header on first content line, got %q", lines[1])
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Text encoding and line-ending detection for preview.
+// Detection looks at a byte order mark first, then at the byte patterns of
+// the head of the file; anything that is not valid UTF-8 or a Japanese
+// multibyte encoding is shown as Latin-1, which never fails to decode.
+
+// encodingSampleBytes is how much of a file is inspected to detect its encoding
+const encodingSampleBytes = 8192
+
+// textEncoding is a detected text encoding
+type textEncoding struct {
+	Name    string            // Display name (e.g., "UTF-16LE")
+	decoder encoding.Encoding // nil = UTF-8, no decoding needed
+	bom     int               // Length of the byte order mark to strip
+}
+
+var (
+	encodingUTF8    = textEncoding{Name: "UTF-8"}
+	encodingUTF8BOM = textEncoding{Name: "UTF-8 BOM", bom: 3}
+	encodingUTF16LE = textEncoding{Name: "UTF-16LE", decoder: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)}
+	encodingUTF16BE = textEncoding{Name: "UTF-16BE", decoder: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)}
+	encodingSJIS    = textEncoding{Name: "Shift_JIS", decoder: japanese.ShiftJIS}
+	encodingEUCJP   = textEncoding{Name: "EUC-JP", decoder: japanese.EUCJP}
+	encodingLatin1  = textEncoding{Name: "Latin-1", decoder: charmap.ISO8859_1}
+)
+
+// detectEncoding guesses the encoding of data from its BOM or byte patterns
+func detectEncoding(data []byte) textEncoding {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return encodingUTF8BOM
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		e := encodingUTF16LE
+		e.bom = 2
+		return e
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		e := encodingUTF16BE
+		e.bom = 2
+		return e
+	}
+
+	sample := data
+	if len(sample) > encodingSampleBytes {
+		sample = sample[:encodingSampleBytes]
+	}
+	if e, ok := detectUTF16(sample); ok {
+		return e
+	}
+
+	if validUTF8Prefix(sample) {
+		return encodingUTF8
+	}
+
+	euc, eucMulti := scanEUCJP(sample)
+	sjis, sjisMulti := scanShiftJIS(sample)
+	switch {
+	case euc && sjis:
+		// Both parse: EUC-JP read as Shift_JIS is mostly single-byte katakana,
+		// so the reading with more double-byte characters wins
+		if eucMulti >= sjisMulti {
+			return encodingEUCJP
+		}
+		return encodingSJIS
+	case euc && eucMulti > 0:
+		return encodingEUCJP
+	case sjis && sjisMulti > 0:
+		return encodingSJIS
+	}
+	return encodingLatin1
+}
+
+// validUTF8Prefix reports whether data is UTF-8, allowing a rune cut off at the end
+func validUTF8Prefix(data []byte) bool {
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return !utf8.FullRune(data) && len(data) < utf8.UTFMax
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// detectUTF16 recognizes BOM-less UTF-16 by the zero bytes of ASCII characters
+func detectUTF16(sample []byte) (textEncoding, bool) {
+	pairs := len(sample) / 2
+	if pairs < 2 {
+		return textEncoding{}, false
+	}
+	// Count ASCII characters (printable byte next to a zero byte) in either byte order
+	le, be := 0, 0
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i+1] == 0 && isTextByte(sample[i]) {
+			le++
+		}
+		if sample[i] == 0 && isTextByte(sample[i+1]) {
+			be++
+		}
+	}
+	switch {
+	case le*10 >= pairs*4 && be*10 < pairs:
+		return encodingUTF16LE, true
+	case be*10 >= pairs*4 && le*10 < pairs:
+		return encodingUTF16BE, true
+	}
+	return textEncoding{}, false
+}
+
+// scanEUCJP reports whether data is valid EUC-JP and how many multibyte characters it has
+func scanEUCJP(data []byte) (valid bool, multi int) {
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			i++
+			continue
+		case b == 0x8e: // Half-width katakana
+			if i+1 >= len(data) {
+				return true, multi
+			}
+			if data[i+1] < 0xa1 || data[i+1] > 0xdf {
+				return false, multi
+			}
+			i += 2
+		case b == 0x8f: // JIS X 0212
+			if i+2 >= len(data) {
+				return true, multi
+			}
+			if !isEUCByte(data[i+1]) || !isEUCByte(data[i+2]) {
+				return false, multi
+			}
+			i += 3
+		case isEUCByte(b):
+			if i+1 >= len(data) {
+				return true, multi
+			}
+			if !isEUCByte(data[i+1]) {
+				return false, multi
+			}
+			i += 2
+		default:
+			return false, multi
+		}
+		multi++
+	}
+	return true, multi
+}
+
+func isTextByte(b byte) bool {
+	return (b >= 0x20 && b < 0x7f) || b == '\n' || b == '\r' || b == '\t'
+}
+
+func isEUCByte(b byte) bool {
+	return b >= 0xa1 && b <= 0xfe
+}
+
+// scanShiftJIS reports whether data is valid Shift_JIS and how many double-byte characters it has
+func scanShiftJIS(data []byte) (valid bool, multi int) {
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b < 0x80 || (b >= 0xa1 && b <= 0xdf): // ASCII, half-width katakana
+			i++
+			continue
+		case (b >= 0x81 && b <= 0x9f) || (b >= 0xe0 && b <= 0xfc):
+			if i+1 >= len(data) {
+				return true, multi
+			}
+			t := data[i+1]
+			if t < 0x40 || t == 0x7f || t > 0xfc {
+				return false, multi
+			}
+			i += 2
+		default:
+			return false, multi
+		}
+		multi++
+	}
+	return true, multi
+}
+
+// looksLikeUTF16 reports whether content is UTF-16 text (so not binary despite its zero bytes)
+func looksLikeUTF16(content []byte) bool {
+	if bytes.HasPrefix(content, []byte{0xff, 0xfe}) || bytes.HasPrefix(content, []byte{0xfe, 0xff}) {
+		return true
+	}
+	_, ok := detectUTF16(content[:min(len(content), encodingSampleBytes)])
+	return ok
+}
+
+// decodeText converts content in the detected encoding to UTF-8
+func decodeText(content []byte) (string, textEncoding) {
+	enc := detectEncoding(content)
+	content = content[enc.bom:]
+	if enc.decoder == nil {
+		return string(content), enc
+	}
+	decoded, err := enc.decoder.NewDecoder().Bytes(content)
+	if err != nil {
+		// Fall back to the raw bytes rather than showing nothing
+		return string(content), encodingLatin1
+	}
+	return string(decoded), enc
+}
+
+// textInfo describes the layout of a text file for the preview status line
+type textInfo struct {
+	Encoding     string
+	LineEnding   string // "LF", "CRLF", "mixed" or "" (single line)
+	FinalNewline bool   // Text ends with a line break
+	Indent       string // "tabs", "N spaces", "mixed" or "" (no indentation)
+}
+
+// analyzeText detects line endings and indentation of decoded text
+func analyzeText(text string, enc textEncoding) textInfo {
+	info := textInfo{Encoding: enc.Name, FinalNewline: text == "" || strings.HasSuffix(text, "\n")}
+
+	crlf := strings.Count(text, "\r\n")
+	lf := strings.Count(text, "\n") - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		info.LineEnding = "mixed"
+	case crlf > 0:
+		info.LineEnding = "CRLF"
+	case lf > 0:
+		info.LineEnding = "LF"
+	}
+
+	tabs, spaces, width := 0, 0, 0
+	for line := range strings.SplitSeq(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		switch line[0] {
+		case '\t':
+			tabs++
+		case ' ':
+			n := len(line) - len(strings.TrimLeft(line, " "))
+			// A single space is usually alignment (e.g., " * " in block comments)
+			if n < 2 {
+				continue
+			}
+			spaces++
+			if width == 0 || n < width {
+				width = n
+			}
+		}
+	}
+	switch {
+	case tabs == 0 && spaces == 0:
+	case spaces*10 <= tabs:
+		info.Indent = "tabs"
+	case tabs*10 <= spaces:
+		info.Indent = fmt.Sprintf("%d spaces", width)
+	default:
+		info.Indent = "mixed"
+	}
+	return info
+}
+
+// String formats the info for the status line, e.g. "UTF-8 LF tabs [noeol]"
+func (t textInfo) String() string {
+	parts := []string{t.Encoding}
+	if t.LineEnding != "" {
+		parts = append(parts, t.LineEnding)
+	}
+	if t.Indent != "" {
+		parts = append(parts, t.Indent)
+	}
+	if !t.FinalNewline {
+		parts = append(parts, "[noeol]")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func encodeText(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return b
+}
+
+func TestDetectEncoding(t *testing.T) {
+	japaneseText := "こんにちは、世界。日本語のテキストです。\n"
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"ascii", []byte("hello\n"), "UTF-8"},
+		{"utf-8", []byte("héllo 世界\n"), "UTF-8"},
+		{"utf-8 bom", append([]byte{0xef, 0xbb, 0xbf}, "hi"...), "UTF-8 BOM"},
+		{"utf-16le bom", encodeText(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "hello\n"), "UTF-16LE"},
+		{"utf-16be bom", encodeText(t, unicode.UTF16(unicode.BigEndian, unicode.UseBOM), "hello\n"), "UTF-16BE"},
+		{"utf-16le without bom", encodeText(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "hello world\n"), "UTF-16LE"},
+		{"shift_jis", encodeText(t, japanese.ShiftJIS, japaneseText), "Shift_JIS"},
+		{"euc-jp", encodeText(t, japanese.EUCJP, japaneseText), "EUC-JP"},
+		{"latin-1", []byte("caf\xe9 cr\xe8me\n"), "Latin-1"},
+		{"utf-8 cut in the middle of a rune", []byte("ab\xe4\xb8"), "UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEncoding(tt.data).Name; got != tt.expected {
+				t.Errorf("detectEncoding = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	data := encodeText(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "line1\r\nline2\r\n")
+	if isBinaryContent(data) {
+		t.Error("Expected UTF-16 text not to be treated as binary")
+	}
+	text, enc := decodeText(data)
+	if text != "line1\r\nline2\r\n" || enc.Name != "UTF-16LE" {
+		t.Errorf("decodeText = %q (%s)", text, enc.Name)
+	}
+
+	text, _ = decodeText(encodeText(t, japanese.ShiftJIS, "日本語\n"))
+	if text != "日本語\n" {
+		t.Errorf("Shift_JIS decoded to %q", text)
+	}
+}
+
+func TestAnalyzeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"lf tabs", "func f() {\n\treturn\n}\n", "UTF-8 LF tabs"},
+		{"crlf spaces", "a:\r\n  b: 1\r\n    c: 2\r\n", "UTF-8 CRLF 2 spaces"},
+		{"mixed endings", "a\r\nb\nc\n", "UTF-8 mixed"},
+		{"no final newline", "a\nb", "UTF-8 LF [noeol]"},
+		{"mixed indentation", "a\n\tb\n\tc\n    d\n    e\n", "UTF-8 LF mixed"},
+		{"comment alignment ignored", "/*\n * x\n */\n\tcode\n", "UTF-8 LF tabs"},
+		{"empty", "", "UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := analyzeText(tt.text, encodingUTF8).String(); got != tt.expected {
+				t.Errorf("analyzeText = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPreview_EncodingStatus(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := encodeText(t, japanese.ShiftJIS, "こんにちは\r\n  世界\r\n")
+	os.WriteFile(filepath.Join(tmpDir, "sjis.txt"), data, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.width = 120
+	model.selected = 1
+	model.openPreview()
+
+	if model.previewIsBinary || model.previewContent[0] != "こんにちは\r" {
+		t.Fatalf("Expected decoded Shift_JIS text, got %q", model.previewContent)
+	}
+	if status := model.previewTextStatus(); status != " | Shift_JIS CRLF 2 spaces" {
+		t.Errorf("previewTextStatus = %q", status)
+	}
+	if !strings.Contains(model.renderPreview(), "Shift_JIS CRLF") {
+		t.Error("Expected encoding in the preview status bar")
+	}
+
+	model.closePreview()
+	if model.previewTextStatus() != "" {
+		t.Error("Expected text info cleared on close")
+	}
+}
@@ -11,6 +11,36 @@ import (
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Snapshotting copies and sorts the marks, so only do it for subscribers
+	var prevSelection controlSelection
+	subscribed := m.control != nil && m.tree != nil && m.control.HasSubscribers()
+	if subscribed {
+		prevSelection = m.controlSelection()
+	}
+
+	next, cmd := m.update(msg)
+
+	// Tell bon3 ctl subscribers where the selection went
+	if nm, ok := next.(Model); ok && subscribed && nm.control != nil {
+		nm.publishSelection(prevSelection)
+	}
+
+	// Tell the terminal about root changes (OSC 7), wherever they came from
+	if nm, ok := next.(Model); ok && m.tree != nil && nm.tree != nil && nm.tree.Root.Path != m.tree.Root.Path {
+		cmd = tea.Batch(cmd, tea.Raw(cwdSequence(nm.tree.Root.Path)))
+	}
+
+	// The split pane follows the selection, whatever moved it
+	if nm, ok := next.(Model); ok && nm.splitPane {
+		if splitCmd := nm.syncSplitPreview(); splitCmd != nil {
+			return nm, tea.Batch(cmd, splitCmd)
+		}
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.PasteMsg:
 		// Handle paste (drag & drop sends text as paste)
@@ -22,12 +52,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.inputMode {
 		case ModeNormal:
 			return m.updateNormalMode(msg)
-		case ModeSearch, ModeRename, ModeNewFile, ModeNewDir, ModeGoTo:
+		case ModeSearch, ModeRename, ModeNewFile, ModeNewDir, ModeGoTo, ModeNewArchive:
 			return m.updateInputMode(msg)
 		case ModeConfirmDelete:
 			return m.updateConfirmMode(msg)
 		case ModePreview:
 			return m.updatePreviewMode(msg)
+		case ModePreviewSearch:
+			return m.updatePreviewSearchMode(msg)
+		case ModePreviewQuery:
+			return m.updatePreviewQueryMode(msg)
+		case ModePreviewJump:
+			return m.updatePreviewJumpMode(msg)
+		case ModeShell:
+			return m.updateShellMode(msg)
+		case ModeShellOutput:
+			return m.updateShellOutputMode(msg)
+		case ModeAgentPick:
+			return m.updateAgentPickMode(msg)
+		case ModeRecent:
+			return m.updateRecentMode(msg)
 		}
 
 	case tea.MouseWheelMsg:
@@ -39,24 +83,69 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.inputMode == ModeNormal {
 			return m.updateMouseClickEvent(msg)
 		}
+		if m.inputMode == ModePreview {
+			return m.updatePreviewMouseClick(msg)
+		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.centerPending {
+			// A path revealed on startup is centered once the height is known
+			m.centerSelection()
+			m.centerPending = false
+		}
+		m.rerenderPreview()
+		if m.previewStream != nil {
+			m.loadStreamWindow()
+		}
+		return m, m.redrawImagePreview()
 
 	case tickMsg:
 		m.checkDropBuffer()
 		return m, tickCmd()
 
+	case streamIndexMsg:
+		return m, m.stepStreamIndex(msg)
+
+	case streamFollowMsg:
+		return m, m.stepStreamFollow(msg)
+
+	case imageDrawMsg:
+		return m, m.drawImage(msg)
+
+	case splitPreviewTickMsg:
+		return m, m.stepSplitPreview(msg)
+
+	case splitPreviewLoadedMsg:
+		m.applySplitPreview(msg)
+		return m, nil
+
 	case execDoneMsg:
 		// External process execution completed, exit exec mode
 		m.execMode = false
 		return m, nil
 
+	case openerDoneMsg:
+		return m.handleOpenerDone(msg)
+
+	case shellDoneMsg:
+		return m.handleShellDone(msg)
+
 	case FileChangeMsg:
 		// Refresh tree on file system changes
 		if m.watcherEnabled {
-			m.tree.Refresh()
+			selected := m.selectedPath()
+			if len(msg.Changes) == 0 {
+				// Overflowed batch: reload everything
+				m.tree.Refresh()
+			} else {
+				// Apply just the changed paths. Ghosts go first, since inserting
+				// assumes sorted children; they are re-added from fresh VCS status.
+				m.tree.RemoveGhostNodes()
+				m.tree.ApplyChanges(msg.Changes)
+				m.recordRecent(msg.Changes, time.Now())
+			}
 
 			// Respect forced VCS type, only re-detect in Auto mode
 			if m.vcsForceType == VCSTypeAuto {
@@ -75,17 +164,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Add ghost nodes for deleted files
 			m.tree.AddGhostNodes(m.vcsRepo.GetDeletedFiles())
 
-			m.adjustSelection()
+			m.selectPath(selected)
 
 			// Continue watching
+			reload := m.reloadSplitPreview()
 			if m.watcher != nil {
-				return m, m.watcher.Watch()
+				return m, tea.Batch(m.watcher.Watch(), reload)
 			}
+			return m, reload
 		}
 
+	case controlMsg:
+		return m.handleControl(msg)
+
 	case watcherToggledMsg:
 		// Toggle complete, allow next toggle
 		m.watcherToggling = false
+
+	default:
+		// Replies to the image capability query
+		m.updateImageCapability(msg)
 	}
 
 	return m, nil
@@ -116,18 +214,63 @@ func (m Model) updateNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// gv -> cycle VCS type
 			m.cycleVCSType()
 			return m, nil
+		case "@":
+			// g@ -> pick the agent target
+			m.startAgentPick(nil)
+			return m, nil
+		case "r":
+			// gr -> jump to the most recently changed file
+			return m, m.jumpToRecent()
+		case "R":
+			// gR -> list recent changes
+			m.startRecentList()
+			return m, nil
+		case "f":
+			// gf -> jump to the symlink target
+			return m, m.jumpToLinkTarget()
+		case "s":
+			// gs -> paste as relative symlinks
+			m.pasteLink(LinkSymlinkRelative)
+			return m, nil
+		case "S":
+			// gS -> paste as absolute symlinks
+			m.pasteLink(LinkSymlinkAbsolute)
+			return m, nil
+		case "h":
+			// gh -> paste as hard links
+			m.pasteLink(LinkHard)
+			return m, nil
 		default:
 			// Any other key cancels g and is ignored
 			return m, nil
 		}
 	}
 
+	// Chooser mode confirm keys
+	if m.chooseMode != chooseNone {
+		if handled, cmd := m.updateChooseKey(key); handled {
+			return m, cmd
+		}
+	}
+
+	// Split pane resize and scroll
+	if m.updateSplitPaneKey(key) {
+		return m, nil
+	}
+
 	switch key {
 	case "q", "ctrl+c":
 		if m.watcher != nil {
 			m.watcher.Close()
 		}
 		return m, tea.Quit
+	case "Q":
+		// Quit, leaving the shell in the selected directory (--cwd-file)
+		m.quitToSelection = true
+		if m.watcher != nil {
+			m.watcher.Close()
+		}
+		return m, tea.Quit
 
 	// Navigation
 	case "up", "k":
@@ -183,6 +326,8 @@ func (m Model) updateNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.startNewFile()
 	case "A":
 		m.startNewDir()
+	case "Z":
+		m.startNewArchive()
 
 	// Search
 	case "/":
@@ -194,6 +339,24 @@ func (m Model) updateNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "o":
 		cmd := m.openPreview()
 		return m, cmd
+	case "P":
+		cmd := m.toggleSplitPane()
+		return m, cmd
+
+	// Editor and opener
+	case "e":
+		return m, m.editSelection()
+	case "O":
+		return m, m.openWithOpener()
+
+	// Shell command
+	case "!":
+		m.startShell()
+		return m, nil
+
+	// Send @path references to the agent pane
+	case "@":
+		m.sendToAgentPane()
 
 	// System clipboard
 	case "c":
@@ -204,12 +367,14 @@ func (m Model) updateNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Other
 	case ".":
 		m.toggleHidden()
+		m.adjustScroll()
+		return m, m.reloadSplitPreview()
 	case "R", "f5":
 		return m.refresh()
 	case "W":
 		return m.toggleWatcher()
 	case "?":
-		m.message = "o:preview c:path C:name y:yank d:cut p:paste D:del r:rename"
+		m.message = "o:preview P:split e:edit O:open !:shell @:agent c:path C:name y:yank d:cut p:paste D:del r:rename"
 	}
 
 	m.adjustScroll()
@@ -257,7 +422,9 @@ func (m Model) updateInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.inputBuffer = m.completionCandidates[m.completionIndex]
 		}
 		m.clearCompletions()
-		m.confirmInput()
+		cmd := m.confirmInput()
+		m.adjustScroll()
+		return m, cmd
 	case "esc":
 		m.clearCompletions()
 		m.cancelInput()
@@ -304,18 +471,58 @@ func (m Model) updateConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updatePreviewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	visibleHeight := m.height - 4
+	visibleHeight := m.height - 4 - len(m.previewHeader)
 	contentLen := len(m.previewContent) // Safe: len(nil) returns 0
 
+	// Tree view keys (cursor, folding, query) take precedence
+	if m.isDataTreePreview() && m.updateDataTreeKey(msg) {
+		return m, nil
+	}
+	// Archive listing keys (cursor, marking, extraction)
+	if m.previewIsArchive && m.updateArchiveKey(msg) {
+		return m, nil
+	}
+	// Hex viewer cursor and layout keys
+	if m.isHexViewer() && m.updateHexKey(msg) {
+		return m, nil
+	}
+	// Streamed files scroll by reading only the visible rows
+	if m.previewStream != nil {
+		if handled, cmd := m.updateStreamKey(msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch msg.String() {
-	case "q", "esc", "o":
-		wasImage := m.previewIsImage
-		m.closePreview()
-		if wasImage {
-			// Clear Kitty graphics and refresh screen
-			return m, tea.Sequence(clearKittyImages(), tea.ClearScreen)
+	case "esc":
+		// Clear active search and query first, then close
+		if m.hasPreviewSearch() {
+			m.clearPreviewSearch()
+			return m, nil
 		}
-		return m, nil
+		if m.hasPreviewQuery() {
+			m.clearPreviewQuery()
+			return m, nil
+		}
+		return m.closePreviewWithCleanup()
+	case "q", "o":
+		return m.closePreviewWithCleanup()
+
+	// Search
+	case "/":
+		m.startPreviewSearch()
+
+	// Jump to line, offset or percentage; follow appended data
+	case ":":
+		m.startPreviewJump()
+	case "F":
+		return m, m.toggleFollow()
+
+	// Edit at the current line, or open with the configured opener
+	case "e":
+		return m, m.editSelection()
+	case "O":
+		return m, m.openWithOpener()
 
 	// Scroll
 	case "up", "k":
@@ -347,6 +554,20 @@ func (m Model) updatePreviewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.previewScroll = maxScroll
 		}
 
+	// Horizontal scroll
+	case "left", "h":
+		m.previewHScroll -= PreviewHScrollStep
+		if m.previewHScroll < 0 {
+			m.previewHScroll = 0
+		}
+	case "right", "l":
+		// Stop once the longest visible line ends at the right edge
+		if maxScroll := m.maxPreviewHScroll(); m.previewHScroll < maxScroll {
+			m.previewHScroll = min(m.previewHScroll+PreviewHScrollStep, maxScroll)
+		}
+	case "0":
+		m.previewHScroll = 0
+
 	// Jump to top/bottom
 	case "g":
 		m.previewScroll = 0
@@ -357,16 +578,45 @@ func (m Model) updatePreviewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.previewScroll = maxScroll
 
-	// Jump to next/previous change
+	// Rendered view (Markdown, data, tables)
+	case "m":
+		m.togglePreviewRendered()
+	case "t":
+		m.togglePreviewTOC()
+	case "]":
+		m.jumpToNextHeading()
+	case "[":
+		m.jumpToPrevHeading()
+
+	// Jump to next/previous search match, or change when not searching
 	case "n":
-		m.jumpToNextDiff()
+		if m.hasPreviewSearch() {
+			m.jumpToNextMatch()
+		} else {
+			m.jumpToNextDiff()
+		}
 	case "N":
-		m.jumpToPrevDiff()
+		if m.hasPreviewSearch() {
+			m.jumpToPrevMatch()
+		} else {
+			m.jumpToPrevDiff()
+		}
 	}
 
 	return m, nil
 }
 
+// closePreviewWithCleanup closes the preview and clears image graphics if needed
+func (m Model) closePreviewWithCleanup() (tea.Model, tea.Cmd) {
+	wasImage := m.previewIsImage
+	m.closePreview()
+	if wasImage {
+		// Clear Kitty graphics and refresh screen
+		return m, tea.Sequence(clearKittyImages(), tea.ClearScreen)
+	}
+	return m, nil
+}
+
 // jumpToNextDiff jumps to the next diff line in preview
 func (m *Model) jumpToNextDiff() {
 	if len(m.previewDiffLines) == 0 {
@@ -439,6 +689,17 @@ func (m Model) updateMouseWheelEvent(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd)
 	}
 	m.lastScrollTime = now
 
+	// Wheel over the split pane scrolls its content
+	if m.splitPane && msg.X > m.treeWidth() {
+		switch msg.Button {
+		case tea.MouseWheelUp:
+			m.scrollSplitPane(-1)
+		case tea.MouseWheelDown:
+			m.scrollSplitPane(1)
+		}
+		return m, nil
+	}
+
 	switch msg.Button {
 	case tea.MouseWheelUp:
 		m.moveUp()
@@ -451,8 +712,8 @@ func (m Model) updateMouseWheelEvent(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd)
 
 func (m Model) updateMouseClickEvent(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
 	if msg.Button == tea.MouseLeft {
-		// Tree area starts at row 1 (after title)
-		if msg.Y > 0 {
+		// Tree area starts at row 1 (after title); the split pane ignores clicks
+		if msg.Y > 0 && msg.X < m.treeWidth() {
 			row := msg.Y - 1
 			index := m.scrollOffset + row
 			if index < m.tree.Len() {
@@ -496,7 +757,10 @@ func (m *Model) startNewDir() {
 	m.inputMode = ModeNewDir
 }
 
-func (m *Model) confirmInput() {
+// confirmInput applies the input of the current mode. The command is only set
+// when the root changed and the new watcher needs starting.
+func (m *Model) confirmInput() tea.Cmd {
+	var cmd tea.Cmd
 	switch m.inputMode {
 	case ModeRename:
 		m.doRename()
@@ -504,28 +768,31 @@ func (m *Model) confirmInput() {
 		m.doNewFile()
 	case ModeNewDir:
 		m.doNewDir()
+	case ModeNewArchive:
+		m.doNewArchive()
 	case ModeSearch:
 		// Check if input looks like a dropped file path
 		if m.tryHandleAsDrop() {
 			m.inputMode = ModeNormal
 			m.inputBuffer = ""
-			return
+			return nil
 		}
 		// Empty query: treat as cancel (clear any prior search state)
 		if m.inputBuffer == "" {
 			m.searchActive = false
 			m.searchMatchCount = 0
-			return
+			return nil
 		}
 		// Activate search and count matches
 		m.searchActive = true
 		m.searchMatchCount = m.countSearchMatches()
 		m.searchNext()
 	case ModeGoTo:
-		m.doGoTo()
+		cmd = m.doGoTo()
 	}
 
 	m.inputMode = ModeNormal
+	return cmd
 }
 
 func (m *Model) cancelInput() {
@@ -550,6 +817,7 @@ func (m *Model) doRename() {
 	} else {
 		m.message = fmt.Sprintf("Renamed to %s", filepath.Base(newPath))
 		m.refreshTreeAndVCS()
+		m.selectPath(newPath)
 	}
 	m.inputBuffer = ""
 }
@@ -645,6 +913,7 @@ func (m *Model) clearSearch() {
 // Other operations
 
 func (m *Model) toggleHidden() {
+	selected := m.selectedPath()
 	m.showHidden = !m.showHidden
 	if err := m.tree.SetShowHidden(m.showHidden); err != nil {
 		m.message = fmt.Sprintf("Error: %v", err)
@@ -655,10 +924,12 @@ func (m *Model) toggleHidden() {
 			m.message = "Hiding hidden files"
 		}
 	}
-	m.adjustSelection()
+	m.tree.AddGhostNodes(m.vcsRepo.GetDeletedFiles())
+	m.selectPath(selected)
 }
 
 func (m Model) refresh() (tea.Model, tea.Cmd) {
+	selected := m.selectedPath()
 	if err := m.tree.Refresh(); err != nil {
 		m.message = fmt.Sprintf("Error: %v", err)
 	} else {
@@ -667,8 +938,8 @@ func (m Model) refresh() (tea.Model, tea.Cmd) {
 	// VCS refresh runs synchronously
 	m.vcsRepo.Refresh(m.tree.Root.Path)
 	m.tree.AddGhostNodes(m.vcsRepo.GetDeletedFiles())
-	m.adjustSelection()
-	return m, nil
+	m.selectPath(selected)
+	return m, m.reloadSplitPreview()
 }
 
 // watcherToggledMsg is sent when watcher toggle is complete
@@ -683,16 +954,18 @@ func (m Model) toggleWatcher() (tea.Model, tea.Cmd) {
 
 	if !m.watcherEnabled {
 		// Enable: Create new watcher
-		watcher, err := NewWatcher(m.tree.Root.Path)
+		watcher, err := m.startWatcher(m.tree.Root.Path)
 		if err != nil {
 			m.message = "Failed to enable watching"
 			m.watcherToggling = false
 			return m, nil
 		}
 		m.watcher = watcher
-		m.watcher.WatchExpandedDirs(m.tree)
 		m.watcherEnabled = true
 		m.message = "File watching enabled"
+		if watcher.Backend() == "poll" {
+			m.message += " (polling)"
+		}
 		return m, tea.Batch(
 			m.watcher.Watch(),
 			func() tea.Msg { return watcherToggledMsg{} },
@@ -709,11 +982,32 @@ func (m Model) toggleWatcher() (tea.Model, tea.Cmd) {
 	}
 }
 
-// refreshTreeAndVCS refreshes the tree and VCS status after file operations
+// startWatcher creates a watcher for root with the configured backend and
+// ignore list, watching the directories expanded in the tree
+func (m *Model) startWatcher(root string) (*Watcher, error) {
+	var watcher *Watcher
+	var err error
+	if m.config.Watch.Backend == "poll" {
+		watcher, err = NewPollWatcher(root)
+	} else {
+		watcher, err = NewWatcher(root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	watcher.SetIgnore(m.config.Watch.Ignore)
+	watcher.WatchExpandedDirs(m.tree)
+	return watcher, nil
+}
+
+// refreshTreeAndVCS refreshes the tree and VCS status after file operations,
+// keeping the selection on the same path
 func (m *Model) refreshTreeAndVCS() {
+	selected := m.selectedPath()
 	m.tree.Refresh()
 	m.vcsRepo.Refresh(m.tree.Root.Path)
 	m.tree.AddGhostNodes(m.vcsRepo.GetDeletedFiles())
+	m.selectPath(selected)
 }
 
 // cycleVCSType cycles through VCS types: Auto → JJ → Git → Auto
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Send-to-agent operations
+
+// sendToAgentPane sends the marked files or the selection to the session's
+// target, asking for one first if none was picked yet
+func (m *Model) sendToAgentPane() {
+	paths := m.openTargets()
+	if len(paths) == 0 {
+		return
+	}
+	if m.agentTarget == nil {
+		m.startAgentPick(paths)
+		return
+	}
+	m.deliverToAgent(paths)
+}
+
+// agentTargetCandidates lists configured targets and the other tmux panes
+func (m *Model) agentTargetCandidates() []agentTarget {
+	var candidates []agentTarget
+	for _, s := range m.config.Agent.Targets {
+		if t, err := parseAgentTarget(s); err == nil {
+			candidates = append(candidates, t)
+		}
+	}
+	// Errors mean no tmux server is running; configured targets still work
+	if panes, err := listTmuxPanes(os.Getenv("TMUX_PANE")); err == nil {
+		candidates = append(candidates, panes...)
+	}
+	return candidates
+}
+
+// startAgentPick opens the target picker; pending paths are sent after picking
+func (m *Model) startAgentPick(pending []string) {
+	candidates := m.agentTargetCandidates()
+	if len(candidates) == 0 {
+		m.message = "No agent targets (run inside tmux or set [agent] targets)"
+		return
+	}
+	m.agentCandidates = candidates
+	m.agentPending = pending
+	m.agentIndex = 0
+	if m.agentTarget != nil {
+		for i, c := range candidates {
+			if c.Kind == m.agentTarget.Kind && c.Addr == m.agentTarget.Addr {
+				m.agentIndex = i
+			}
+		}
+	}
+	m.inputMode = ModeAgentPick
+}
+
+// deliverToAgent sends paths as @path references to the current target
+func (m *Model) deliverToAgent(paths []string) {
+	t := *m.agentTarget
+	dir := t.Dir
+	if t.Kind == agentTargetTmux {
+		// The agent may have changed directory since the pane was listed
+		if d := tmuxPaneDir(t.Addr); d != "" {
+			dir = d
+		}
+	}
+
+	refs := agentRefs(paths, dir)
+	if err := sendToAgent(t, refs); err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	if len(paths) == 1 {
+		m.message = fmt.Sprintf("Sent %s to %s", refs, t.Label)
+	} else {
+		m.message = fmt.Sprintf("Sent %d paths to %s", len(paths), t.Label)
+	}
+}
+
+func (m Model) updateAgentPickMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k", "ctrl+p":
+		if m.agentIndex > 0 {
+			m.agentIndex--
+		}
+	case "down", "j", "ctrl+n":
+		if m.agentIndex < len(m.agentCandidates)-1 {
+			m.agentIndex++
+		}
+	case "enter":
+		t := m.agentCandidates[m.agentIndex]
+		m.agentTarget = &t
+		m.inputMode = ModeNormal
+		m.agentCandidates = nil
+		if len(m.agentPending) > 0 {
+			m.deliverToAgent(m.agentPending)
+		} else {
+			m.message = fmt.Sprintf("Agent target: %s", t.Label)
+		}
+		m.agentPending = nil
+	case "esc", "q":
+		m.inputMode = ModeNormal
+		m.agentCandidates = nil
+		m.agentPending = nil
+		m.message = "Cancelled"
+	}
+	return m, nil
+}
+
+// renderAgentPicker renders the target list for ModeAgentPick
+func (m Model) renderAgentPicker(maxContentWidth int) string {
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	candidateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Width(maxContentWidth - 1)
+	selectedCandidateStyle := candidateStyle.Background(lipgloss.Color("238"))
+
+	lines := []string{" Send to agent:"}
+
+	// Keep the selection visible
+	start := max(0, min(m.agentIndex-MaxCompletionVisible/2, len(m.agentCandidates)-MaxCompletionVisible))
+	end := min(start+MaxCompletionVisible, len(m.agentCandidates))
+	for i := start; i < end; i++ {
+		c := m.agentCandidates[i]
+		text := " " + c.Label
+		if c.Dir != "" {
+			text += "  " + collapseHomePath(c.Dir)
+		}
+		text = ansi.Truncate(text, maxContentWidth-1, "…")
+		if i == m.agentIndex {
+			lines = append(lines, selectedCandidateStyle.Render(text))
+		} else {
+			lines = append(lines, candidateStyle.Render(text))
+		}
+	}
+
+	hint := " j/k:move Enter:send Esc:cancel"
+	if lipgloss.Width(hint) > maxContentWidth {
+		hint = ansi.Truncate(hint, maxContentWidth-1, "") + "…"
+	}
+	lines = append(lines, hintStyle.Render(hint))
+	return strings.Join(lines, "\n")
+}
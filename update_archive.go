@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Archive preview operations
+
+// openArchivePreview shows the archive listing instead of a hex dump
+func (m *Model) openArchivePreview(path string) tea.Cmd {
+	entries, err := listArchive(path)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+
+	m.previewIsArchive = true
+	m.previewIsBinary = false
+	m.previewArchive = entries
+	m.previewArchiveMarked = make(map[string]bool)
+	m.previewCursor = 0
+	m.renderArchiveListing()
+	m.inputMode = ModePreview
+	return nil
+}
+
+// resetArchivePreview clears archive listing state
+func (m *Model) resetArchivePreview() {
+	m.previewIsArchive = false
+	m.previewArchive = nil
+	m.previewArchiveMarked = nil
+	m.previewCursor = 0
+}
+
+// renderArchiveListing lays out the archive entries (mark, size, date, name)
+func (m *Model) renderArchiveListing() {
+	m.previewContent = make([]string, len(m.previewArchive))
+	m.previewSyntax = make([][]textSpan, len(m.previewArchive))
+
+	for i, e := range m.previewArchive {
+		var b strings.Builder
+		var spans []textSpan
+
+		if m.previewArchiveMarked[e.Name] {
+			b.WriteString("● ")
+			spans = append(spans, textSpan{Start: 0, End: b.Len(), Style: markedStyle})
+		} else {
+			b.WriteString("  ")
+		}
+
+		size := "-"
+		if !e.IsDir {
+			size = formatFileSize(e.Size)
+		}
+		date := ""
+		if !e.ModTime.IsZero() {
+			date = e.ModTime.Local().Format("2006-01-02 15:04")
+		}
+		start := b.Len()
+		fmt.Fprintf(&b, "%9s  %-16s  ", size, date)
+		spans = append(spans, textSpan{Start: start, End: b.Len(), Style: lineNumStyle})
+
+		start = b.Len()
+		b.WriteString(e.Name)
+		if e.IsDir {
+			b.WriteString("/")
+			spans = append(spans, textSpan{Start: start, End: b.Len(), Style: dirStyle})
+		}
+		if e.Link != "" {
+			start = b.Len()
+			b.WriteString(" -> " + e.Link)
+			spans = append(spans, textSpan{Start: start, End: b.Len(), Style: lineNumStyle})
+		}
+
+		m.previewContent[i] = b.String()
+		m.previewSyntax[i] = spans
+	}
+}
+
+// updateArchiveKey handles keys specific to the archive listing.
+// Returns false for keys the regular preview handling should process.
+func (m *Model) updateArchiveKey(msg tea.KeyMsg) bool {
+	if m.updatePreviewCursorKey(msg) {
+		return true
+	}
+
+	switch msg.String() {
+	case "space", " ":
+		if m.previewCursor < len(m.previewArchive) {
+			name := m.previewArchive[m.previewCursor].Name
+			if m.previewArchiveMarked[name] {
+				delete(m.previewArchiveMarked, name)
+			} else {
+				m.previewArchiveMarked[name] = true
+			}
+			m.renderArchiveListing()
+			m.movePreviewCursor(1)
+		}
+	case "x":
+		m.extractArchiveEntries(m.selectedArchiveEntries())
+	case "X":
+		m.extractArchiveEntries(nil)
+	default:
+		return false
+	}
+	return true
+}
+
+// selectedArchiveEntries returns marked entries, or the entry under the cursor
+func (m *Model) selectedArchiveEntries() []string {
+	if len(m.previewArchiveMarked) > 0 {
+		names := make([]string, 0, len(m.previewArchiveMarked))
+		for _, e := range m.previewArchive {
+			if m.previewArchiveMarked[e.Name] {
+				names = append(names, e.Name)
+			}
+		}
+		return names
+	}
+	if m.previewCursor < len(m.previewArchive) {
+		return []string{m.previewArchive[m.previewCursor].Name}
+	}
+	return nil
+}
+
+// extractArchiveEntries extracts entries (nil = all) next to the archive
+func (m *Model) extractArchiveEntries(names []string) {
+	destDir := filepath.Dir(m.previewPath)
+	count, err := extractArchive(m.previewPath, destDir, names)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+	} else {
+		m.message = fmt.Sprintf("Extracted %d file(s) to %s", count, collapseHomePath(destDir))
+	}
+	m.previewArchiveMarked = make(map[string]bool)
+	m.renderArchiveListing()
+	m.refreshTreeAndVCS()
+	m.adjustSelection()
+}
+
+// Archive creation
+
+// startNewArchive prompts for the name of an archive holding the selected paths
+func (m *Model) startNewArchive() {
+	paths := m.getSelectedPaths()
+	if len(paths) == 0 {
+		return
+	}
+
+	m.archivePaths = paths
+	if len(paths) == 1 {
+		m.inputBuffer = filepath.Base(paths[0]) + ".zip"
+	} else {
+		m.inputBuffer = "archive.zip"
+	}
+	m.inputMode = ModeNewArchive
+}
+
+func (m *Model) doNewArchive() {
+	paths := m.archivePaths
+	m.archivePaths = nil
+	if m.inputBuffer == "" || len(paths) == 0 {
+		return
+	}
+
+	// Place the archive next to the (first) archived item
+	destDir := filepath.Dir(paths[0])
+	dest := uniqueArchivePath(filepath.Join(destDir, m.inputBuffer))
+	count, err := createArchive(dest, paths)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+	} else {
+		m.message = fmt.Sprintf("Created %s (%d file(s))", filepath.Base(dest), count)
+		m.marked = make(map[string]bool)
+		m.refreshTreeAndVCS()
+		m.adjustSelection()
+	}
+	m.inputBuffer = ""
+}
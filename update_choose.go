@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"sort"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Chooser mode operations (--choose, --choose-dir)
+
+// updateChooseKey handles the confirm keys in chooser mode: Enter picks the
+// marked paths or the selected file (directories still expand, except with
+// --choose-dir), Ctrl+S picks the selection whatever it is
+func (m *Model) updateChooseKey(key string) (bool, tea.Cmd) {
+	if key != "enter" && key != "ctrl+s" {
+		return false, nil
+	}
+
+	var paths []string
+	if len(m.marked) > 0 {
+		paths = m.getSelectedPaths()
+	} else {
+		node := m.tree.GetNode(m.selected)
+		if node == nil {
+			return true, nil
+		}
+		if key == "enter" && node.IsDir && m.chooseMode == chooseAny {
+			return false, nil
+		}
+		paths = []string{node.Path}
+	}
+
+	chosen := make([]string, 0, len(paths))
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue // Ghost nodes (deleted files) cannot be picked
+		}
+		if m.chooseMode == chooseDir && !info.IsDir() {
+			m.message = "Only directories can be chosen"
+			return true, nil
+		}
+		chosen = append(chosen, p)
+	}
+	if len(chosen) == 0 {
+		m.message = "Nothing to choose"
+		return true, nil
+	}
+
+	sort.Strings(chosen)
+	m.chosen = chosen
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
+	return true, tea.Quit
+}
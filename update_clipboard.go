@@ -103,6 +103,42 @@ func (m *Model) paste() {
 	m.adjustSelection()
 }
 
+// pasteLink links the clipboard paths into the paste destination instead of
+// copying them. The clipboard is kept, even after a cut, since nothing moved.
+func (m *Model) pasteLink(linkType LinkType) {
+	if m.clipboard.IsEmpty() {
+		m.message = "Clipboard is empty"
+		return
+	}
+
+	destDir := m.getPasteDestination()
+	if destDir == "" {
+		return
+	}
+
+	var success, failed int
+	var lastErr error
+	for _, path := range m.clipboard.Paths {
+		if _, err := LinkFile(path, destDir, linkType); err != nil {
+			failed++
+			lastErr = err
+		} else {
+			success++
+		}
+	}
+
+	switch {
+	case failed == 0:
+		m.message = fmt.Sprintf("Linked %d item(s) as %s", success, linkType)
+	case success == 0:
+		m.message = fmt.Sprintf("Error: %v", lastErr)
+	default:
+		m.message = fmt.Sprintf("Linked %d item(s) as %s, %d failed: %v", success, linkType, failed, lastErr)
+	}
+	m.refreshTreeAndVCS()
+	m.adjustSelection()
+}
+
 func (m *Model) getPasteDestination() string {
 	node := m.tree.GetNode(m.selected)
 	if node == nil {
@@ -123,10 +159,10 @@ func (m *Model) confirmDelete() {
 		return
 	}
 
-	// Check if any path is a directory
+	// Check if any path is a directory (links to directories only lose the link)
 	hasDirectories := false
 	for _, path := range paths {
-		info, err := os.Stat(path)
+		info, err := os.Lstat(path)
 		if err == nil && info.IsDir() {
 			hasDirectories = true
 			break
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// handleControl runs a request from the control socket and re-arms the listener
+func (m Model) handleControl(msg controlMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	resp := controlResponse{OK: true}
+
+	var err error
+	switch msg.req.Cmd {
+	case "reveal":
+		cmd, err = m.revealPath(msg.req.Path)
+		cmd = tea.Batch(cmd, m.reloadSplitPreview())
+	case "cd":
+		cmd, err = m.changeRoot(filepath.Clean(msg.req.Path))
+	case "refresh":
+		var next tea.Model
+		next, cmd = m.refresh()
+		m = next.(Model)
+	case "mark":
+		err = m.controlMark(msg.req.Paths)
+	case "unmark":
+		m.controlUnmark(msg.req.Paths)
+	case "selection":
+	default:
+		err = fmt.Errorf("unknown command %q", msg.req.Cmd)
+	}
+
+	if err != nil {
+		resp = controlResponse{Error: err.Error()}
+	} else {
+		sel := m.controlSelection()
+		resp.Selection = &sel
+	}
+	msg.reply <- resp
+
+	if m.control != nil {
+		cmd = tea.Batch(cmd, m.control.Listen())
+	}
+	return m, cmd
+}
+
+// controlSelection reports the root, selected path and sorted marks
+func (m *Model) controlSelection() controlSelection {
+	sel := controlSelection{
+		Root:   m.tree.Root.Path,
+		Marked: make([]string, 0, len(m.marked)),
+	}
+	if node := m.tree.GetNode(m.selected); node != nil {
+		sel.Path = node.Path
+	}
+	for path := range m.marked {
+		sel.Marked = append(sel.Marked, path)
+	}
+	sort.Strings(sel.Marked)
+	return sel
+}
+
+// publishSelection notifies subscribers when the selection, marks or root changed
+func (m *Model) publishSelection(prev controlSelection) {
+	if m.control == nil || m.tree == nil {
+		return
+	}
+	sel := m.controlSelection()
+	if sel.Root == prev.Root && sel.Path == prev.Path && strings.Join(sel.Marked, "\x00") == strings.Join(prev.Marked, "\x00") {
+		return
+	}
+	m.control.Publish(sel)
+}
+
+func (m *Model) controlMark(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths to mark")
+	}
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		m.marked[filepath.Clean(path)] = true
+	}
+	m.message = fmt.Sprintf("Marked %d item(s)", len(m.marked))
+	return nil
+}
+
+// controlUnmark removes the given marks, or all of them
+func (m *Model) controlUnmark(paths []string) {
+	if len(paths) == 0 {
+		m.clearMarks()
+	}
+	for _, path := range paths {
+		delete(m.marked, filepath.Clean(path))
+	}
+}
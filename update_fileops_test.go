@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	tea "charm.land/bubbletea/v2"
 )
 
 // ========================================
@@ -1053,3 +1055,77 @@ func TestVCSRefresh_WithGitRepo(t *testing.T) {
 		t.Error("Deleted tracked file should appear in VCS deleted files")
 	}
 }
+
+func TestPasteLink(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcFile := filepath.Join(tmpDir, "shared", "config.toml")
+	destDir := filepath.Join(tmpDir, "pkg")
+	os.MkdirAll(filepath.Dir(srcFile), 0755)
+	os.MkdirAll(destDir, 0755)
+	os.WriteFile(srcFile, nil, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	var m tea.Model = model
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("s"))
+	if msg := m.(Model).message; msg != "Clipboard is empty" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	model = m.(Model)
+	model.clipboard.Cut([]string{srcFile})
+	model.selected = model.tree.IndexOf(destDir)
+	m = model
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("s"))
+
+	link := filepath.Join(destDir, "config.toml")
+	if target, err := os.Readlink(link); err != nil || target != filepath.Join("..", "shared", "config.toml") {
+		t.Errorf("Expected a relative symlink, got %q, %v", target, err)
+	}
+	if msg := m.(Model).message; msg != "Linked 1 item(s) as relative symlink" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	// Linking moves nothing: the source stays and the clipboard is kept
+	if _, err := os.Stat(srcFile); err != nil || len(m.(Model).clipboard.Paths) != 1 {
+		t.Error("Expected the source and clipboard kept")
+	}
+
+	// Names are made unique like paste does
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("h"))
+	if _, err := os.Stat(filepath.Join(destDir, "config_1.toml")); err != nil {
+		t.Errorf("Expected config_1.toml hard linked: %v", err)
+	}
+	if msg := m.(Model).message; msg != "Linked 1 item(s) as hard link" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	model = m.(Model)
+	model.clipboard.Copy([]string{filepath.Dir(srcFile)})
+	m = model
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("h"))
+	if msg := m.(Model).message; !strings.HasPrefix(msg, "Error: ") {
+		t.Errorf("Expected an error hard linking a directory, got %q", msg)
+	}
+
+	// Partial failures are counted with the last error
+	model = m.(Model)
+	model.clipboard.Copy([]string{srcFile, filepath.Dir(srcFile)})
+	m = model
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("h"))
+	if msg := m.(Model).message; !strings.HasPrefix(msg, "Linked 1 item(s) as hard link, 1 failed: shared: cannot hard link a directory") {
+		t.Errorf("Expected the failure reported, got %q", msg)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Hex viewer operations (binary previews)
+
+// hexRowWidths are the row widths cycled with w
+var hexRowWidths = []int{8, 16, 32}
+
+// hexInspectorLines is the height of the inspector below the hex dump
+const hexInspectorLines = 2
+
+// isHexViewer returns true when a binary file is shown in the hex viewer
+func (m Model) isHexViewer() bool {
+	return m.previewStream != nil && m.previewStream.hex
+}
+
+// hexRowBytes returns the number of bytes per hex dump line
+func (m Model) hexRowBytes() int {
+	if m.isHexViewer() {
+		return m.previewStream.rowBytes
+	}
+	return hexBytesPerLine
+}
+
+// previewFooterLines is the number of rows between the content and the status bar
+func (m Model) previewFooterLines() int {
+	if m.isHexViewer() {
+		return hexInspectorLines
+	}
+	return 0
+}
+
+// updateHexKey handles cursor movement and layout keys in the hex viewer.
+// Returns false for keys the regular preview handling should process.
+func (m *Model) updateHexKey(msg tea.KeyMsg) bool {
+	s := m.previewStream
+	rb := int64(s.rowBytes)
+	page := int64(m.streamVisibleHeight()) * rb
+
+	switch msg.String() {
+	case "left", "h":
+		m.moveHexCursor(s.cursor - 1)
+	case "right", "l":
+		m.moveHexCursor(s.cursor + 1)
+	case "up", "k":
+		m.moveHexCursor(s.cursor - rb)
+	case "down", "j":
+		m.moveHexCursor(s.cursor + rb)
+	case "pgup", "b":
+		m.moveHexCursor(s.cursor - page)
+	case "pgdown", "f", "space", " ":
+		m.moveHexCursor(s.cursor + page)
+	case "0", "home":
+		m.moveHexCursor(s.cursor / rb * rb)
+	case "$", "end":
+		m.moveHexCursor(s.cursor/rb*rb + rb - 1)
+	case "g":
+		m.moveHexCursor(0)
+	case "G":
+		m.moveHexCursor(s.size() - 1)
+	case "w":
+		m.cycleHexRowWidth()
+	case "E":
+		s.bigEndian = !s.bigEndian
+	default:
+		return false
+	}
+	return true
+}
+
+// moveHexCursor moves the cursor to off (clamped to the file) and scrolls it into view
+func (m *Model) moveHexCursor(off int64) {
+	s := m.previewStream
+	s.cursor = min(max(off, 0), max(s.size()-1, 0))
+	s.follow = false
+	m.revealHexCursor()
+}
+
+// revealHexCursor scrolls the minimum needed to show the cursor row
+func (m *Model) revealHexCursor() {
+	s := m.previewStream
+	rb := int64(s.rowBytes)
+	visible := int64(m.streamVisibleHeight())
+	row := s.cursor / rb * rb
+	if row < s.top {
+		s.top = row
+	} else if row >= s.top+visible*rb {
+		s.top = row - (visible-1)*rb
+	}
+	m.loadStreamWindow()
+}
+
+// cycleHexRowWidth switches between 8, 16 and 32 bytes per row
+func (m *Model) cycleHexRowWidth() {
+	s := m.previewStream
+	for i, w := range hexRowWidths {
+		if w == s.rowBytes {
+			s.rowBytes = hexRowWidths[(i+1)%len(hexRowWidths)]
+			break
+		}
+	}
+	s.top = s.rowStart(s.top)
+	m.revealHexCursor()
+}
+
+// hexCursorSpans highlights the cursor byte on a hex dump line
+func (m Model) hexCursorSpans(lineIdx int) []textSpan {
+	s := m.previewStream
+	idx := int(s.cursor - s.top)
+	if s.cursor < s.top || idx >= len(m.previewBytes) {
+		return nil
+	}
+	return m.hexByteSpans(lineIdx, idx, idx+1, hexCursorStyle)
+}
+
+// renderHexInspector renders the values under the cursor, one line per group
+func (m Model) renderHexInspector() []string {
+	s := m.previewStream
+	fields := inspectBytes(s.file.read(s.cursor, s.cursor+8), s.bigEndian)
+
+	order := "LE"
+	if s.bigEndian {
+		order = "BE"
+	}
+	groups := [][]hexField{fields[:7], fields[7:]} // 8 to 32 bits, then 64 bits and floats
+
+	lines := make([]string, len(groups))
+	for i, group := range groups {
+		var b strings.Builder
+		b.WriteString(" ")
+		if i == len(groups)-1 {
+			b.WriteString(tocCurrentStyle.Render(order) + "  ")
+		}
+		for j, f := range group {
+			if j > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(lineNumStyle.Render(f.Label) + " " + f.Value)
+		}
+		line := b.String()
+		if ansi.StringWidth(line) > m.width && m.width > 1 {
+			line = ansi.Truncate(line, m.width-1, "") + "…"
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// hexPosition describes the cursor for the status bar
+func (m Model) hexPosition() string {
+	s := m.previewStream
+	percent := 0
+	if s.size() > 0 {
+		percent = int((s.cursor + 1) * 100 / s.size())
+	}
+	return fmt.Sprintf("Offset 0x%08x (%d) / 0x%x (%d%%) %dB rows", s.cursor, s.cursor, s.size(), percent, s.rowBytes)
+}
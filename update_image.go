@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Inline image preview operations
+
+// imageDrawMsg draws the encoded preview image once the screen was repainted
+type imageDrawMsg struct {
+	id int
+}
+
+// inTmux reports whether bon3 runs inside tmux (sequences need passthrough)
+func inTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// queryImageCapabilities asks the terminal which image protocol it supports
+func (m Model) queryImageCapabilities() tea.Cmd {
+	if m.imageForced {
+		return nil
+	}
+	return tea.Raw(imageCapabilityQuery(inTmux()))
+}
+
+// updateImageCapability handles terminal replies to the capability query.
+// It returns false for messages that are not replies.
+func (m *Model) updateImageCapability(msg tea.Msg) bool {
+	switch msg := msg.(type) {
+	case uv.KittyGraphicsEvent:
+		if msg.Options.ID == kittyQueryID && string(msg.Payload) == "OK" && !m.imageForced {
+			m.imageProtocol = imageProtocolKitty
+		}
+	case uv.PrimaryDeviceAttributesEvent:
+		if hasSixel(msg) && !m.imageForced && m.imageProtocol == imageProtocolAuto {
+			m.imageProtocol = imageProtocolSixel
+		}
+	case uv.CellSizeEvent:
+		if msg.Width > 0 && msg.Height > 0 {
+			m.cellWidth = msg.Width
+			m.cellHeight = msg.Height
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// imageArea returns the preview area for images in cells
+func (m Model) imageArea() (cols, rows int) {
+	return max(m.width-2, 10), max(m.height-4, 5)
+}
+
+// cellSize returns the terminal cell size in pixels (a common default until reported)
+func (m Model) cellSize() (w, h int) {
+	if m.cellWidth > 0 && m.cellHeight > 0 {
+		return m.cellWidth, m.cellHeight
+	}
+	return DefaultCellWidthPx, DefaultCellHeightPx
+}
+
+// openNativeImagePreview decodes and encodes the image for the detected protocol.
+// It returns false when the image cannot be decoded (e.g., ICO), so the caller
+// falls back to chafa or ASCII art.
+func (m *Model) openNativeImagePreview(path string) (tea.Cmd, bool) {
+	if err := m.encodePreviewImage(path); err != nil {
+		return nil, false
+	}
+	m.previewIsBinary = false
+	m.previewIsImage = true
+	m.inputMode = ModePreview
+	return tea.Sequence(tea.ClearScreen, m.drawImageCmd()), true
+}
+
+// encodePreviewImage encodes the image at path to fit the preview area
+func (m *Model) encodePreviewImage(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	cols, rows := m.imageArea()
+	cellW, cellH := m.cellSize()
+	enc, err := encodeImage(m.imageProtocol, img, cols, rows, cellW, cellH, inTmux())
+	if err != nil {
+		return err
+	}
+
+	// Blank rows reserve the space the image is drawn over
+	m.previewImageSeq = enc.Seq
+	m.previewContent = make([]string, enc.Rows)
+	return nil
+}
+
+// drawImageCmd schedules drawing the image after the next repaint
+func (m *Model) drawImageCmd() tea.Cmd {
+	m.imageDrawID++
+	id := m.imageDrawID
+	return tea.Tick(ImageDrawDelayMs*time.Millisecond, func(time.Time) tea.Msg {
+		return imageDrawMsg{id: id}
+	})
+}
+
+// drawImage writes the encoded image below the title, bypassing the renderer
+func (m Model) drawImage(msg imageDrawMsg) tea.Cmd {
+	if msg.id != m.imageDrawID || m.previewImageSeq == "" || m.inputMode != ModePreview {
+		return nil
+	}
+	return tea.Raw(ansi.SaveCursor + ansi.CursorPosition(1, 2) + m.previewImageSeq + ansi.RestoreCursor)
+}
+
+// redrawImagePreview re-encodes the image for a new window size
+func (m *Model) redrawImagePreview() tea.Cmd {
+	if m.previewImageSeq == "" {
+		return nil
+	}
+	if err := m.encodePreviewImage(m.previewPath); err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+	return tea.Sequence(clearKittyImages(), tea.ClearScreen, m.drawImageCmd())
+}
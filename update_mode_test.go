@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
@@ -591,6 +592,40 @@ func TestPreviewMode_JumpToBottom(t *testing.T) {
 	}
 }
 
+func TestPreviewMode_ScrollRightStopsAtLongestLine(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	model.inputMode = ModePreview
+	model.previewContent = []string{"short", strings.Repeat("x", 50)}
+	model.height = 20
+	model.width = 40 // 32 columns of content
+
+	var m tea.Model = model
+	for i := 0; i < 10; i++ {
+		m, _ = m.Update(keyMsg("l"))
+	}
+	if got := m.(Model).previewHScroll; got != 50-32 {
+		t.Errorf("Expected previewHScroll clamped to %d, got %d", 50-32, got)
+	}
+
+	// Nothing to scroll when every line fits
+	model.previewContent = []string{"short"}
+	newModel, _ := model.Update(keyMsg("l"))
+	if got := newModel.(Model).previewHScroll; got != 0 {
+		t.Errorf("Expected no horizontal scroll, got %d", got)
+	}
+}
+
 // --- Input Mode → ModeNormal ---
 
 func TestTransition_Search_to_Normal_Enter(t *testing.T) {
@@ -1292,6 +1327,100 @@ func TestModeGoTo_Enter_WithoutSelection_UsesInputBuffer(t *testing.T) {
 	}
 }
 
+func TestModeGoTo_Enter_File_RevealsIt(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "pkg", "sub"), 0755)
+	target := filepath.Join(tmpDir, "pkg", "sub", "main.go")
+	os.WriteFile(target, nil, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.height = 20
+
+	model.inputMode = ModeGoTo
+	model.inputBuffer = "pkg/sub/main.go"
+
+	newModel, _ := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m := newModel.(Model)
+
+	if m.tree.Root.Path != tmpDir {
+		t.Errorf("Expected the root unchanged, got %s", m.tree.Root.Path)
+	}
+	if node := m.tree.GetNode(m.selected); node == nil || node.Path != target {
+		t.Errorf("Expected %s selected, got %v (%s)", target, node, m.message)
+	}
+}
+
+func TestRevealPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "root", "deep", "er"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "elsewhere"), 0755)
+	for i := 0; i < 30; i++ {
+		os.WriteFile(filepath.Join(tmpDir, "root", "deep", "er", "f"+string(rune('a'+i%26))+string(rune('a'+i/26))), nil, 0644)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "root", ".env"), nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, "elsewhere", "x.txt"), nil, 0644)
+
+	model, err := NewModel(filepath.Join(tmpDir, "root"))
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+	model.height = 12
+
+	target := filepath.Join(tmpDir, "root", "deep", "er", "fpa")
+	if _, err := model.revealPath(target); err != nil {
+		t.Fatal(err)
+	}
+	if node := model.tree.GetNode(model.selected); node.Path != target {
+		t.Errorf("Expected %s selected, got %s", target, node.Path)
+	}
+	if model.scrollOffset != model.selected-5 {
+		t.Errorf("Expected the selection centered, scroll %d for line %d", model.scrollOffset, model.selected)
+	}
+
+	// Startup reveals are centered again once the real height is known
+	model.centerPending = true
+	next, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	if sized := next.(Model); sized.scrollOffset != 0 || sized.centerPending {
+		t.Errorf("Expected the selection recentered for height 40, scroll %d", sized.scrollOffset)
+	}
+
+	// Dotfiles are reported, not shown by turning on hidden files
+	if _, err := model.revealPath(filepath.Join(tmpDir, "root", ".env")); err == nil || model.showHidden {
+		t.Errorf("Expected an error for .env with hidden files kept off, err %v", err)
+	}
+	model.showHidden = true
+	model.tree.SetShowHidden(true)
+	if _, err := model.revealPath(filepath.Join(tmpDir, "root", ".env")); err != nil {
+		t.Errorf("Expected .env revealed with hidden files shown, err %v", err)
+	}
+
+	// Paths outside the root move the root
+	outside := filepath.Join(tmpDir, "elsewhere", "x.txt")
+	cmd, err := model.revealPath(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.tree.Root.Path != filepath.Join(tmpDir, "elsewhere") || model.tree.GetNode(model.selected).Path != outside {
+		t.Errorf("Expected root moved to elsewhere/, got %s", model.tree.Root.Path)
+	}
+	if model.watcher != nil && cmd == nil {
+		t.Error("Expected a command starting the new root's watcher")
+	}
+}
+
 func TestModeGoTo_Backspace_RefreshesCandidates(t *testing.T) {
 	tmpDir := t.TempDir()
 	os.Mkdir(filepath.Join(tmpDir, "src"), 0755)
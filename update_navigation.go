@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	tea "charm.land/bubbletea/v2"
 )
 
 // Navigation functions
@@ -91,6 +93,26 @@ func (m *Model) adjustSelection() {
 	}
 }
 
+// selectedPath returns the path of the selected node ("" if none)
+func (m *Model) selectedPath() string {
+	if node := m.tree.GetNode(m.selected); node != nil {
+		return node.Path
+	}
+	return ""
+}
+
+// selectPath re-selects path after the tree changed, keeping the old
+// index (clamped) when the path is gone
+func (m *Model) selectPath(path string) {
+	if idx := m.tree.IndexOf(path); idx >= 0 {
+		m.selected = idx
+	}
+	m.adjustSelection()
+	if m.height > 2 {
+		m.adjustScroll()
+	}
+}
+
 func (m *Model) adjustScroll() {
 	visibleHeight := m.height - 2
 
@@ -101,6 +123,101 @@ func (m *Model) adjustScroll() {
 	}
 }
 
+// centerSelection scrolls so the selected line sits in the middle of the view
+func (m *Model) centerSelection() {
+	visibleHeight := m.height - 2
+	if visibleHeight <= 0 {
+		return // No window size yet
+	}
+	m.scrollOffset = m.selected - visibleHeight/2
+	if maxOffset := m.tree.Len() - visibleHeight; m.scrollOffset > maxOffset {
+		m.scrollOffset = maxOffset
+	}
+	if m.scrollOffset < 0 {
+		m.scrollOffset = 0
+	}
+}
+
+// revealPath expands the ancestors of path, selects it and centers it.
+// Paths outside the tree move the root to their directory first; the returned
+// command starts watching the new root (see changeRoot).
+func (m *Model) revealPath(path string) (tea.Cmd, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Returned even when the path turns out not to be listed
+	var cmd tea.Cmd
+
+	if !isWithin(m.tree.Root.Path, absPath) {
+		dir := absPath
+		if !info.IsDir() {
+			dir = filepath.Dir(absPath)
+		}
+		if cmd, err = m.changeRoot(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	idx, err := m.tree.Reveal(absPath)
+	if err != nil {
+		return cmd, err
+	}
+	if idx < 0 && !m.showHidden && isHiddenPath(m.tree.Root.Path, absPath) {
+		// Dotfiles are only listed with hidden files shown; that stays the user's choice
+		return cmd, fmt.Errorf("%s is hidden (press . to show hidden files)", collapseHomePath(absPath))
+	}
+	if idx < 0 {
+		return cmd, fmt.Errorf("%s: not in the tree", absPath)
+	}
+
+	m.selected = idx
+	m.centerSelection()
+	if m.watcher != nil {
+		m.watcher.WatchExpandedDirs(m.tree)
+	}
+	m.message = fmt.Sprintf("→ %s", collapseHomePath(absPath))
+	return cmd, nil
+}
+
+// isHiddenPath reports whether a component of path below root is a dotfile
+func isHiddenPath(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(name, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// jumpToLinkTarget reveals what the selected symlink points to, moving the
+// root when the target is outside it
+func (m *Model) jumpToLinkTarget() tea.Cmd {
+	node := m.tree.GetNode(m.selected)
+	if node == nil || !node.IsSymlink {
+		m.message = "Not a symlink"
+		return nil
+	}
+	if node.IsBroken {
+		m.message = fmt.Sprintf("Error: broken link → %s", node.LinkTarget)
+		return nil
+	}
+	cmd, err := m.revealPath(node.ResolvedTarget())
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+	}
+	return cmd
+}
+
 // Directory navigation (netrw-style GoTo)
 
 func (m *Model) startGoTo() {
@@ -109,9 +226,9 @@ func (m *Model) startGoTo() {
 	m.clearCompletions()
 }
 
-func (m *Model) doGoTo() {
+func (m *Model) doGoTo() tea.Cmd {
 	if m.inputBuffer == "" {
-		return
+		return nil
 	}
 
 	// Expand ~ to home directory
@@ -121,7 +238,7 @@ func (m *Model) doGoTo() {
 		if err != nil {
 			m.message = fmt.Sprintf("Error: %v", err)
 			m.inputBuffer = ""
-			return
+			return nil
 		}
 		path = filepath.Join(home, path[1:])
 	}
@@ -134,28 +251,43 @@ func (m *Model) doGoTo() {
 		absPath = filepath.Clean(filepath.Join(m.tree.Root.Path, path))
 	}
 
-	m.changeRoot(absPath)
+	// Files are revealed in the tree instead of becoming the root
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		cmd, err := m.revealPath(absPath)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+		}
+		m.inputBuffer = ""
+		return cmd
+	}
+
+	cmd, _ := m.changeRoot(absPath) // Failures are in the status bar
 	m.inputBuffer = ""
+	return cmd
 }
 
-func (m *Model) changeRoot(newPath string) {
+// changeRoot makes newPath the tree root. Failures are shown in the status
+// bar and returned; the old root is kept. The returned command starts the new
+// watcher, since the old one's pending Watch ends when it is closed.
+func (m *Model) changeRoot(newPath string) (tea.Cmd, error) {
 	// Check if path exists and is a directory
 	info, err := os.Stat(newPath)
 	if err != nil {
 		m.message = fmt.Sprintf("Error: %v", err)
-		return
+		return nil, err
 	}
 	if !info.IsDir() {
 		m.message = "Not a directory"
-		return
+		return nil, fmt.Errorf("%s: not a directory", newPath)
 	}
 
 	// Create new tree
 	tree, err := NewFileTree(newPath, m.showHidden)
 	if err != nil {
 		m.message = fmt.Sprintf("Error: %v", err)
-		return
+		return nil, err
 	}
+	m.saveSession() // State of the root being left
 	m.tree = tree
 	m.selected = 0
 	m.scrollOffset = 0
@@ -169,19 +301,34 @@ func (m *Model) changeRoot(newPath string) {
 		m.watcher.Close()
 		m.watcher = nil
 	}
+	m.restoreSession() // Before watching, so restored directories are watched too
 	if m.watcherEnabled {
-		watcher, err := NewWatcher(newPath)
+		watcher, err := m.startWatcher(newPath)
 		if err != nil {
 			// Disable watching if watcher creation fails
 			m.watcherEnabled = false
 			m.message = fmt.Sprintf("→ %s (watch disabled: %v)", newPath, err)
-			return
+			return nil, nil
 		}
 		m.watcher = watcher
-		m.watcher.WatchExpandedDirs(m.tree)
 	}
 
 	m.message = fmt.Sprintf("→ %s", newPath)
+	if m.watcher != nil {
+		return m.watcher.Watch(), nil
+	}
+	return nil, nil
+}
+
+// exitDir is the directory written to --cwd-file on exit: the tree root,
+// or the selected directory when quitting with Q
+func (m *Model) exitDir() string {
+	if m.quitToSelection {
+		if dir := m.getPasteDestination(); dir != "" {
+			return dir
+		}
+	}
+	return m.tree.Root.Path
 }
 
 // Tab completion
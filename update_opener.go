@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Editor and opener operations
+
+// openerDoneMsg is sent when the editor or opener exits
+type openerDoneMsg struct {
+	label string // Shown on success (e.g., "Opened 2 files")
+	err   error
+}
+
+// openTargets returns the files to hand to the editor or opener:
+// the previewed file in preview mode, otherwise the marked files or the selection
+func (m *Model) openTargets() []string {
+	if m.inputMode == ModePreview && m.previewPath != "" {
+		return []string{m.previewPath}
+	}
+
+	if len(m.marked) > 0 {
+		paths := m.getSelectedPaths()
+		sort.Strings(paths)
+		return paths
+	}
+	node := m.tree.GetNode(m.selected)
+	if node == nil {
+		return nil
+	}
+	return []string{node.Path}
+}
+
+// previewEditLine returns the source line (1-based) at the top of the preview,
+// or 0 when the view has no meaningful line (hex, images, rendered documents)
+func (m *Model) previewEditLine() int {
+	if m.inputMode != ModePreview {
+		return 0
+	}
+	if s := m.previewStream; s != nil {
+		if s.hex || s.topLine < 0 {
+			return 0
+		}
+		return int(s.topLine) + 1
+	}
+	if m.previewIsBinary || m.previewIsImage || m.previewIsArchive {
+		return 0
+	}
+	if m.previewRendered {
+		// Rendered Markdown maps back to the source via the current heading
+		if current := m.currentHeadingIndex(); current >= 0 {
+			return m.previewHeadings[current].SrcLine + 1
+		}
+		return 0
+	}
+	return m.previewScroll + 1
+}
+
+// editSelection opens the targets in $VISUAL/$EDITOR
+func (m *Model) editSelection() tea.Cmd {
+	paths := m.openTargets()
+	if len(paths) == 0 {
+		return nil
+	}
+	script := editorScript(editorFromEnv(os.Getenv), paths, m.previewEditLine())
+	return m.execScript(script, "")
+}
+
+// openWithOpener opens the targets with the opener configured for their type
+func (m *Model) openWithOpener() tea.Cmd {
+	var paths []string
+	for _, p := range m.openTargets() {
+		// Ghost nodes (deleted files) cannot be opened
+		if _, err := os.Stat(p); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		m.message = "Nothing to open"
+		return nil
+	}
+
+	label := fmt.Sprintf("Opened %s", paths[0])
+	if len(paths) > 1 {
+		label = fmt.Sprintf("Opened %d files", len(paths))
+	}
+	return m.execScript(openerScript(m.config.Openers, paths), label)
+}
+
+// execScript hands the terminal to a shell script and reports back with openerDoneMsg
+func (m *Model) execScript(script, label string) tea.Cmd {
+	m.execMode = true // Prevent View() from rendering during exec
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = m.tree.Root.Path
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return openerDoneMsg{label: label, err: err}
+	})
+}
+
+// handleOpenerDone refreshes the tree, VCS status and preview after the editor or opener exits
+func (m Model) handleOpenerDone(msg openerDoneMsg) (tea.Model, tea.Cmd) {
+	m.execMode = false
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Error: %v", msg.err)
+	} else {
+		m.message = msg.label
+	}
+
+	m.refreshTreeAndVCS()
+	m.adjustSelection()
+	m.adjustScroll()
+
+	// Reload an edited text preview, keeping the position
+	if m.inputMode == ModePreview && m.previewStream == nil && !m.previewIsImage && !m.previewIsArchive && !m.previewIsBinary {
+		scroll := m.previewScroll
+		rendered := m.previewRendered
+		m.openPreview()
+		if m.previewRendered != rendered {
+			m.togglePreviewRendered()
+		}
+		m.previewScroll = scroll
+		m.clampPreviewScroll()
+	}
+
+	return m, tea.Batch(tea.ClearScreen, m.reloadSplitPreview())
+}
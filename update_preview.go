@@ -17,6 +17,7 @@ import (
 	_ "golang.org/x/image/webp"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/qeesung/image2ascii/convert"
 )
 
@@ -36,7 +37,9 @@ func (m *Model) openPreview() tea.Cmd {
 	// Reset preview state
 	m.previewPath = node.Path
 	m.previewScroll = 0
+	m.previewTextInfo = textInfo{}
 	m.previewIsImage = false
+	m.previewImageSeq = ""
 	m.imageWidth = 0
 	m.imageHeight = 0
 	m.imageFormat = ""
@@ -44,6 +47,18 @@ func (m *Model) openPreview() tea.Cmd {
 	m.previewDiffLines = nil
 	m.previewDiffMap = nil
 	m.previewDiffIndex = -1
+	m.previewBytes = nil
+	m.previewSyntax = nil
+	m.previewHScroll = 0
+	m.clearPreviewSearch()
+	m.resetRenderedPreview()
+	m.resetArchivePreview()
+	m.closeStreamPreview()
+
+	// Archives show a listing of their entries
+	if isArchiveFile(node.Path) {
+		return m.openArchivePreview(node.Path)
+	}
 
 	// Check if image file
 	if isImageFile(node.Path) {
@@ -58,9 +73,16 @@ func (m *Model) openPreview() tea.Cmd {
 		m.imageFormat = imgFormat
 		m.imageSize = imgSize
 
+		// Native encoders draw directly when the terminal's protocol is known
+		if m.imageProtocol.isNative() {
+			if cmd, ok := m.openNativeImagePreview(node.Path); ok {
+				return cmd
+			}
+		}
+
 		// In tmux, use tea.ExecProcess to run chafa directly (bypasses Bubble Tea rendering
 		// which corrupts tmux passthrough escape sequences)
-		if os.Getenv("TMUX") != "" {
+		if inTmux() && m.imageProtocol != imageProtocolASCII {
 			if _, err := exec.LookPath("chafa"); err == nil {
 				m.execMode = true // Prevent View() from rendering during exec
 				return m.execChafaPreview(node.Path)
@@ -90,33 +112,43 @@ func (m *Model) openPreview() tea.Cmd {
 	}
 	defer file.Close()
 
-	limited := &io.LimitedReader{R: file, N: MaxPreviewBytes + 1}
-	content, err := io.ReadAll(limited)
+	// Large files are streamed instead of loaded
+	info, err := file.Stat()
 	if err != nil {
 		m.message = fmt.Sprintf("Error: %v", err)
 		return nil
 	}
+	if info.Size() > MaxPreviewBytes {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(file, head)
+		// Streams show raw bytes, so large UTF-16 files stay in the hex viewer
+		return m.openStreamPreview(node.Path, isBinaryContent(head[:n]) || looksLikeUTF16(head[:n]))
+	}
 
-	truncated := len(content) > MaxPreviewBytes
-	if truncated {
-		content = content[:MaxPreviewBytes]
+	content, err := io.ReadAll(file)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return nil
 	}
 
 	m.previewIsImage = false
 
-	// Check if binary
+	// Binaries open in the hex viewer, which pages through the whole file
 	if isBinaryContent(content) {
-		m.previewIsBinary = true
-		m.previewContent = formatHexPreview(content, len(content))
-	} else {
-		m.previewIsBinary = false
-		m.previewContent = strings.Split(string(content), "\n")
-		// Load diff for text files
-		m.loadFileDiff(node.Path)
+		return m.openStreamPreview(node.Path, true)
 	}
 
-	if truncated {
-		m.message = "Preview truncated (file > 512KB)"
+	m.previewIsBinary = false
+	text, enc := decodeText(content)
+	m.previewTextInfo = analyzeText(text, enc)
+	m.previewContent = strings.Split(text, "\n")
+	m.previewSyntax = syntaxCache.get(node.Path, m.previewContent)
+	// Load diff for text files
+	m.loadFileDiff(node.Path)
+
+	// Data files and tables open in their structured view (raw is one key away)
+	if f := previewFormatFor(node.Path); f == formatData || f == formatTable {
+		m.togglePreviewRendered()
 	}
 
 	m.inputMode = ModePreview
@@ -151,8 +183,10 @@ func (m *Model) closePreview() {
 	m.inputMode = ModeNormal
 	m.previewContent = nil
 	m.previewPath = ""
+	m.previewTextInfo = textInfo{}
 	m.previewScroll = 0
 	m.previewIsImage = false
+	m.previewImageSeq = ""
 	// Reset image metadata
 	m.imageWidth = 0
 	m.imageHeight = 0
@@ -162,11 +196,377 @@ func (m *Model) closePreview() {
 	m.previewDiffLines = nil
 	m.previewDiffMap = nil
 	m.previewDiffIndex = -1
+	// Reset search and highlighting state
+	m.previewBytes = nil
+	m.previewSyntax = nil
+	m.previewHScroll = 0
+	m.clearPreviewSearch()
+	m.resetRenderedPreview()
+	m.resetArchivePreview()
+	m.closeStreamPreview()
+}
+
+// previewTextStatus returns the encoding, line endings and indentation for the status line
+func (m Model) previewTextStatus() string {
+	if m.previewTextInfo.Encoding == "" || m.previewStream != nil {
+		return ""
+	}
+	return " | " + m.previewTextInfo.String()
+}
+
+// Rendered preview (Markdown, structured data, tables)
+
+// canRenderPreview returns true if the current preview has a rendered view
+func (m *Model) canRenderPreview() bool {
+	return !m.previewIsBinary && !m.previewIsImage && m.previewStream == nil && previewFormatFor(m.previewPath) != formatNone
+}
+
+// togglePreviewRendered switches between the raw and rendered views
+func (m *Model) togglePreviewRendered() {
+	if m.previewRendered {
+		m.showRawPreview()
+		return
+	}
+	if !m.canRenderPreview() {
+		m.message = "No rendered view for this file"
+		return
+	}
+
+	// Parse structured formats up front so a parse error keeps the raw view
+	format := previewFormatFor(m.previewPath)
+	raw := []byte(strings.Join(m.previewContent, "\n"))
+	switch format {
+	case formatData:
+		root, err := parseDataFile(m.previewPath, raw)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		expandDataTree(root, DataTreeExpandDepth)
+		m.previewDataRoot = root
+		m.previewDataRoots = []*dataNode{root}
+		m.previewCursor = 0
+	case formatTable:
+		records, err := parseTableData(m.previewPath, raw)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		m.previewTable = records
+	}
+
+	rawScroll := m.previewScroll
+	m.previewRaw = m.previewContent
+	m.previewRawSyntax = m.previewSyntax
+	m.previewRawDiffLines = m.previewDiffLines
+	m.previewRendered = true
+	m.previewFormat = format
+	m.renderPreviewDoc()
+
+	// Keep position: show the section containing the raw scroll position
+	m.previewScroll = 0
+	for _, h := range m.previewHeadings {
+		if h.SrcLine <= rawScroll {
+			m.previewScroll = h.Line
+		}
+	}
+	m.clampPreviewScroll()
+}
+
+// renderPreviewDoc renders the raw content for the current width
+func (m *Model) renderPreviewDoc() {
+	switch m.previewFormat {
+	case formatMarkdown:
+		doc := renderMarkdown(m.previewRaw, m.previewRenderWidth())
+		m.previewContent = doc.Lines
+		m.previewSyntax = doc.Spans
+		m.previewHeadings = doc.Headings
+	case formatData:
+		m.renderDataTreePreview()
+	case formatTable:
+		m.previewHeader, m.previewHeaderSyntax, m.previewContent, m.previewSyntax =
+			layoutTable(m.previewTable, m.previewRenderWidth())
+	}
+
+	// Diff markers and horizontal scroll refer to raw lines
+	m.previewDiffLines = nil
+	m.previewDiffMap = nil
+	m.previewDiffIndex = -1
+	m.previewHScroll = 0
+
+	// Search offsets refer to the old lines; re-run on the new ones
+	if m.hasPreviewSearch() {
+		m.runPreviewSearch()
+	}
+}
+
+// rerenderPreview re-renders the rendered view (e.g., after resize), keeping the current section
+func (m *Model) rerenderPreview() {
+	if !m.previewRendered {
+		return
+	}
+	current := m.currentHeadingIndex()
+	scroll := m.previewScroll
+	m.renderPreviewDoc()
+	if current >= 0 && current < len(m.previewHeadings) {
+		m.previewScroll = m.previewHeadings[current].Line
+	} else {
+		m.previewScroll = scroll
+	}
+	m.clampPreviewScroll()
+}
+
+// showRawPreview restores the raw view
+func (m *Model) showRawPreview() {
+	// Map the rendered position back to the source via the current heading
+	srcLine := 0
+	if current := m.currentHeadingIndex(); current >= 0 {
+		srcLine = m.previewHeadings[current].SrcLine
+	}
+
+	m.previewContent = m.previewRaw
+	m.previewSyntax = m.previewRawSyntax
+	m.previewDiffLines = m.previewRawDiffLines
+	m.previewDiffMap = nil
+	if len(m.previewDiffLines) > 0 {
+		m.previewDiffMap = make(map[int]DiffLine)
+		for _, dl := range m.previewDiffLines {
+			m.previewDiffMap[dl.Line] = dl
+		}
+	}
+	m.previewDiffIndex = -1
+	m.resetRenderedPreview()
+
+	m.previewScroll = srcLine
+	m.clampPreviewScroll()
+	if m.hasPreviewSearch() {
+		m.runPreviewSearch()
+	}
+}
+
+// resetRenderedPreview clears rendered view state
+func (m *Model) resetRenderedPreview() {
+	m.previewRendered = false
+	m.previewFormat = formatNone
+	m.previewRaw = nil
+	m.previewRawSyntax = nil
+	m.previewRawDiffLines = nil
+	m.previewHeadings = nil
+	m.previewShowTOC = false
+	m.previewDataRoot = nil
+	m.previewDataRoots = nil
+	m.previewDataRows = nil
+	m.previewCursor = 0
+	m.previewQuery = ""
+	m.previewQueryErr = ""
+	m.previewTable = nil
+	m.previewHeader = nil
+	m.previewHeaderSyntax = nil
+}
+
+// togglePreviewTOC shows or hides the table of contents sidebar
+func (m *Model) togglePreviewTOC() {
+	if !m.previewRendered {
+		return
+	}
+	if len(m.previewHeadings) == 0 {
+		m.message = "No headings"
+		return
+	}
+	m.previewShowTOC = !m.previewShowTOC
+	m.rerenderPreview()
+}
+
+// currentHeadingIndex returns the index of the heading whose section is at the top of the view
+func (m *Model) currentHeadingIndex() int {
+	current := -1
+	for i, h := range m.previewHeadings {
+		if h.Line > m.previewScroll {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// jumpToHeading scrolls so the given heading is at the top of the view
+func (m *Model) jumpToHeading(index int) {
+	if index < 0 || index >= len(m.previewHeadings) {
+		return
+	}
+	m.previewScroll = m.previewHeadings[index].Line
+	m.clampPreviewScroll()
+}
+
+// jumpToNextHeading jumps to the next heading below the top of the view
+func (m *Model) jumpToNextHeading() {
+	for i, h := range m.previewHeadings {
+		if h.Line > m.previewScroll {
+			m.jumpToHeading(i)
+			return
+		}
+	}
+}
+
+// jumpToPrevHeading jumps to the previous heading above the top of the view
+func (m *Model) jumpToPrevHeading() {
+	for i := len(m.previewHeadings) - 1; i >= 0; i-- {
+		if m.previewHeadings[i].Line < m.previewScroll {
+			m.jumpToHeading(i)
+			return
+		}
+	}
+	m.previewScroll = 0
+}
+
+// previewTOCWidth returns the width of the table of contents sidebar (0 = hidden)
+func (m Model) previewTOCWidth() int {
+	if !m.previewShowTOC {
+		return 0
+	}
+	width := m.width / 3
+	if width > MaxTOCWidth {
+		width = MaxTOCWidth
+	}
+	return width
+}
+
+// previewRenderWidth returns the width available for rendered content
+func (m Model) previewRenderWidth() int {
+	width := m.width - 2
+	if tocWidth := m.previewTOCWidth(); tocWidth > 0 {
+		width -= tocWidth + 2 // Separator and space
+	}
+	return width
+}
+
+// previewNumWidth returns the width of the line number gutter
+func (m Model) previewNumWidth() int {
+	numWidth := 4
+	if m.previewStream != nil {
+		firstLine := int(m.previewStream.topLine) + 1
+		numWidth = max(numWidth, len(fmt.Sprint(firstLine+len(m.previewContent))))
+	}
+	return numWidth
+}
+
+// previewContentWidth returns the columns available to a preview line
+func (m Model) previewContentWidth() int {
+	if m.previewRendered || m.previewIsArchive {
+		return m.previewRenderWidth()
+	}
+	// 2 for marker, line number + 1, 1 for space
+	return max(m.width-m.previewNumWidth()-4, 1)
+}
+
+// maxPreviewHScroll returns the horizontal scroll that brings the end of the
+// longest visible line to the right edge of the pane
+func (m Model) maxPreviewHScroll() int {
+	longest := 0
+	for _, line := range m.previewHeader {
+		longest = max(longest, displayColumn(line, len(line)))
+	}
+	for i := m.previewScroll; i < len(m.previewContent) && i < m.previewScroll+m.height; i++ {
+		longest = max(longest, displayColumn(m.previewContent[i], len(m.previewContent[i])))
+	}
+	return max(longest-m.previewContentWidth(), 0)
+}
+
+// previewTOCStart returns the first visible TOC entry, keeping the current heading in view
+func (m Model) previewTOCStart() int {
+	visibleHeight := m.height - 2
+	current := m.currentHeadingIndex()
+	if visibleHeight < 1 || current < visibleHeight {
+		return 0
+	}
+	return current - visibleHeight + 1
+}
+
+// updatePreviewMouseClick jumps to a heading when a TOC entry is clicked
+func (m Model) updatePreviewMouseClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
+	if msg.Button != tea.MouseLeft || !m.previewShowTOC {
+		return m, nil
+	}
+	// Content area starts at row 1 (after title)
+	if msg.Y < 1 || msg.X >= m.previewTOCWidth() {
+		return m, nil
+	}
+	m.jumpToHeading(m.previewTOCStart() + msg.Y - 1)
+	return m, nil
+}
+
+// updatePreviewCursorKey handles cursor movement for listing views (data tree, archive).
+// Returns false for keys that don't move the cursor.
+func (m *Model) updatePreviewCursorKey(msg tea.KeyMsg) bool {
+	visibleHeight := m.height - 4
+
+	switch msg.String() {
+	case "up", "k":
+		m.movePreviewCursor(-1)
+	case "down", "j":
+		m.movePreviewCursor(1)
+	case "pgup", "b":
+		m.movePreviewCursor(-visibleHeight)
+	case "pgdown", "f":
+		m.movePreviewCursor(visibleHeight)
+	case "g":
+		m.movePreviewCursor(-len(m.previewContent))
+	case "G":
+		m.movePreviewCursor(len(m.previewContent))
+	case "n", "N":
+		// Search matches move the cursor along with the view
+		if !m.hasPreviewSearch() {
+			return true
+		}
+		if msg.String() == "n" {
+			m.jumpToNextMatch()
+		} else {
+			m.jumpToPrevMatch()
+		}
+		if m.previewMatchIndex >= 0 {
+			m.previewCursor = m.previewMatches[m.previewMatchIndex].Line
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// movePreviewCursor moves the cursor by delta lines and keeps it visible
+func (m *Model) movePreviewCursor(delta int) {
+	m.previewCursor += delta
+	m.previewCursor = max(min(m.previewCursor, len(m.previewContent)-1), 0)
+
+	visibleHeight := max(m.height-4, 1)
+	if m.previewCursor < m.previewScroll {
+		m.previewScroll = m.previewCursor
+	} else if m.previewCursor >= m.previewScroll+visibleHeight {
+		m.previewScroll = m.previewCursor - visibleHeight + 1
+	}
+	m.clampPreviewScroll()
+}
+
+// clampPreviewScroll keeps the preview scroll within content bounds
+func (m *Model) clampPreviewScroll() {
+	maxScroll := len(m.previewContent) - (m.height - 4 - len(m.previewHeader))
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if m.previewScroll > maxScroll {
+		m.previewScroll = maxScroll
+	}
+	if m.previewScroll < 0 {
+		m.previewScroll = 0
+	}
 }
 
 // clearKittyImages sends escape sequence to delete all Kitty graphics
 func clearKittyImages() tea.Cmd {
-	return tea.Printf("\x1b_Ga=d,d=A\x1b\\")
+	seq := kittyDeleteSequence()
+	if inTmux() {
+		seq = ansi.TmuxPassthrough(seq)
+	}
+	return tea.Raw(seq)
 }
 
 // Image file detection and metadata
@@ -263,8 +663,8 @@ func (m *Model) execChafaPreview(path string) tea.Cmd {
 	// Title uses cyan background (matching previewTitleStyle).
 	// Use ANSI escape sequences instead of 'clear' command to reduce flicker:
 	// \033[?25l = hide cursor, \033[H = home, \033[2J = clear screen
-	script := fmt.Sprintf(`printf '\033[?25l\033[H\033[2J\033[46;30m%%s\033[0m\n\n' "$INFO"; chafa --format kitty --passthrough tmux --animate off --size %dx%d -- "$IMAGE_PATH"; printf '\n\033[90mPress any key to close...\033[0m'; read -n 1; printf '\033[?25h'`,
-		width, height)
+	script := fmt.Sprintf(`printf '\033[?25l\033[H\033[2J\033[46;30m%%s\033[0m\n\n' "$INFO"; chafa --format %s --passthrough tmux --animate off --size %dx%d -- "$IMAGE_PATH"; printf '\n\033[90mPress any key to close...\033[0m'; read -n 1; printf '\033[?25h'`,
+		chafaFormat(m.imageProtocol), width, height)
 
 	cmd := exec.Command("bash", "-c", script)
 	cmd.Env = append(os.Environ(), "INFO="+info, "IMAGE_PATH="+path)
@@ -289,9 +689,9 @@ func (m *Model) loadImagePreview(path string) ([]string, error) {
 
 	// Try chafa first (high quality with Kitty protocol)
 	// Note: tmux uses execChafaPreview instead (View() corrupts tmux passthrough)
-	if _, err := exec.LookPath("chafa"); err == nil {
+	if _, err := exec.LookPath("chafa"); err == nil && m.imageProtocol != imageProtocolASCII {
 		args := []string{
-			"--format", "kitty",
+			"--format", chafaFormat(m.imageProtocol),
 			"--animate", "off",
 			"--polite", "on",
 			"--size", fmt.Sprintf("%dx%d", width, height),
@@ -309,6 +709,18 @@ func (m *Model) loadImagePreview(path string) ([]string, error) {
 	return m.loadASCIIPreview(path, width, height)
 }
 
+// chafaFormat returns chafa's --format for the image protocol (Kitty when unknown)
+func chafaFormat(p imageProtocol) string {
+	switch p {
+	case imageProtocolSixel:
+		return "sixels"
+	case imageProtocolITerm2:
+		return "iterm"
+	default:
+		return "kitty"
+	}
+}
+
 func (m *Model) loadASCIIPreview(path string, width, height int) ([]string, error) {
 	converter := convert.NewImageConverter()
 	opts := convert.DefaultOptions
@@ -328,6 +740,11 @@ func (m *Model) loadASCIIPreview(path string, width, height int) ([]string, erro
 // Binary content detection and hex preview
 
 func isBinaryContent(content []byte) bool {
+	// UTF-16 text has zero bytes but is decoded for preview
+	if looksLikeUTF16(content) {
+		return false
+	}
+
 	// Check first 512 bytes for null bytes or high ratio of non-printable chars
 	checkLen := len(content)
 	if checkLen > 512 {
@@ -353,50 +770,40 @@ func isBinaryContent(content []byte) bool {
 	return float64(nonPrintable)/float64(checkLen) > 0.3
 }
 
-func formatHexPreview(content []byte, originalSize int) []string {
+// formatHexDump formats content as hex dump lines of width bytes,
+// numbering offsets from offset
+func formatHexDump(content []byte, offset int64, width int) []string {
 	var lines []string
-	maxBytes := MaxHexPreviewBytes
-	truncated := originalSize > maxBytes
-
-	if len(content) > maxBytes {
-		content = content[:maxBytes]
+	for i := 0; i < len(content); i += width {
+		end := min(i+width, len(content))
+		lines = append(lines, formatHexLine(content[i:end], offset+int64(i), width))
 	}
+	return lines
+}
 
-	for i := 0; i < len(content); i += 16 {
-		end := i + 16
-		if end > len(content) {
-			end = len(content)
-		}
-		chunk := content[i:end]
-
-		// Hex part
-		hexParts := make([]string, len(chunk))
-		for j, b := range chunk {
-			hexParts[j] = fmt.Sprintf("%02x", b)
-		}
-		hexStr := strings.Join(hexParts, " ")
-
-		// Pad hex string
-		for len(hexStr) < 47 {
-			hexStr += " "
-		}
-
-		// ASCII part
-		ascii := make([]byte, len(chunk))
-		for j, b := range chunk {
-			if b >= 32 && b < 127 {
-				ascii[j] = b
-			} else {
-				ascii[j] = '.'
-			}
-		}
+// formatHexLine formats one hex dump line: offset, hex bytes and ASCII
+func formatHexLine(chunk []byte, offset int64, width int) string {
+	// Hex part
+	hexParts := make([]string, len(chunk))
+	for j, b := range chunk {
+		hexParts[j] = fmt.Sprintf("%02x", b)
+	}
+	hexStr := strings.Join(hexParts, " ")
 
-		lines = append(lines, fmt.Sprintf("%08x  %s  %s", i, hexStr, string(ascii)))
+	// Pad hex string
+	for len(hexStr) < width*3-1 {
+		hexStr += " "
 	}
 
-	if truncated {
-		lines = append(lines, "... (truncated)")
+	// ASCII part
+	ascii := make([]byte, len(chunk))
+	for j, b := range chunk {
+		if b >= 32 && b < 127 {
+			ascii[j] = b
+		} else {
+			ascii[j] = '.'
+		}
 	}
 
-	return lines
+	return fmt.Sprintf("%08x  %s  %s", offset, hexStr, string(ascii))
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Recent changes operations
+
+// jumpToRecent selects the most recently created or modified file under the root
+func (m *Model) jumpToRecent() tea.Cmd {
+	for _, change := range m.recent.under(m.tree.Root.Path) {
+		if change.Op == FileRemoved {
+			continue
+		}
+		cmd, err := m.revealPath(change.Path)
+		if err != nil {
+			continue // Gone since, or hidden by an ignore rule
+		}
+		m.message = fmt.Sprintf("→ %s (%s ago)", m.relativePath(change.Path), formatAge(time.Since(change.Time)))
+		return cmd
+	}
+	m.message = "No recent changes"
+	return nil
+}
+
+// startRecentList opens the recent changes panel
+func (m *Model) startRecentList() {
+	m.recentList = m.recent.under(m.tree.Root.Path)
+	if len(m.recentList) == 0 {
+		m.message = "No recent changes"
+		return
+	}
+	m.recentIndex = 0
+	m.inputMode = ModeRecent
+}
+
+func (m Model) updateRecentMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k", "ctrl+p":
+		if m.recentIndex > 0 {
+			m.recentIndex--
+		}
+	case "down", "j", "ctrl+n":
+		if m.recentIndex < len(m.recentList)-1 {
+			m.recentIndex++
+		}
+	case "enter":
+		change := m.recentList[m.recentIndex]
+		m.inputMode = ModeNormal
+		m.recentList = nil
+		cmd, err := m.revealPath(change.Path)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+		}
+		return m, cmd
+	case "esc", "q":
+		m.inputMode = ModeNormal
+		m.recentList = nil
+		m.message = "Cancelled"
+	}
+	return m, nil
+}
+
+// relativePath shows path relative to the root when it is inside it
+func (m Model) relativePath(path string) string {
+	if rel, err := filepath.Rel(m.tree.Root.Path, path); err == nil && isWithin(m.tree.Root.Path, path) {
+		return rel
+	}
+	return collapseHomePath(path)
+}
+
+// formatAge renders a duration the way the recent changes panel shows it (5s, 3m, 2h)
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+}
+
+// recentOpSymbol marks created (+), modified (~) and removed (-) entries
+func recentOpSymbol(op FileChangeOp) string {
+	switch op {
+	case FileCreated:
+		return "+"
+	case FileRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// renderRecentList renders the recent changes panel for ModeRecent
+func (m Model) renderRecentList(maxContentWidth int) string {
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	entryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Width(maxContentWidth - 1)
+	selectedEntryStyle := entryStyle.Background(lipgloss.Color("238"))
+
+	lines := []string{" Recent changes:"}
+
+	// Keep the selection visible
+	now := time.Now()
+	start := max(0, min(m.recentIndex-MaxCompletionVisible/2, len(m.recentList)-MaxCompletionVisible))
+	end := min(start+MaxCompletionVisible, len(m.recentList))
+	for i := start; i < end; i++ {
+		c := m.recentList[i]
+		text := fmt.Sprintf(" %s %4s  %s %s", c.Time.Format("15:04:05"), formatAge(now.Sub(c.Time)), recentOpSymbol(c.Op), m.relativePath(c.Path))
+		text = ansi.Truncate(text, maxContentWidth-1, "…")
+		if i == m.recentIndex {
+			lines = append(lines, selectedEntryStyle.Render(text))
+		} else {
+			lines = append(lines, entryStyle.Render(text))
+		}
+	}
+
+	hint := fmt.Sprintf(" %d/%d j/k:move Enter:reveal Esc:close", m.recentIndex+1, len(m.recentList))
+	if lipgloss.Width(hint) > maxContentWidth {
+		hint = ansi.Truncate(hint, maxContentWidth-1, "") + "…"
+	}
+	lines = append(lines, hintStyle.Render(hint))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,69 @@
+package main
+
+// captureSession records the tree state for the current root
+func (m *Model) captureSession() *sessionState {
+	state := &sessionState{
+		Root:       m.tree.Root.Path,
+		Expanded:   expandedDirs(m.tree),
+		Scroll:     m.scrollOffset,
+		ShowHidden: m.showHidden,
+		VCSType:    vcsTypeName(m.vcsForceType),
+		Marked:     sortedPaths(m.marked),
+	}
+	if node := m.tree.GetNode(m.selected); node != nil && !node.IsGhost {
+		state.Selected = node.Path
+	}
+	return state
+}
+
+// saveSession writes the state for the current root when sessions are enabled
+func (m *Model) saveSession() error {
+	if !m.sessionEnabled || m.tree == nil {
+		return nil
+	}
+	return saveSession(m.captureSession())
+}
+
+// restoreSession applies the saved state for the current root, if any.
+// Unreadable state files are ignored: the tree simply starts fresh.
+func (m *Model) restoreSession() {
+	if !m.sessionEnabled {
+		return
+	}
+	state, err := loadSession(m.tree.Root.Path)
+	if err != nil || state == nil {
+		return
+	}
+
+	if state.ShowHidden != m.showHidden {
+		m.showHidden = state.ShowHidden
+		m.tree.SetShowHidden(m.showHidden)
+	}
+	if forceType := parseVCSType(state.VCSType); forceType != m.vcsForceType {
+		m.vcsForceType = forceType
+		m.vcsRepo = NewVCSRepoWithType(m.tree.Root.Path, forceType)
+	}
+
+	for _, dir := range state.Expanded {
+		if idx, err := m.tree.Reveal(dir); err == nil && idx >= 0 {
+			m.tree.Expand(idx)
+		}
+	}
+	m.tree.AddGhostNodes(m.vcsRepo.GetDeletedFiles())
+	if m.watcher != nil {
+		m.watcher.WatchExpandedDirs(m.tree)
+	}
+
+	for _, path := range state.Marked {
+		m.marked[path] = true
+	}
+
+	m.selected = 0
+	if idx := m.tree.IndexOf(state.Selected); idx >= 0 {
+		m.selected = idx
+	}
+	m.scrollOffset = state.Scroll
+	if m.scrollOffset > m.selected {
+		m.scrollOffset = m.selected
+	}
+}
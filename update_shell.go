@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Shell prompt and output pane operations
+
+// shellDoneMsg is sent when a shell command finishes
+type shellDoneMsg struct {
+	id          int // Generation of the command (output of replaced runs is dropped)
+	interactive bool
+	output      string
+	exitCode    int
+	err         error
+}
+
+// startShell opens the ! prompt
+func (m *Model) startShell() {
+	m.inputBuffer = ""
+	m.inputMode = ModeShell
+	m.shellHistoryIndex = len(m.shellHistory)
+	m.shellDraft = ""
+}
+
+// shellContext collects the paths for placeholders from the current selection
+func (m *Model) shellContext() shellContext {
+	ctx := shellContext{
+		Marked:  m.getSelectedPaths(),
+		Dir:     m.getPasteDestination(),
+		Root:    m.tree.Root.Path,
+		WorkDir: m.tree.Root.Path,
+	}
+	if node := m.tree.GetNode(m.selected); node != nil {
+		ctx.Selected = node.Path
+	}
+	sort.Strings(ctx.Marked)
+	if m.vcsRepo.IsInsideRepo() && m.vcsRepo.GetRoot() != "" {
+		ctx.Root = m.vcsRepo.GetRoot()
+	}
+	return ctx
+}
+
+func (m Model) updateShellMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		command := strings.TrimSpace(m.inputBuffer)
+		m.inputBuffer = ""
+		m.inputMode = ModeNormal
+		if command == "" {
+			return m, nil
+		}
+		m.shellHistory = addShellHistory(m.shellHistory, command)
+		return m, m.runShell(command)
+	case "esc":
+		m.inputBuffer = ""
+		m.inputMode = ModeNormal
+	case "up", "ctrl+p":
+		m.browseShellHistory(-1)
+	case "down", "ctrl+n":
+		m.browseShellHistory(1)
+	case "backspace":
+		if len(m.inputBuffer) > 0 {
+			runes := []rune(m.inputBuffer)
+			m.inputBuffer = string(runes[:len(runes)-1])
+		}
+	default:
+		if text := msg.Key().Text; text != "" {
+			m.inputBuffer += text
+		}
+	}
+	return m, nil
+}
+
+// browseShellHistory moves through the history; past the newest entry the
+// input typed before browsing comes back
+func (m *Model) browseShellHistory(delta int) {
+	if len(m.shellHistory) == 0 {
+		return
+	}
+	if m.shellHistoryIndex == len(m.shellHistory) {
+		m.shellDraft = m.inputBuffer
+	}
+	m.shellHistoryIndex = max(0, min(len(m.shellHistory), m.shellHistoryIndex+delta))
+	if m.shellHistoryIndex == len(m.shellHistory) {
+		m.inputBuffer = m.shellDraft
+	} else {
+		m.inputBuffer = m.shellHistory[m.shellHistoryIndex]
+	}
+}
+
+// runShell expands and runs a command from the prompt. A leading "!" runs it
+// interactively on the terminal; otherwise its output opens in the output pane.
+func (m *Model) runShell(command string) tea.Cmd {
+	if rest, ok := strings.CutPrefix(command, "!"); ok {
+		script := expandShellCommand(strings.TrimSpace(rest), m.shellContext())
+		m.execMode = true // Prevent View() from rendering during exec
+		cmd := exec.Command("sh", "-c", script)
+		cmd.Dir = m.tree.Root.Path
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return shellDoneMsg{interactive: true, exitCode: exitStatus(err), err: startError(err)}
+		})
+	}
+
+	m.shellCommand = command
+	m.shellScript = expandShellCommand(command, m.shellContext())
+	return m.startShellScript()
+}
+
+// startShellScript runs the current script in the background and opens the output pane
+func (m *Model) startShellScript() tea.Cmd {
+	if m.shellCancel != nil {
+		m.shellCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.shellCancel = cancel
+	m.shellID++
+	m.shellRunning = true
+	m.shellOutput = nil
+	m.shellScroll = 0
+	m.inputMode = ModeShellOutput
+
+	id, script, dir := m.shellID, m.shellScript, m.tree.Root.Path
+	return func() tea.Msg {
+		output, code, err := runShellScript(ctx, script, dir)
+		return shellDoneMsg{id: id, output: output, exitCode: code, err: err}
+	}
+}
+
+// handleShellDone shows the result and refreshes the tree and VCS status,
+// since the command may have changed files
+func (m Model) handleShellDone(msg shellDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.interactive {
+		m.execMode = false
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error: %v", msg.err)
+		} else {
+			m.message = fmt.Sprintf("Command finished (%s)", exitStatusText(msg.exitCode))
+		}
+	} else {
+		if msg.id != m.shellID {
+			return m, nil
+		}
+		m.shellRunning = false
+		m.shellCancel = nil
+		m.shellExitCode = msg.exitCode
+		m.shellOutput = shellOutputLines(msg.output)
+		if msg.err != nil {
+			m.shellOutput = append(m.shellOutput, fmt.Sprintf("Error: %v", msg.err))
+		}
+		// Show the end of the output, where summaries usually are
+		m.shellScroll = len(m.shellOutput)
+		m.clampShellScroll()
+		if m.inputMode != ModeShellOutput {
+			m.message = fmt.Sprintf("%s (%s)", m.shellCommand, exitStatusText(msg.exitCode))
+		}
+	}
+
+	m.refreshTreeAndVCS()
+	m.adjustSelection()
+	m.adjustScroll()
+	if msg.interactive {
+		return m, tea.Batch(tea.ClearScreen, m.reloadSplitPreview())
+	}
+	return m, m.reloadSplitPreview()
+}
+
+func (m Model) updateShellOutputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.shellVisibleHeight()
+
+	switch msg.String() {
+	case "q", "esc":
+		// Closing stops a command that is still running
+		if m.shellRunning && m.shellCancel != nil {
+			m.shellCancel()
+		}
+		m.inputMode = ModeNormal
+	case "r":
+		if !m.shellRunning && m.shellScript != "" {
+			return m, m.startShellScript()
+		}
+	case "!":
+		m.startShell()
+	case "up", "k":
+		m.shellScroll--
+	case "down", "j":
+		m.shellScroll++
+	case "pgup", "b":
+		m.shellScroll -= page
+	case "pgdown", "f", "space", " ":
+		m.shellScroll += page
+	case "g":
+		m.shellScroll = 0
+	case "G":
+		m.shellScroll = len(m.shellOutput)
+	}
+	m.clampShellScroll()
+	return m, nil
+}
+
+// shellVisibleHeight is the number of output rows (title and status bar excluded)
+func (m *Model) shellVisibleHeight() int {
+	if h := m.height - 2; h > 0 {
+		return h
+	}
+	return 10
+}
+
+func (m *Model) clampShellScroll() {
+	maxScroll := max(0, len(m.shellOutput)-m.shellVisibleHeight())
+	m.shellScroll = max(0, min(m.shellScroll, maxScroll))
+}
+
+// renderShellOutput renders the output pane of the last shell command
+func (m Model) renderShellOutput() string {
+	var b strings.Builder
+
+	b.WriteString(previewTitleStyle.Render(" $ " + m.shellCommand + " "))
+	b.WriteString("\n")
+
+	visibleHeight := m.shellVisibleHeight()
+	for row := 0; row < visibleHeight; row++ {
+		i := m.shellScroll + row
+		if i < len(m.shellOutput) {
+			b.WriteString(renderLine(m.shellOutput[i], nil, lipgloss.NewStyle(), 0, m.width))
+		}
+		b.WriteString("\n")
+	}
+
+	status := " Running… | q/Esc:stop "
+	if !m.shellRunning {
+		position := "No output"
+		if total := len(m.shellOutput); total > 0 {
+			last := min(m.shellScroll+visibleHeight, total)
+			position = fmt.Sprintf("Lines %d-%d/%d", m.shellScroll+1, last, total)
+		}
+		status = fmt.Sprintf(" %s | %s | j/k:scroll r:rerun !:new q:close ", exitStatusText(m.shellExitCode), position)
+	}
+	if lipgloss.Width(status) > m.width && m.width > 1 {
+		status = ansi.Truncate(status, m.width-1, "") + "…"
+	}
+	b.WriteString(previewStatusStyle.Width(m.width).Render(status))
+
+	return b.String()
+}
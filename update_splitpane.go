@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Split-pane preview operations
+
+// splitPreviewTickMsg fires when the selection has been still for the debounce interval
+type splitPreviewTickMsg struct {
+	id int
+}
+
+// splitPreviewLoadedMsg carries content loaded in the background
+type splitPreviewLoadedMsg struct {
+	id      int
+	content splitContent
+}
+
+// toggleSplitPane shows or hides the preview pane next to the tree
+func (m *Model) toggleSplitPane() tea.Cmd {
+	m.splitPane = !m.splitPane
+	m.splitPath = ""
+	m.splitContent = nil
+	m.splitScroll = 0
+	if !m.splitPane {
+		m.splitID++ // Drop loads still in flight
+		return nil
+	}
+	if m.splitPercent == 0 {
+		m.splitPercent = SplitDefaultPercent
+	}
+	return m.syncSplitPreview()
+}
+
+// resizeSplitPane moves the divider by delta percent of the terminal width
+func (m *Model) resizeSplitPane(delta int) {
+	m.splitPercent = min(max(m.splitPercent+delta, SplitMinPercent), SplitMaxPercent)
+	m.message = fmt.Sprintf("Tree width: %d%%", m.splitPercent)
+}
+
+// treeWidth returns the width of the tree column
+func (m Model) treeWidth() int {
+	if !m.splitPane {
+		return m.width
+	}
+	return m.width * m.splitPercent / 100
+}
+
+// splitPaneWidth returns the width of the preview pane (right of the divider)
+func (m Model) splitPaneWidth() int {
+	return m.width - m.treeWidth() - 1
+}
+
+// syncSplitPreview schedules a load when the selection moved to another path.
+// Loads are debounced so holding j/k does not read every file passed over.
+func (m *Model) syncSplitPreview() tea.Cmd {
+	if !m.splitPane {
+		return nil
+	}
+	node := m.tree.GetNode(m.selected)
+	if node == nil || node.Path == m.splitPath {
+		return nil
+	}
+
+	m.splitPath = node.Path
+	m.splitScroll = 0
+	m.splitID++
+	id := m.splitID
+	return tea.Tick(SplitPreviewDebounceMs*time.Millisecond, func(time.Time) tea.Msg {
+		return splitPreviewTickMsg{id: id}
+	})
+}
+
+// reloadSplitPreview reloads the pane (e.g., after the file system changed)
+func (m *Model) reloadSplitPreview() tea.Cmd {
+	scroll := m.splitScroll
+	m.splitPath = ""
+	cmd := m.syncSplitPreview()
+	m.splitScroll = scroll
+	return cmd
+}
+
+// stepSplitPreview starts the background load once the debounce interval passed
+func (m *Model) stepSplitPreview(msg splitPreviewTickMsg) tea.Cmd {
+	if msg.id != m.splitID || !m.splitPane {
+		return nil
+	}
+	path, showHidden, rows := m.splitPath, m.showHidden, m.height
+	return func() tea.Msg {
+		return splitPreviewLoadedMsg{id: msg.id, content: loadSplitContent(path, showHidden, rows)}
+	}
+}
+
+// applySplitPreview shows loaded content unless the selection moved on meanwhile
+func (m *Model) applySplitPreview(msg splitPreviewLoadedMsg) {
+	if msg.id != m.splitID || !m.splitPane {
+		return
+	}
+	content := msg.content
+	m.splitContent = &content
+	m.clampSplitScroll()
+}
+
+// scrollSplitPane scrolls the pane content by delta rows
+func (m *Model) scrollSplitPane(delta int) {
+	m.splitScroll += delta
+	m.clampSplitScroll()
+}
+
+// clampSplitScroll keeps the pane scroll within the loaded content
+func (m *Model) clampSplitScroll() {
+	maxScroll := 0
+	if c := m.splitContent; c != nil {
+		rows := len(c.Lines)
+		if c.IsDir {
+			rows = len(c.Entries)
+		}
+		maxScroll = max(rows-1, 0)
+	}
+	m.splitScroll = min(max(m.splitScroll, 0), maxScroll)
+}
+
+// updateSplitPaneKey handles pane keys in normal mode; returns false if not consumed
+func (m *Model) updateSplitPaneKey(key string) bool {
+	if !m.splitPane {
+		return false
+	}
+	switch key {
+	case "<":
+		m.resizeSplitPane(-SplitResizeStep)
+	case ">":
+		m.resizeSplitPane(SplitResizeStep)
+	case "J":
+		m.scrollSplitPane(1)
+	case "K":
+		m.scrollSplitPane(-1)
+	default:
+		return false
+	}
+	return true
+}
+
+// renderSplitPane renders the preview pane as height rows of exactly width columns
+func (m Model) renderSplitPane(width, height int) []string {
+	rows := make([]string, 0, height)
+	add := func(line string) {
+		if len(rows) < height {
+			rows = append(rows, fitWidth(line, width))
+		}
+	}
+
+	c := m.splitContent
+	title := filepath.Base(m.splitPath)
+	switch {
+	case m.splitPath == "":
+		title = ""
+	case c == nil || c.Path != m.splitPath:
+		title += " (loading)"
+	case c.Info != "":
+		title += " (" + c.Info + ")"
+	}
+	add(previewTitleStyle.Render(ansi.Truncate(" "+title, width, "…")))
+
+	if c == nil {
+		return padRows(rows, width, height)
+	}
+	if c.Err != nil {
+		add(gitDeletedStyle.Render(ansi.Truncate(fmt.Sprintf(" Error: %v", c.Err), width, "…")))
+		return padRows(rows, width, height)
+	}
+
+	if c.IsDir {
+		if summary := vcsSummary(c.Entries, m.vcsRepo.GetStatus); summary != "" {
+			add(lineNumStyle.Render(ansi.Truncate(" "+summary, width, "…")))
+		}
+		if len(c.Entries) == 0 {
+			add(lineNumStyle.Render(" (empty)"))
+		}
+		for _, e := range c.Entries[min(m.splitScroll, len(c.Entries)):] {
+			if len(rows) >= height {
+				break
+			}
+			add(m.renderSplitEntry(e, width))
+		}
+		return padRows(rows, width, height)
+	}
+
+	gutter := len(fmt.Sprintf("%d", len(c.Lines))) + 1
+	for i := m.splitScroll; i < len(c.Lines) && len(rows) < height; i++ {
+		var spans []textSpan
+		if i < len(c.Spans) {
+			spans = c.Spans[i]
+		}
+		num := lineNumStyle.Render(fmt.Sprintf("%*d ", gutter, i+1))
+		if c.Spans == nil {
+			num = " " // Hex dumps and listings carry their own offsets
+		}
+		add(num + renderLine(c.Lines[i], spans, lipgloss.NewStyle(), 0, width-lipgloss.Width(num)))
+	}
+	return padRows(rows, width, height)
+}
+
+// renderSplitEntry renders a directory entry in the pane, colored by VCS status
+func (m Model) renderSplitEntry(e splitEntry, width int) string {
+	icon := getFileIconByExt(e.Name)
+	style := fileStyle
+	if e.IsDir {
+		icon = icons.FolderClosed
+		style = dirStyle
+	}
+	if s, ok := vcsStatusStyle(m.vcsRepo.GetStatus(e.Path)); ok {
+		style = s
+	}
+	return style.Render(ansi.Truncate(fmt.Sprintf(" %s %s", icon, e.Name), width, "…"))
+}
+
+// fitWidth truncates or pads a rendered line to exactly width columns
+func fitWidth(line string, width int) string {
+	line = ansi.Truncate(line, width, "…")
+	if w := lipgloss.Width(line); w < width {
+		line += strings.Repeat(" ", width-w)
+	}
+	return line
+}
+
+// padRows fills rows with blank lines up to height
+func padRows(rows []string, width, height int) []string {
+	for len(rows) < height {
+		rows = append(rows, strings.Repeat(" ", width))
+	}
+	return rows
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Streaming preview operations (files larger than MaxPreviewBytes)
+
+// streamIndexMsg runs the next background step of building the line index
+type streamIndexMsg struct {
+	stream *streamView
+}
+
+// streamFollowMsg checks a followed file for new data
+type streamFollowMsg struct {
+	stream *streamView
+	id     int
+}
+
+// openStreamPreview pages a file from a memory map instead of loading it
+func (m *Model) openStreamPreview(path string, hex bool) tea.Cmd {
+	p, err := openPagedFile(path)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+
+	m.previewStream = newStreamView(p, hex)
+	m.previewIsBinary = hex
+	m.previewIsImage = false
+	m.previewSyntax = nil
+	m.loadStreamWindow()
+	m.inputMode = ModePreview
+	return m.streamIndexCmd()
+}
+
+// closeStreamPreview unmaps the streamed file
+func (m *Model) closeStreamPreview() {
+	if m.previewStream != nil {
+		m.previewStream.file.Close()
+		m.previewStream = nil
+	}
+}
+
+// streamVisibleHeight is the number of rows scrolled per page
+func (m *Model) streamVisibleHeight() int {
+	return max(m.height-4-m.previewFooterLines(), 1)
+}
+
+// loadStreamWindow reads the visible rows into previewContent, so the regular
+// rendering, match highlighting and horizontal scroll apply to streamed files
+func (m *Model) loadStreamWindow() {
+	s := m.previewStream
+	m.previewContent, m.previewBytes = s.window(max(m.height-2-m.previewFooterLines(), 1))
+	m.previewScroll = 0
+	s.updateTopLine()
+
+	// Highlight matches in the visible rows only
+	m.previewMatches = nil
+	m.previewMatchIndex = -1
+	if !m.hasPreviewSearch() || m.previewSearchErr != "" {
+		return
+	}
+	if s.hex {
+		m.previewMatches, _ = findHexMatches(m.previewBytes, s.rowBytes, m.previewSearchQuery, m.previewSearchCase, m.previewSearchRegex)
+	} else {
+		m.previewMatches, _ = findTextMatches(m.previewContent, m.previewSearchQuery, m.previewSearchCase, m.previewSearchRegex)
+	}
+	for i, match := range m.previewMatches {
+		if m.streamMatchOffset(match) == s.matchStart {
+			m.previewMatchIndex = i
+		}
+	}
+}
+
+// streamMatchOffset returns the file offset of a match in the visible rows
+func (m *Model) streamMatchOffset(match previewMatch) int64 {
+	s := m.previewStream
+	if s.hex {
+		return s.top + int64(match.Start)
+	}
+	if match.Line < len(s.rows) {
+		return s.rows[match.Line] + int64(match.Start)
+	}
+	return -1
+}
+
+// updateStreamKey handles scrolling and follow mode for streamed files.
+// Returns false for keys the regular preview handling should process.
+func (m *Model) updateStreamKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	s := m.previewStream
+	visible := m.streamVisibleHeight()
+
+	switch msg.String() {
+	case "up", "k":
+		s.follow = false
+		s.scroll(-1, visible)
+	case "down", "j":
+		s.scroll(1, visible)
+	case "pgup", "b":
+		s.follow = false
+		s.scroll(-visible, visible)
+	case "pgdown", "f", "space", " ":
+		s.scroll(visible, visible)
+	case "g":
+		s.follow = false
+		s.top = 0
+	case "G":
+		s.top = s.lastTop(visible)
+	default:
+		return false, nil
+	}
+	s.pending = -1
+	m.loadStreamWindow()
+	return true, nil
+}
+
+// streamIndexCmd schedules the next background indexing step
+func (m *Model) streamIndexCmd() tea.Cmd {
+	s := m.previewStream
+	if s == nil || s.hex || s.index.eof || s.indexing {
+		return nil
+	}
+	s.indexing = true
+	return func() tea.Msg {
+		return streamIndexMsg{stream: s}
+	}
+}
+
+// stepStreamIndex indexes the next StreamIndexStepBytes of the streamed file
+func (m *Model) stepStreamIndex(msg streamIndexMsg) tea.Cmd {
+	s := m.previewStream
+	if s != msg.stream {
+		return nil
+	}
+	s.indexing = false
+
+	before := s.index.next
+	s.index.extend(s.file, StreamIndexStepBytes, func() bool { return false })
+	if s.index.next == before {
+		s.pending = -1
+		s.updateTopLine()
+		return nil // No progress (read error)
+	}
+
+	// A line jump waiting for the index lands once it is reached
+	if s.pending >= 0 && (s.index.lines > s.pending || s.index.eof) {
+		s.jumpLine(s.pending)
+		s.center(m.streamVisibleHeight())
+		m.loadStreamWindow()
+	} else {
+		s.updateTopLine()
+	}
+	return m.streamIndexCmd()
+}
+
+// toggleFollow turns follow mode on or off. Regular previews switch to
+// streaming first so that data appended to the file can be shown.
+func (m *Model) toggleFollow() tea.Cmd {
+	var cmds []tea.Cmd
+	if m.previewStream == nil {
+		if m.previewIsImage || m.previewIsArchive || m.previewRendered {
+			return nil
+		}
+		m.previewDiffLines = nil
+		m.previewDiffMap = nil
+		m.previewDiffIndex = -1
+		cmds = append(cmds, m.openStreamPreview(m.previewPath, m.previewIsBinary))
+		if m.previewStream == nil {
+			return nil
+		}
+	}
+
+	s := m.previewStream
+	s.follow = !s.follow
+	if !s.follow {
+		return tea.Batch(cmds...)
+	}
+	s.followID++
+	m.followStreamEnd()
+	cmds = append(cmds, streamFollowCmd(s, s.followID))
+	return tea.Batch(cmds...)
+}
+
+func streamFollowCmd(s *streamView, id int) tea.Cmd {
+	return tea.Tick(StreamFollowMs*time.Millisecond, func(time.Time) tea.Msg {
+		return streamFollowMsg{stream: s, id: id}
+	})
+}
+
+// stepStreamFollow picks up data appended to (or a truncation of) a followed file
+func (m *Model) stepStreamFollow(msg streamFollowMsg) tea.Cmd {
+	s := m.previewStream
+	if s != msg.stream || !s.follow || msg.id != s.followID {
+		return nil
+	}
+
+	old, err := s.file.remap()
+	if err == nil && old != s.size() {
+		s.index.resize(old, s.size())
+		if s.matchEnd > s.size() {
+			s.matchStart, s.matchEnd = -1, -1
+		}
+		m.followStreamEnd()
+	}
+	return tea.Batch(m.streamIndexCmd(), streamFollowCmd(s, s.followID))
+}
+
+// followStreamEnd scrolls so the last row is at the bottom
+func (m *Model) followStreamEnd() {
+	s := m.previewStream
+	s.pending = -1
+	s.top = s.lastTop(m.streamVisibleHeight())
+	if s.hex {
+		s.cursor = max(s.size()-1, 0)
+	}
+	m.loadStreamWindow()
+}
+
+// Search in streamed files
+
+// runStreamSearch finds the first match at or after the top row (or hex cursor). While typing,
+// only StreamIncrementalSearchBytes are searched; full searches the whole file.
+func (m *Model) runStreamSearch(full bool) {
+	s := m.previewStream
+	s.matchStart, s.matchEnd = -1, -1
+	s.searchedAll = false
+	if m.previewSearchQuery == "" {
+		m.loadStreamWindow()
+		return
+	}
+
+	match, err := streamMatcher(m.previewSearchQuery, s.hex, m.previewSearchCase, m.previewSearchRegex)
+	if err != nil {
+		m.previewSearchErr = "Invalid regex"
+		m.loadStreamWindow()
+		return
+	}
+
+	limit := int64(StreamIncrementalSearchBytes)
+	if full {
+		limit = -1
+	}
+	// The hex viewer searches from the cursor
+	from := s.top
+	if s.hex {
+		from = s.cursor
+	}
+	start, end, ok := s.find(match, from, true, limit)
+	if !ok && full && from > 0 {
+		start, end, ok = s.find(match, 0, true, -1) // Wrap around
+	}
+	s.searchedAll = full || (from == 0 && limit >= s.size())
+
+	if !ok {
+		m.loadStreamWindow()
+		return
+	}
+	m.showStreamMatch(start, end)
+}
+
+// findStreamMatch jumps to the next or previous match, wrapping around
+func (m *Model) findStreamMatch(forward bool) {
+	s := m.previewStream
+	match, err := streamMatcher(m.previewSearchQuery, s.hex, m.previewSearchCase, m.previewSearchRegex)
+	if err != nil {
+		return
+	}
+
+	from := s.top
+	if s.matchStart >= 0 {
+		from = s.matchStart
+		if forward {
+			from++
+		}
+	}
+	start, end, ok := s.find(match, from, forward, -1)
+	if !ok {
+		if forward {
+			start, end, ok = s.find(match, 0, true, -1)
+		} else {
+			start, end, ok = s.find(match, s.size(), false, -1)
+		}
+	}
+	s.searchedAll = true
+	if !ok {
+		m.message = "No match found"
+		return
+	}
+	s.follow = false
+	m.showStreamMatch(start, end)
+}
+
+// showStreamMatch makes a match current, scrolling only if it is off screen
+func (m *Model) showStreamMatch(start, end int64) {
+	s := m.previewStream
+	s.matchStart, s.matchEnd = start, end
+	if s.hex {
+		s.cursor = start
+	}
+
+	if start < s.top || start >= s.windowEnd {
+		s.pending = -1
+		s.jumpOffset(start)
+		s.center(m.streamVisibleHeight())
+	}
+	m.loadStreamWindow()
+	if m.previewMatchIndex >= 0 {
+		m.revealPreviewMatchColumn(m.previewMatches[m.previewMatchIndex])
+	}
+}
+
+// streamSearchStatus returns the search state for the status line
+func (m Model) streamSearchStatus() string {
+	s := m.previewStream
+	switch {
+	case s.matchStart >= 0:
+		return fmt.Sprintf(" [match at 0x%x]", s.matchStart)
+	case s.searchedAll:
+		return " [no matches]"
+	default:
+		return " [Enter: search whole file]"
+	}
+}
+
+// Jump to line or offset
+
+func (m *Model) startPreviewJump() {
+	if m.previewIsImage || m.previewIsArchive {
+		return
+	}
+	m.previewJumpInput = ""
+	m.previewJumpErr = ""
+	m.inputMode = ModePreviewJump
+}
+
+func (m Model) updatePreviewJumpMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.previewJumpInput == "" {
+			m.inputMode = ModePreview
+			return m, nil
+		}
+		kind, v, err := parseJumpTarget(m.previewJumpInput)
+		if err != nil {
+			m.previewJumpErr = err.Error()
+			return m, nil
+		}
+		m.inputMode = ModePreview
+		return m, m.jumpPreview(kind, v)
+	case "esc":
+		m.inputMode = ModePreview
+	case "backspace":
+		if len(m.previewJumpInput) > 0 {
+			runes := []rune(m.previewJumpInput)
+			m.previewJumpInput = string(runes[:len(runes)-1])
+		}
+		m.previewJumpErr = ""
+	default:
+		key := msg.Key()
+		if key.Text == "" {
+			return m, nil
+		}
+		m.previewJumpInput += key.Text
+		m.previewJumpErr = ""
+	}
+	return m, nil
+}
+
+// jumpPreview centers the given line, byte offset or percentage of the file.
+// Streamed lines past the indexed range are jumped to by the background index.
+func (m *Model) jumpPreview(kind jumpKind, v int64) tea.Cmd {
+	if s := m.previewStream; s != nil {
+		s.follow = false
+		s.pending = -1
+		if kind == jumpPercent {
+			kind, v = jumpOffset, s.size()*v/100
+		}
+		if kind == jumpLine && s.hex {
+			kind, v = jumpOffset, (v-1)*int64(s.rowBytes)
+		}
+		if kind == jumpLine {
+			if !s.jumpLine(v - 1) {
+				return m.streamIndexCmd()
+			}
+		} else {
+			s.jumpOffset(v)
+		}
+		// The hex cursor lands on the target byte
+		if s.hex {
+			s.cursor = min(v, max(s.size()-1, 0))
+		}
+		s.center(m.streamVisibleHeight())
+		m.loadStreamWindow()
+		return nil
+	}
+
+	line := v
+	switch kind {
+	case jumpOffset:
+		if m.previewIsBinary {
+			line = v/hexBytesPerLine + 1
+		} else {
+			line = int64(previewLineAtOffset(m.previewContent, v)) + 1
+		}
+	case jumpPercent:
+		line = int64(len(m.previewContent))*v/100 + 1
+	}
+	m.scrollToPreviewLine(int(min(line, int64(len(m.previewContent)))))
+	return nil
+}
+
+// previewLineAtOffset returns the index of the line containing a byte offset
+func previewLineAtOffset(lines []string, offset int64) int {
+	var pos int64
+	for i, line := range lines {
+		pos += int64(len(line)) + 1 // Newline
+		if offset < pos {
+			return i
+		}
+	}
+	return max(len(lines)-1, 0)
+}
+
+// renderPreviewJumpInput renders the jump prompt in place of the preview status bar
+func (m Model) renderPreviewJumpInput() string {
+	errText := ""
+	if m.previewJumpErr != "" {
+		errText = fmt.Sprintf(" [%s]", m.previewJumpErr)
+	}
+	content := fmt.Sprintf(" :%s█%s | 120:line 0x1f00 or @8192:offset 50%%:percent Enter:jump Esc:cancel ",
+		m.previewJumpInput, errText)
+	if lipgloss.Width(content) > m.width && m.width > 1 {
+		content = ansi.Truncate(content, m.width-1, "") + "…"
+	}
+	return previewStatusStyle.Width(m.width).Render(content)
+}
+
+// streamTitle describes a streamed file for the preview title
+func (m Model) streamTitle() string {
+	s := m.previewStream
+	kind := "streaming"
+	if s.follow {
+		kind = "following"
+	}
+	parts := []string{formatFileSize(s.size()), kind}
+	if s.hex {
+		parts = append([]string{"binary"}, parts...)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Structured preview operations (JSON/YAML/TOML tree)
+
+// renderDataTreePreview lays out the tree, keeping the cursor on the same node
+func (m *Model) renderDataTreePreview() {
+	current := m.previewDataNode()
+	m.previewContent, m.previewSyntax, m.previewDataRows = renderDataTree(m.previewDataRoots, m.previewQuery != "")
+
+	m.previewCursor = min(m.previewCursor, max(len(m.previewDataRows)-1, 0))
+	for i, n := range m.previewDataRows {
+		if n == current {
+			m.previewCursor = i
+			break
+		}
+	}
+}
+
+// previewDataNode returns the node under the tree cursor
+func (m *Model) previewDataNode() *dataNode {
+	if m.previewCursor < 0 || m.previewCursor >= len(m.previewDataRows) {
+		return nil
+	}
+	return m.previewDataRows[m.previewCursor]
+}
+
+// isDataTreePreview returns true when the structured tree view is shown
+func (m *Model) isDataTreePreview() bool {
+	return m.previewRendered && m.previewFormat == formatData
+}
+
+// updateDataTreeKey handles keys specific to the tree view.
+// Returns false for keys the regular preview handling should process.
+func (m *Model) updateDataTreeKey(msg tea.KeyMsg) bool {
+	if m.updatePreviewCursorKey(msg) {
+		return true
+	}
+
+	switch msg.String() {
+	case "enter", "tab", "space", " ":
+		if n := m.previewDataNode(); n != nil && n.isContainer() {
+			n.Collapsed = !n.Collapsed
+			m.renderDataTreePreview()
+		}
+	case "left", "h":
+		m.collapseDataNode()
+	case "right", "l":
+		m.expandDataNode()
+	case "E":
+		m.setAllDataCollapsed(false)
+	case "C":
+		m.setAllDataCollapsed(true)
+	case ":":
+		m.startPreviewQuery()
+	default:
+		return false
+	}
+	return true
+}
+
+// collapseDataNode collapses the node under the cursor, or moves to its parent
+func (m *Model) collapseDataNode() {
+	n := m.previewDataNode()
+	if n == nil {
+		return
+	}
+	if n.isContainer() && len(n.Children) > 0 && !n.Collapsed {
+		n.Collapsed = true
+		m.renderDataTreePreview()
+		return
+	}
+	for i, row := range m.previewDataRows {
+		if row == n.Parent {
+			m.movePreviewCursor(i - m.previewCursor)
+			return
+		}
+	}
+}
+
+// expandDataNode expands the node under the cursor, or moves to its first child
+func (m *Model) expandDataNode() {
+	n := m.previewDataNode()
+	if n == nil || !n.isContainer() || len(n.Children) == 0 {
+		return
+	}
+	if n.Collapsed {
+		n.Collapsed = false
+		m.renderDataTreePreview()
+		return
+	}
+	m.movePreviewCursor(1)
+}
+
+// setAllDataCollapsed expands or collapses everything below the displayed roots
+func (m *Model) setAllDataCollapsed(collapsed bool) {
+	for _, root := range m.previewDataRoots {
+		setDataCollapsed(root, collapsed)
+		root.Collapsed = false
+	}
+	m.renderDataTreePreview()
+	m.movePreviewCursor(0)
+}
+
+// Path query
+
+func (m *Model) startPreviewQuery() {
+	m.previewQueryErr = ""
+	m.inputMode = ModePreviewQuery
+}
+
+func (m Model) updatePreviewQueryMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.inputMode = ModePreview
+		return m, nil
+	case "esc":
+		m.clearPreviewQuery()
+		m.inputMode = ModePreview
+		return m, nil
+	case "backspace":
+		if len(m.previewQuery) > 0 {
+			runes := []rune(m.previewQuery)
+			m.previewQuery = string(runes[:len(runes)-1])
+		}
+	default:
+		key := msg.Key()
+		if key.Text == "" {
+			return m, nil
+		}
+		m.previewQuery += key.Text
+	}
+
+	// Evaluate on every change
+	m.runPreviewQuery()
+	return m, nil
+}
+
+// runPreviewQuery shows the nodes matched by the current query.
+// An invalid or empty query keeps the previous results and reports an error.
+func (m *Model) runPreviewQuery() {
+	m.previewQueryErr = ""
+	if m.previewDataRoot == nil {
+		return
+	}
+
+	steps, err := parseDataQuery(m.previewQuery)
+	if err != nil {
+		m.previewQueryErr = err.Error()
+		return
+	}
+	results := evalDataQuery(m.previewDataRoot, steps)
+	if len(results) == 0 {
+		m.previewQueryErr = "no results"
+		return
+	}
+
+	m.previewDataRoots = results
+	m.previewCursor = 0
+	m.previewScroll = 0
+	m.renderDataTreePreview()
+	if m.hasPreviewSearch() {
+		m.runPreviewSearch()
+	}
+}
+
+// hasPreviewQuery returns true if the tree shows query results
+func (m *Model) hasPreviewQuery() bool {
+	return m.previewQuery != ""
+}
+
+// clearPreviewQuery shows the whole document again
+func (m *Model) clearPreviewQuery() {
+	m.previewQuery = ""
+	m.previewQueryErr = ""
+	if m.previewDataRoot == nil {
+		return
+	}
+	m.previewDataRoots = []*dataNode{m.previewDataRoot}
+	m.renderDataTreePreview()
+	m.movePreviewCursor(0)
+	if m.hasPreviewSearch() {
+		m.runPreviewSearch()
+	}
+}
+
+// previewQueryStatus returns the query result summary for the status bar
+func (m Model) previewQueryStatus() string {
+	if m.previewQueryErr != "" {
+		return fmt.Sprintf(" [%s]", m.previewQueryErr)
+	}
+	if m.previewQuery == "" {
+		return ""
+	}
+	if len(m.previewDataRoots) == 1 {
+		return " [1 result]"
+	}
+	return fmt.Sprintf(" [%d results]", len(m.previewDataRoots))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -86,7 +87,7 @@ func TestIsBinaryContent_LargeContent(t *testing.T) {
 	}
 }
 
-func TestFormatHexPreview(t *testing.T) {
+func TestFormatHexDump(t *testing.T) {
 	tests := []struct {
 		name           string
 		content        []byte
@@ -121,10 +122,10 @@ func TestFormatHexPreview(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatHexPreview(tt.content, len(tt.content))
+			result := formatHexDump(tt.content, 0, 16)
 
 			if len(result) != tt.expectedLines {
-				t.Errorf("formatHexPreview() returned %d lines, expected %d", len(result), tt.expectedLines)
+				t.Errorf("formatHexDump() returned %d lines, expected %d", len(result), tt.expectedLines)
 			}
 
 			if tt.expectedLines > 0 && !strings.HasPrefix(result[0], tt.checkFirstLine) {
@@ -134,10 +135,10 @@ func TestFormatHexPreview(t *testing.T) {
 	}
 }
 
-func TestFormatHexPreview_ASCIIPart(t *testing.T) {
+func TestFormatHexDump_ASCIIPart(t *testing.T) {
 	// Test ASCII representation
 	content := []byte("Hello\x00World!")
-	result := formatHexPreview(content, len(content))
+	result := formatHexDump(content, 0, 16)
 
 	if len(result) != 1 {
 		t.Fatalf("Expected 1 line, got %d", len(result))
@@ -149,24 +150,22 @@ func TestFormatHexPreview_ASCIIPart(t *testing.T) {
 	}
 }
 
-func TestFormatHexPreview_Truncation(t *testing.T) {
-	// Create content larger than 1600 bytes
-	content := make([]byte, 2000)
+func TestFormatHexDump_RowWidth(t *testing.T) {
+	content := make([]byte, 40)
 	for i := range content {
 		content[i] = byte(i % 256)
 	}
 
-	result := formatHexPreview(content, len(content))
-
-	// Should be truncated to 100 lines (1600 bytes / 16) + 1 truncation message
-	expectedLines := 101
-	if len(result) != expectedLines {
-		t.Errorf("Expected %d lines (100 + truncation), got %d", expectedLines, len(result))
+	// Rows are numbered from the given offset and padded to the row width
+	result := formatHexDump(content, 0x100, 8)
+	if len(result) != 5 {
+		t.Fatalf("Expected 5 rows of 8 bytes, got %d", len(result))
 	}
-
-	// Last line should be truncation message
-	if result[len(result)-1] != "... (truncated)" {
-		t.Errorf("Last line should be truncation message, got %q", result[len(result)-1])
+	if !strings.HasPrefix(result[1], "00000108  08 09") {
+		t.Errorf("Second row = %q", result[1])
+	}
+	if got := strings.Index(result[0], "........"); got != hexASCIIStart(8) {
+		t.Errorf("ASCII column at %d, expected %d", got, hexASCIIStart(8))
 	}
 }
 
@@ -490,6 +489,109 @@ func TestFileChangeMsg_WatcherEnabled_SyncRefresh(t *testing.T) {
 	}
 }
 
+func TestFileChangeMsg_AppliesChangedPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "pkg"), 0755)
+	target := filepath.Join(tmpDir, "pkg", "main.go")
+	os.WriteFile(target, nil, 0644)
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if model.watcher != nil {
+		model.watcher.Close()
+		model.watcher = nil
+	}
+	model.watcherEnabled = true
+	model.height = 20
+	if _, err := model.revealPath(target); err != nil {
+		t.Fatal(err)
+	}
+
+	created := filepath.Join(tmpDir, "pkg", "a.go")
+	os.WriteFile(created, nil, 0644)
+	next, _ := model.Update(FileChangeMsg{Changes: []FileChange{{Path: created, Op: FileCreated}}})
+	m := next.(Model)
+
+	if m.tree.IndexOf(created) < 0 {
+		t.Error("Expected the created file inserted into the expanded directory")
+	}
+	if node := m.tree.GetNode(m.selected); node == nil || node.Path != target {
+		t.Errorf("Expected %s to stay selected, got %v", target, node)
+	}
+}
+
+func TestFileChangeMsg_CreateNextToGhost(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if err := cmd.Run(); err != nil {
+			t.Skip("git not available, skipping VCS test")
+		}
+	}
+	for _, name := range []string{"a.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(tmpDir, name), nil, 0644)
+	}
+	cmd := exec.Command("sh", "-c", "git add . && git commit -qm initial")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+	os.Remove(filepath.Join(tmpDir, "c.txt"))
+
+	model, err := NewModel(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	if model.watcher != nil {
+		model.watcher.Close()
+		model.watcher = nil
+	}
+	model.watcherEnabled = true
+
+	// A deleted tracked file (ghost) plus creates around it in the same directory
+	var changes []FileChange
+	for _, name := range []string{"b.txt", "d.txt"} {
+		path := filepath.Join(tmpDir, name)
+		os.WriteFile(path, nil, 0644)
+		changes = append(changes, FileChange{Path: path, Op: FileCreated})
+	}
+	next, _ := model.Update(FileChangeMsg{Changes: changes})
+	m := next.(Model)
+
+	var names []string
+	for _, child := range m.tree.Root.Children {
+		names = append(names, child.Name)
+	}
+	if got := strings.Join(names, " "); got != "a.txt b.txt c.txt d.txt" {
+		t.Errorf("Expected a b c(ghost) d in order, got %s", got)
+	}
+
+	// Recreating the deleted file replaces its ghost
+	recreated := filepath.Join(tmpDir, "c.txt")
+	os.WriteFile(recreated, nil, 0644)
+	next, _ = m.Update(FileChangeMsg{Changes: []FileChange{{Path: recreated, Op: FileCreated}}})
+	m = next.(Model)
+	count := 0
+	for _, child := range m.tree.Root.Children {
+		if child.Name == "c.txt" {
+			count++
+			if child.IsGhost {
+				t.Error("Expected c.txt no longer a ghost")
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected c.txt once, got %d", count)
+	}
+}
+
 func TestToggleWatcher_EnableFromDisabled(t *testing.T) {
 	tmpDir := t.TempDir()
 	model, err := NewModel(tmpDir)
@@ -1213,3 +1315,56 @@ func TestSearch_EmptyQuery_ClearsSearchState(t *testing.T) {
 	}
 }
 
+func TestJumpToLinkTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "root", "pkg"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "shared"), 0755)
+	target := filepath.Join(tmpDir, "root", "pkg", "config.toml")
+	os.WriteFile(target, nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, "shared", "x.txt"), nil, 0644)
+	os.Symlink(filepath.Join("pkg", "config.toml"), filepath.Join(tmpDir, "root", "config.toml"))
+	os.Symlink(filepath.Join("..", "shared"), filepath.Join(tmpDir, "root", "shared"))
+	os.Symlink("gone", filepath.Join(tmpDir, "root", "broken"))
+
+	model, err := NewModel(filepath.Join(tmpDir, "root"))
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer func() {
+		if model.watcher != nil {
+			model.watcher.Close()
+		}
+	}()
+
+	selectLink := func(m tea.Model, name string) tea.Model {
+		model := m.(Model)
+		model.selected = model.tree.IndexOf(filepath.Join(tmpDir, "root", name))
+		return model
+	}
+
+	var m tea.Model = selectLink(model, "broken")
+	if line := m.(Model).renderNode(m.(Model).tree.GetNode(m.(Model).selected), false); !strings.Contains(line, "broken → gone") {
+		t.Errorf("Expected the link target shown, got %q", line)
+	}
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("f"))
+	if msg := m.(Model).message; msg != "Error: broken link → gone" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	// Relative targets resolve from the link's directory
+	m = selectLink(m, "config.toml")
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("f"))
+	if node := m.(Model).tree.GetNode(m.(Model).selected); node == nil || node.Path != target {
+		t.Errorf("Expected %s selected, got %v", target, node)
+	}
+
+	// Targets outside the root move the root
+	m = selectLink(m, "shared")
+	m, _ = m.Update(keyMsg("g"))
+	m, _ = m.Update(keyMsg("f"))
+	if root := m.(Model).tree.Root.Path; root != filepath.Join(tmpDir, "shared") {
+		t.Errorf("Expected root moved to shared/, got %s", root)
+	}
+}
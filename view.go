@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
@@ -28,15 +29,25 @@ func (m Model) View() tea.View {
 	}
 
 	// Preview mode has its own view
-	if m.inputMode == ModePreview {
+	if m.inputMode == ModePreview || m.inputMode == ModePreviewSearch || m.inputMode == ModePreviewQuery || m.inputMode == ModePreviewJump {
 		return newView(m.renderPreview())
 	}
 
+	// Shell command output has its own view
+	if m.inputMode == ModeShellOutput {
+		return newView(m.renderShellOutput())
+	}
+
 	// Confirm delete mode - show popup with tree in background
 	if m.inputMode == ModeConfirmDelete {
 		return newView(m.renderConfirmView())
 	}
 
+	return newView(m.renderTreeView())
+}
+
+// renderTreeView renders the tree (with the split pane when shown), status bar and input popup
+func (m Model) renderTreeView() string {
 	var b strings.Builder
 
 	// Title
@@ -55,6 +66,11 @@ func (m Model) View() tea.View {
 		visibleHeight = 10
 	}
 
+	var pane []string
+	if m.splitPane {
+		pane = m.renderSplitPane(m.splitPaneWidth(), visibleHeight)
+	}
+	row := 0
 	for i := m.scrollOffset; i < m.tree.Len() && i < m.scrollOffset+visibleHeight; i++ {
 		node := m.tree.GetNode(i)
 		if node == nil {
@@ -62,8 +78,17 @@ func (m Model) View() tea.View {
 		}
 
 		line := m.renderNode(node, i == m.selected)
+		if pane != nil {
+			// Tree on the left, preview pane on the right
+			line = fitWidth(line, m.treeWidth()) + splitDividerStyle.Render("│") + pane[row]
+		}
 		b.WriteString(line)
 		b.WriteString("\n")
+		row++
+	}
+	for ; pane != nil && row < visibleHeight; row++ {
+		b.WriteString(strings.Repeat(" ", m.treeWidth()) + splitDividerStyle.Render("│") + pane[row])
+		b.WriteString("\n")
 	}
 
 	// Pad remaining lines
@@ -72,6 +97,13 @@ func (m Model) View() tea.View {
 		b.WriteString("\n")
 	}
 
+	if m.isHexViewer() {
+		for _, line := range m.renderHexInspector() {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
 	// Status bar
 	status := m.renderStatusBar()
 	b.WriteString(status)
@@ -82,7 +114,7 @@ func (m Model) View() tea.View {
 		b.WriteString("\n" + popup)
 	}
 
-	return newView(b.String())
+	return b.String()
 }
 
 func (m Model) renderPreview() string {
@@ -91,8 +123,14 @@ func (m Model) renderPreview() string {
 	// Title
 	filename := filepath.Base(m.previewPath)
 	var title string
-	if m.previewIsBinary {
+	if m.previewStream != nil {
+		title = fmt.Sprintf(" %s (%s) ", filename, m.streamTitle())
+	} else if m.previewIsBinary {
 		title = fmt.Sprintf(" %s (binary) ", filename)
+	} else if m.previewRendered {
+		title = fmt.Sprintf(" %s (rendered) ", filename)
+	} else if m.previewIsArchive {
+		title = fmt.Sprintf(" %s (archive) ", filename)
 	} else {
 		title = fmt.Sprintf(" %s ", filename)
 	}
@@ -100,8 +138,8 @@ func (m Model) renderPreview() string {
 	b.WriteString("\n")
 
 	// Content
-	// Reserve space: 1 for title, 1 for status bar
-	visibleHeight := m.height - 2
+	// Reserve space: 1 for title, 1 for status bar, and the hex inspector
+	visibleHeight := m.height - 2 - m.previewFooterLines()
 	if visibleHeight < 1 {
 		visibleHeight = 10
 	}
@@ -112,23 +150,64 @@ func (m Model) renderPreview() string {
 			b.WriteString(m.previewContent[i])
 			b.WriteString("\n")
 		}
+	} else if m.previewRendered || m.previewIsArchive {
+		// Rendered preview and archive listing - no gutter, optional table of contents on the left
+		tocWidth := m.previewTOCWidth()
+		tocStart := m.previewTOCStart()
+		currentHeading := m.currentHeadingIndex()
+
+		// Frozen header (table) stays above the scrolled rows
+		for i, line := range m.previewHeader {
+			b.WriteString(renderLine(line, m.previewHeaderSyntax[i], lipgloss.NewStyle(), m.previewHScroll, m.previewRenderWidth()))
+			b.WriteString("\n")
+		}
+
+		for row := 0; row < visibleHeight-len(m.previewHeader); row++ {
+			i := m.previewScroll + row
+			if i >= len(m.previewContent) && tocWidth == 0 {
+				break
+			}
+			if tocWidth > 0 {
+				b.WriteString(m.renderTOCEntry(tocStart+row, currentHeading, tocWidth))
+				b.WriteString(mdRuleStyle.Render("│"))
+				b.WriteString(" ")
+			}
+			if i < len(m.previewContent) {
+				var spans []textSpan
+				if i < len(m.previewSyntax) {
+					spans = append(spans, m.previewSyntax[i]...)
+				}
+				spans = append(spans, m.previewMatchSpans(i)...)
+				lineStyle := lipgloss.NewStyle()
+				if (m.isDataTreePreview() || m.previewIsArchive) && i == m.previewCursor {
+					lineStyle = selectedStyle
+				}
+				b.WriteString(renderLine(m.previewContent[i], spans, lineStyle, m.previewHScroll, m.previewRenderWidth()))
+			}
+			b.WriteString("\n")
+		}
 	} else {
 		// Text/binary preview - with line numbers and diff markers
+		// Streamed files number rows from the top of the window (blank until indexed)
+		firstLine := 1
+		if m.previewStream != nil {
+			firstLine = int(m.previewStream.topLine) + 1
+		}
+		numWidth := m.previewNumWidth()
+		maxWidth := m.previewContentWidth()
+
 		for i := m.previewScroll; i < len(m.previewContent) && i < m.previewScroll+visibleHeight; i++ {
-			lineNum := i + 1
+			lineNum := firstLine + i
 			line := m.previewContent[i]
 
-			// Truncate long lines (account for marker column)
-			maxWidth := m.width - 8 // 2 for marker, 5 for line number, 1 for space
-			if maxWidth < 1 {
-				maxWidth = 1
+			// Syntax spans first, search matches on top
+			var spans []textSpan
+			if i < len(m.previewSyntax) {
+				spans = append(spans, m.previewSyntax[i]...)
 			}
-			if len(line) > maxWidth {
-				if maxWidth == 1 {
-					line = "…"
-				} else {
-					line = line[:maxWidth-1] + "…"
-				}
+			spans = append(spans, m.previewMatchSpans(i)...)
+			if m.isHexViewer() {
+				spans = append(spans, m.hexCursorSpans(i)...)
 			}
 
 			// Diff marker
@@ -157,10 +236,13 @@ func (m Model) renderPreview() string {
 				}
 			}
 
-			lineNumStr := fmt.Sprintf("%4d ", lineNum)
+			lineNumStr := fmt.Sprintf("%*d ", numWidth, lineNum)
+			if firstLine <= 0 {
+				lineNumStr = strings.Repeat(" ", numWidth+1)
+			}
 			b.WriteString(markerStyle.Render(marker))
 			b.WriteString(lineNumStyle.Render(lineNumStr))
-			b.WriteString(lineStyle.Render(line))
+			b.WriteString(renderLine(line, spans, lineStyle, m.previewHScroll, maxWidth))
 			b.WriteString("\n")
 		}
 	}
@@ -171,7 +253,27 @@ func (m Model) renderPreview() string {
 		b.WriteString("\n")
 	}
 
-	// Status bar
+	if m.isHexViewer() {
+		for _, line := range m.renderHexInspector() {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	// Status bar (search or query input replaces it while typing)
+	if m.inputMode == ModePreviewSearch {
+		b.WriteString(m.renderPreviewSearchInput())
+		return b.String()
+	}
+	if m.inputMode == ModePreviewQuery {
+		b.WriteString(m.renderPreviewQueryInput())
+		return b.String()
+	}
+	if m.inputMode == ModePreviewJump {
+		b.WriteString(m.renderPreviewJumpInput())
+		return b.String()
+	}
+
 	var status string
 	if isImageFile(m.previewPath) {
 		// Image preview - show image info
@@ -205,19 +307,111 @@ func (m Model) renderPreview() string {
 		}
 
 		// Build help text
-		help := "j/k:scroll"
-		if len(m.previewDiffLines) > 0 {
+		help := "j/k:scroll h/l:pan /:search"
+		switch {
+		case m.previewFormat == formatMarkdown:
+			help = "j/k:scroll /:search m:raw t:toc [/]:sections"
+		case m.previewFormat == formatData:
+			help = "j/k:move h/l:fold E/C:all ::query /:search m:raw"
+		case m.previewFormat == formatTable:
+			help += " m:raw"
+		case m.previewIsArchive:
+			help = "j/k:move space:mark x:extract X:extract all /:search"
+		case m.isHexViewer():
+			help = "hjkl:move w:width E:endian ::offset /:bytes F:follow"
+		case m.previewStream != nil:
+			help += " ::jump F:follow"
+		case m.canRenderPreview():
+			help += " m:render"
+		}
+		if m.hasPreviewSearch() {
+			help += " n/N:matches"
+		} else if len(m.previewDiffLines) > 0 {
 			help += " n/N:changes"
 		}
 		help += " q:close"
 
-		status = fmt.Sprintf(" Line %d/%d (%d%%)%s | %s ", currentLine, totalLines, percent, diffIndicator, help)
+		// Horizontal scroll position
+		colIndicator := ""
+		if m.previewHScroll > 0 {
+			colIndicator = fmt.Sprintf(" Col %d", m.previewHScroll+1)
+		}
+
+		position := fmt.Sprintf("Line %d/%d (%d%%)", currentLine, totalLines, percent)
+		switch m.previewFormat {
+		case formatData:
+			// Path of the node under the cursor
+			if n := m.previewDataNode(); n != nil {
+				position = n.Path
+			}
+		case formatTable:
+			position = fmt.Sprintf("Row %d/%d, %d columns", currentLine, totalLines, tableColumnCount(m.previewTable))
+		}
+		if m.previewStream != nil {
+			position = m.previewStream.position()
+			if m.isHexViewer() {
+				position = m.hexPosition()
+			}
+			if m.previewStream.follow {
+				position += " [FOLLOW]"
+			}
+		}
+		if m.previewIsArchive {
+			position = fmt.Sprintf("Entry %d/%d", m.previewCursor+1, totalLines)
+			if len(m.previewArchiveMarked) > 0 {
+				position += fmt.Sprintf(", %d marked", len(m.previewArchiveMarked))
+			}
+		}
+
+		status = fmt.Sprintf(" %s%s%s%s%s%s | %s ", position, colIndicator, diffIndicator, m.previewQueryStatus(), m.previewSearchStatus(), m.previewTextStatus(), help)
+		if lipgloss.Width(status) > m.width && m.width > 1 {
+			status = ansi.Truncate(status, m.width-1, "") + "…"
+		}
 	}
 	b.WriteString(previewStatusStyle.Width(m.width).Render(status))
 
 	return b.String()
 }
 
+// renderPreviewQueryInput renders the path query prompt in place of the preview status bar
+func (m Model) renderPreviewQueryInput() string {
+	content := fmt.Sprintf(" :%s█%s | e.g. .items[0].name .list[].id | Enter:confirm Esc:clear ",
+		m.previewQuery, m.previewQueryStatus())
+	if lipgloss.Width(content) > m.width && m.width > 1 {
+		content = ansi.Truncate(content, m.width-1, "") + "…"
+	}
+	return previewStatusStyle.Width(m.width).Render(content)
+}
+
+// renderTOCEntry renders one row of the table of contents sidebar, padded to width
+func (m Model) renderTOCEntry(index, current, width int) string {
+	if index < 0 || index >= len(m.previewHeadings) {
+		return strings.Repeat(" ", width)
+	}
+	h := m.previewHeadings[index]
+	text := strings.Repeat(" ", h.Level-1) + h.Text
+	if ansi.StringWidth(text) > width {
+		text = ansi.Truncate(text, width-1, "") + "…"
+	}
+	text += strings.Repeat(" ", width-ansi.StringWidth(text))
+
+	style := tocStyle
+	if index == current {
+		style = tocCurrentStyle
+	}
+	return style.Render(text)
+}
+
+// renderPreviewSearchInput renders the search prompt in place of the preview status bar
+func (m Model) renderPreviewSearchInput() string {
+	content := fmt.Sprintf(" /%s█%s %s | C-t:case C-r:regex Enter:confirm Esc:cancel ",
+		m.previewSearchQuery, m.previewSearchStatus(), m.previewSearchFlags())
+	if lipgloss.Width(content) > m.width && m.width > 1 {
+		content = ansi.Truncate(content, m.width-1, "") + "…"
+	}
+	return previewStatusStyle.Width(m.width).Render(content)
+}
+
 func (m Model) renderNode(node *FileNode, isSelected bool) string {
 	indent := strings.Repeat("  ", node.Depth)
 
@@ -231,12 +425,16 @@ func (m Model) renderNode(node *FileNode, isSelected bool) string {
 	var icon string
 	if node.IsGhost {
 		icon = icons.Ghost
+	} else if node.IsBroken {
+		icon = icons.BrokenLink
 	} else if node.IsDir {
 		if node.Expanded {
 			icon = icons.FolderOpen
 		} else {
 			icon = icons.FolderClosed
 		}
+	} else if node.IsSymlink {
+		icon = icons.Symlink
 	} else {
 		icon = getFileIconByExt(node.Name)
 	}
@@ -254,8 +452,8 @@ func (m Model) renderNode(node *FileNode, isSelected bool) string {
 	var style lipgloss.Style
 	isCut := m.clipboard.Type == ClipboardCut && m.clipboard.Contains(node.Path)
 
-	if node.IsGhost {
-		// Ghost files always use deleted style (red)
+	if node.IsGhost || node.IsBroken {
+		// Ghost files and broken links always use deleted style (red)
 		if isSelected {
 			style = selectedStyle.Foreground(lipgloss.Color("196"))
 		} else {
@@ -265,35 +463,49 @@ func (m Model) renderNode(node *FileNode, isSelected bool) string {
 		style = selectedStyle
 	} else if isCut {
 		style = cutStyle
-	} else {
+	} else if vcsStyle, ok := vcsStatusStyle(m.vcsRepo.GetStatus(node.Path)); ok {
 		// Apply VCS status color
-		vcsStatus := m.vcsRepo.GetStatus(node.Path)
-		switch vcsStatus {
-		case VCSStatusModified:
-			style = gitModifiedStyle
-		case VCSStatusAdded:
-			style = gitAddedStyle
-		case VCSStatusDeleted:
-			style = gitDeletedStyle
-		case VCSStatusRenamed:
-			style = gitRenamedStyle
-		case VCSStatusUntracked:
-			style = gitUntrackedStyle
-		case VCSStatusIgnored:
-			style = gitIgnoredStyle
-		case VCSStatusConflict:
-			style = gitConflictStyle
-		default:
-			if node.IsDir {
-				style = dirStyle
-			} else {
-				style = fileStyle
-			}
+		style = vcsStyle
+	} else if node.IsDir {
+		style = dirStyle
+	} else {
+		style = fileStyle
+	}
+
+	// Link target, dimmed
+	var target string
+	if node.IsSymlink {
+		targetStyle := symlinkTargetStyle
+		if isSelected {
+			targetStyle = selectedStyle.Foreground(symlinkTargetStyle.GetForeground())
 		}
+		target = targetStyle.Render(" → " + node.LinkTarget)
 	}
 
 	markStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("226"))
-	return markStyle.Render(markIndicator) + style.Render(line)
+	return markStyle.Render(markIndicator) + style.Render(line) + target + m.recentBadge(node, time.Now())
+}
+
+// vcsStatusStyle returns the color for a VCS status (false = no status)
+func vcsStatusStyle(status VCSStatus) (lipgloss.Style, bool) {
+	switch status {
+	case VCSStatusModified:
+		return gitModifiedStyle, true
+	case VCSStatusAdded:
+		return gitAddedStyle, true
+	case VCSStatusDeleted:
+		return gitDeletedStyle, true
+	case VCSStatusRenamed:
+		return gitRenamedStyle, true
+	case VCSStatusUntracked:
+		return gitUntrackedStyle, true
+	case VCSStatusIgnored:
+		return gitIgnoredStyle, true
+	case VCSStatusConflict:
+		return gitConflictStyle, true
+	default:
+		return lipgloss.Style{}, false
+	}
 }
 
 func (m Model) renderStatusBar() string {
@@ -313,6 +525,14 @@ func (m Model) renderStatusBar() string {
 		leftParts = append(leftParts, m.message)
 	}
 
+	// Chooser mode hint
+	switch m.chooseMode {
+	case chooseAny:
+		leftParts = append(leftParts, "[choose] Enter:pick C-s:pick dir q:cancel")
+	case chooseDir:
+		leftParts = append(leftParts, "[choose dir] Enter:pick q:cancel")
+	}
+
 	// Marked count
 	if len(m.marked) > 0 {
 		leftParts = append(leftParts, fmt.Sprintf("Marked:%d", len(m.marked)))
@@ -344,8 +564,11 @@ func (m Model) renderStatusBar() string {
 
 	leftStatus := strings.Join(leftParts, " | ")
 
-	// Right side: position (like "8/12")
+	// Right side: watcher backend and position (like "fsnotify 8/12")
 	rightStatus := fmt.Sprintf("%d/%d", m.selected+1, m.tree.Len())
+	if m.watcherEnabled && m.watcher != nil {
+		rightStatus = m.watcher.Backend() + " " + rightStatus
+	}
 
 	// Calculate padding between left and right
 	leftWidth := lipgloss.Width(leftStatus)
@@ -374,6 +597,10 @@ func (m Model) renderInputPopup() string {
 		title = "New Directory"
 	case ModeGoTo:
 		title = "Go to"
+	case ModeNewArchive:
+		title = "New Archive (.zip .tar .tar.gz .tar.xz)"
+	case ModeShell:
+		title = "Shell"
 	}
 
 	// Full terminal width minus border (2 chars for left + right border)
@@ -382,6 +609,14 @@ func (m Model) renderInputPopup() string {
 		maxContentWidth = 20
 	}
 
+	// Agent target picker has no input line
+	if m.inputMode == ModeAgentPick {
+		return inputStyle.Width(maxContentWidth).Render(m.renderAgentPicker(maxContentWidth))
+	}
+	if m.inputMode == ModeRecent {
+		return inputStyle.Width(maxContentWidth).Render(m.renderRecentList(maxContentWidth))
+	}
+
 	// Display input with cursor
 	displayBuffer := collapseHomePath(m.inputBuffer)
 	content := fmt.Sprintf(" %s: %s█", title, displayBuffer)
@@ -406,6 +641,11 @@ func (m Model) renderInputPopup() string {
 		content += m.renderSearchHint(maxContentWidth)
 	}
 
+	// Add hint for ModeShell
+	if m.inputMode == ModeShell {
+		content += m.renderShellHint(maxContentWidth)
+	}
+
 	// Apply width constraint to the popup
 	popupStyle := inputStyle.Width(maxContentWidth)
 	return popupStyle.Render(content)
@@ -771,3 +1011,119 @@ func wrapText(text string, maxWidth int) []string {
 	return lines
 }
 
+// renderLine renders the visible window [startCol, startCol+width) of a line.
+// Styled spans (byte ranges) are applied over the base style, with later spans
+// taking precedence where they overlap. Tabs are expanded to PreviewTabWidth and
+// an ellipsis marks content cut off on the right.
+func renderLine(line string, spans []textSpan, base lipgloss.Style, startCol, width int) string {
+	if width < 1 {
+		return ""
+	}
+
+	// Resolve the owning span for each byte
+	var owner []int
+	if len(spans) > 0 {
+		owner = make([]int, len(line))
+		for i := range owner {
+			owner[i] = -1
+		}
+		for si, span := range spans {
+			start := max(span.Start, 0)
+			end := min(span.End, len(line))
+			for i := start; i < end; i++ {
+				owner[i] = si
+			}
+		}
+	}
+
+	type cell struct {
+		text  string
+		width int
+		owner int
+	}
+
+	var cells []cell
+	outWidth := 0
+	overflow := false
+	col := 0
+	for i, r := range line {
+		if r == '\r' {
+			continue
+		}
+
+		var text string
+		var w int
+		if r == '\t' {
+			w = PreviewTabWidth - col%PreviewTabWidth
+			text = strings.Repeat(" ", w)
+		} else {
+			text = string(r)
+			w = ansi.StringWidth(text)
+		}
+
+		o := -1
+		if owner != nil {
+			o = owner[i]
+		}
+
+		// Skip content left of the window (pad partially visible cells)
+		if col+w <= startCol {
+			col += w
+			continue
+		}
+		if col < startCol {
+			text = strings.Repeat(" ", col+w-startCol)
+			w = col + w - startCol
+		}
+		col += w
+
+		if outWidth+w > width {
+			overflow = true
+			break
+		}
+		cells = append(cells, cell{text: text, width: w, owner: o})
+		outWidth += w
+	}
+
+	// Make room for the ellipsis
+	if overflow {
+		for len(cells) > 0 && outWidth > width-1 {
+			outWidth -= cells[len(cells)-1].width
+			cells = cells[:len(cells)-1]
+		}
+		cells = append(cells, cell{text: "…", width: 1, owner: -1})
+	}
+
+	// Render runs of cells sharing the same style
+	var b strings.Builder
+	for start := 0; start < len(cells); {
+		end := start + 1
+		for end < len(cells) && cells[end].owner == cells[start].owner {
+			end++
+		}
+		var seg strings.Builder
+		for _, c := range cells[start:end] {
+			seg.WriteString(c.text)
+		}
+		style := base
+		if o := cells[start].owner; o >= 0 {
+			style = spans[o].Style.Inherit(base)
+		}
+		b.WriteString(style.Render(seg.String()))
+		start = end
+	}
+	return b.String()
+}
+
+// renderShellHint renders the placeholder and keyboard hint for ModeShell
+func (m Model) renderShellHint(maxContentWidth int) string {
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	hint := " %f:file %F:marked %d:dir %r:root !cmd:interactive ↑↓:history Enter:run Esc:cancel"
+
+	// Truncate hint if too long
+	if lipgloss.Width(hint) > maxContentWidth {
+		hint = ansi.Truncate(hint, maxContentWidth-1, "") + "…"
+	}
+
+	return "\n" + hintStyle.Render(hint)
+}
@@ -1,6 +1,12 @@
 package main
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
 
 func TestGetFileIconByExt(t *testing.T) {
 	tests := []struct {
@@ -270,3 +276,57 @@ func TestWrapText(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderLine(t *testing.T) {
+	base := lipgloss.NewStyle()
+
+	tests := []struct {
+		name     string
+		line     string
+		startCol int
+		width    int
+		expected string
+	}{
+		{"fits", "hello", 0, 10, "hello"},
+		{"truncated with ellipsis", "hello world", 0, 6, "hello…"},
+		{"exact width", "hello", 0, 5, "hello"},
+		{"horizontal scroll", "hello world", 6, 10, "world"},
+		{"scroll past end", "hello", 10, 10, ""},
+		{"tab expansion", "\tx", 0, 10, "    x"},
+		{"carriage return dropped", "line\r", 0, 10, "line"},
+		{"wide characters", "日本語テキスト", 0, 7, "日本語…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ansi.Strip(renderLine(tt.line, nil, base, tt.startCol, tt.width))
+			if result != tt.expected {
+				t.Errorf("renderLine(%q) = %q, expected %q", tt.line, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderLine_SpansPreserveText(t *testing.T) {
+	base := lipgloss.NewStyle()
+	line := "abc foo def"
+	spans := []textSpan{
+		{Start: 0, End: 3, Style: syntaxKeywordStyle},
+		{Start: 4, End: 7, Style: previewMatchStyle},
+		{Start: 5, End: 6, Style: previewCurrentMatchStyle}, // Overlap: later wins
+	}
+
+	result := renderLine(line, spans, base, 0, 40)
+	if ansi.Strip(result) != line {
+		t.Errorf("renderLine changed visible text: %q", ansi.Strip(result))
+	}
+
+	// Scrolled window keeps span alignment
+	result = ansi.Strip(renderLine(line, spans, base, 4, 4))
+	if result != "foo…" {
+		t.Errorf("Expected scrolled window %q, got %q", "foo…", result)
+	}
+	if !strings.Contains(ansi.Strip(renderLine(line, spans, base, 0, 5)), "…") {
+		t.Error("Expected ellipsis when spans are cut off")
+	}
+}
@@ -1,44 +1,71 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/fsnotify/fsnotify"
 )
 
-// FileChangeMsg is sent when a file system change is detected
-type FileChangeMsg struct{}
+// FileChangeOp is the kind of change seen for a path
+type FileChangeOp int
 
-// Watcher wraps fsnotify.Watcher with debouncing and performance optimizations
+const (
+	FileCreated  FileChangeOp = iota // Created, or renamed into place
+	FileRemoved                      // Removed, or renamed away
+	FileModified                     // Contents written
+)
+
+// FileChange is one path reported by the watcher
+type FileChange struct {
+	Path string
+	Op   FileChangeOp
+}
+
+// FileChangeMsg is sent when a file system change is detected.
+// Changes lists the paths seen in the debounce window; it is empty when the
+// batch overflowed and the whole tree should be refreshed.
+type FileChangeMsg struct {
+	Changes []FileChange
+}
+
+// Watcher wraps fsnotify.Watcher with debouncing and performance optimizations.
+// When fsnotify is unavailable or runs out of watches it polls instead.
 type Watcher struct {
-	watcher    *fsnotify.Watcher
-	rootPath   string
-	debounceMs int
-	watched    map[string]bool
-	mu         sync.Mutex
+	watcher      *fsnotify.Watcher // nil when polling
+	polling      bool
+	snapshots    map[string]dirSnapshot // Last listing of each watched dir (polling)
+	pollInterval time.Duration          // Current adaptive poll interval
+	rootPath     string
+	debounceMs   int
+	maxWaitMs    int
+	ignore       []string // Patterns whose events are dropped (see watchIgnored)
+	watched      map[string]bool
+	done         chan struct{}
+	closeOnce    sync.Once
+	mu           sync.Mutex
 }
 
-// NewWatcher creates a new file watcher
+// NewWatcher creates a new file watcher, falling back to polling when
+// fsnotify can't be set up (e.g., out of inotify instances)
 func NewWatcher(rootPath string) (*Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return nil, err
+		return NewPollWatcher(rootPath)
 	}
 
-	w := &Watcher{
-		watcher:    watcher,
-		rootPath:   rootPath,
-		debounceMs: DebounceWatchMs,
-		watched:    make(map[string]bool),
-	}
+	w := newWatcher(rootPath)
+	w.watcher = watcher
 
 	// Only watch root path initially (lazy watching for subdirs)
 	if err := w.addPath(rootPath); err != nil {
-		watcher.Close()
+		w.Close()
 		return nil, err
 	}
 
@@ -48,6 +75,74 @@ func NewWatcher(rootPath string) (*Watcher, error) {
 	return w, nil
 }
 
+// NewPollWatcher creates a watcher that polls directory listings
+func NewPollWatcher(rootPath string) (*Watcher, error) {
+	w := newWatcher(rootPath)
+	w.polling = true
+	if err := w.addPath(rootPath); err != nil {
+		return nil, err
+	}
+	w.watchVCSDirs(rootPath)
+	return w, nil
+}
+
+func newWatcher(rootPath string) *Watcher {
+	return &Watcher{
+		rootPath:   rootPath,
+		debounceMs: DebounceWatchMs,
+		maxWaitMs:  MaxWaitWatchMs,
+		ignore:     DefaultWatchIgnore,
+		watched:    make(map[string]bool),
+		snapshots:  make(map[string]dirSnapshot),
+		done:       make(chan struct{}),
+	}
+}
+
+// Backend names the active backend for the status bar
+func (w *Watcher) Backend() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.polling {
+		return "poll"
+	}
+	return "fsnotify"
+}
+
+// isPolling reports whether the polling backend is active
+func (w *Watcher) isPolling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.polling
+}
+
+// switchToPolling replaces fsnotify with polling for all watched paths.
+// Called with w.mu held.
+func (w *Watcher) switchToPolling() {
+	if w.polling {
+		return
+	}
+	w.polling = true
+	for dir := range w.watched {
+		if snap, err := snapshotDir(dir); err == nil {
+			w.snapshots[dir] = snap
+		} else {
+			delete(w.watched, dir)
+		}
+	}
+	// Closing ends the fsnotify loop in Watch, which then continues polling.
+	// Close waits for fsnotify's reader, which may be waiting on Watch, which
+	// may be waiting for w.mu: close in the background.
+	if old := w.watcher; old != nil {
+		w.watcher = nil
+		go old.Close()
+	}
+}
+
+// watchLimitReached reports errors that mean no more watches can be added
+func watchLimitReached(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
 // watchVCSDirs watches VCS directories (.git, .jj) for status changes
 func (w *Watcher) watchVCSDirs(rootPath string) {
 	// Watch .git directory
@@ -83,9 +178,24 @@ func (w *Watcher) addPath(path string) error {
 		return nil
 	}
 
-	if err := w.watcher.Add(path); err != nil {
+	if !w.polling {
+		err := w.watcher.Add(path)
+		if err == nil {
+			w.watched[path] = true
+			return nil
+		}
+		if !watchLimitReached(err) {
+			return err
+		}
+		// Out of inotify watches: poll everything instead
+		w.switchToPolling()
+	}
+
+	snap, err := snapshotDir(path)
+	if err != nil {
 		return err
 	}
+	w.snapshots[path] = snap
 	w.watched[path] = true
 	return nil
 }
@@ -104,7 +214,9 @@ func (w *Watcher) RemovePath(path string) error {
 		return nil
 	}
 
-	if err := w.watcher.Remove(path); err != nil {
+	if w.polling {
+		delete(w.snapshots, path)
+	} else if err := w.watcher.Remove(path); err != nil {
 		return err
 	}
 	delete(w.watched, path)
@@ -121,8 +233,8 @@ func (w *Watcher) watchNodeRecursive(node *FileNode) {
 		return
 	}
 
-	// Watch this directory if expanded
-	if node.Expanded {
+	// Watch this directory if expanded (ignored trees stay unwatched)
+	if node.Expanded && (node.Path == w.rootPath || !w.ignored(node.Path)) {
 		w.addPath(node.Path)
 		for _, child := range node.Children {
 			w.watchNodeRecursive(child)
@@ -130,57 +242,206 @@ func (w *Watcher) watchNodeRecursive(node *FileNode) {
 	}
 }
 
-// Watch returns a command that listens for file system events
+// Watch returns a command that listens for file system events. Changes are
+// collected until events stop for the debounce interval (trailing edge), or
+// for at most the max wait during a continuous stream, then returned together.
 func (w *Watcher) Watch() tea.Cmd {
 	return func() tea.Msg {
-		var lastEvent time.Time
+		w.mu.Lock()
+		watcher, polling := w.watcher, w.polling
+		w.mu.Unlock()
+		if polling {
+			return w.pollChanges()
+		}
+
+		batch := newChangeBatch()
+		var quiet *time.Timer
+		var quietC, deadline <-chan time.Time
+		defer func() {
+			if quiet != nil {
+				quiet.Stop()
+			}
+		}()
+
+		// restart pushes the trailing edge back and starts the max-wait clock
+		restart := func() {
+			debounce := time.Duration(w.debounceMs) * time.Millisecond
+			if quiet == nil {
+				quiet = time.NewTimer(debounce)
+				quietC = quiet.C
+			} else {
+				quiet.Reset(debounce)
+			}
+			if deadline == nil {
+				deadline = time.After(time.Duration(w.maxWaitMs) * time.Millisecond)
+			}
+		}
 
 		for {
 			select {
-			case event, ok := <-w.watcher.Events:
+			case event, ok := <-watcher.Events:
 				if !ok {
-					return nil
+					return w.afterClose()
 				}
-
-				// Ignore Chmod events (Spotlight, antivirus, etc.)
-				if event.Op&fsnotify.Chmod == fsnotify.Chmod {
+				change, ok := w.handleEvent(event)
+				if !ok {
 					continue
 				}
+				batch.add(change)
+				restart()
 
-				// Debounce: ignore events within debounceMs of each other
-				now := time.Now()
-				if now.Sub(lastEvent).Milliseconds() < int64(w.debounceMs) {
-					continue
+			case <-quietC:
+				return batch.msg()
+
+			case <-deadline:
+				return batch.msg()
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return w.afterClose()
 				}
-				lastEvent = now
-
-				// If a new directory is created, add to watch list
-				if event.Op&fsnotify.Create == fsnotify.Create {
-					info, err := os.Stat(event.Name)
-					if err == nil && info.IsDir() {
-						w.addPath(event.Name)
-					}
+				// Dropped events: only a full refresh is reliable
+				if errors.Is(err, fsnotify.ErrEventOverflow) {
+					batch.overflow = true
+					restart()
 				}
+			}
+		}
+	}
+}
 
-				// If a directory is removed, remove from watch list
-				if event.Op&fsnotify.Remove == fsnotify.Remove {
-					w.mu.Lock()
-					if w.watched[event.Name] {
-						delete(w.watched, event.Name)
-					}
-					w.mu.Unlock()
-				}
+// afterClose runs when the fsnotify channels close: either the watcher was
+// closed, or it switched to polling and Watch carries on with that
+func (w *Watcher) afterClose() tea.Msg {
+	if w.isPolling() {
+		// Events since the switch were missed; refresh everything once
+		select {
+		case <-w.done:
+			return nil
+		default:
+			return FileChangeMsg{}
+		}
+	}
+	return nil
+}
 
-				return FileChangeMsg{}
+// changeBatch coalesces the changes of one debounce window, one entry per path
+type changeBatch struct {
+	changes  []FileChange
+	index    map[string]int
+	overflow bool
+}
 
-			case _, ok := <-w.watcher.Errors:
-				if !ok {
-					return nil
+func newChangeBatch() *changeBatch {
+	return &changeBatch{index: make(map[string]int)}
+}
+
+// add records a change; for a path seen before, the latest create or remove
+// wins and writes never override either
+func (b *changeBatch) add(change FileChange) {
+	if i, ok := b.index[change.Path]; ok {
+		if change.Op != FileModified {
+			b.changes[i].Op = change.Op
+		}
+		return
+	}
+	if len(b.changes) >= MaxWatchBatch {
+		b.overflow = true
+		return
+	}
+	b.index[change.Path] = len(b.changes)
+	b.changes = append(b.changes, change)
+}
+
+// msg returns the batch, with no paths when it overflowed (full refresh)
+func (b *changeBatch) msg() FileChangeMsg {
+	if b.overflow {
+		return FileChangeMsg{}
+	}
+	return FileChangeMsg{Changes: b.changes}
+}
+
+// SetIgnore replaces the ignore patterns; nil restores the defaults
+func (w *Watcher) SetIgnore(patterns []string) {
+	if patterns == nil {
+		patterns = DefaultWatchIgnore
+	}
+	w.mu.Lock()
+	w.ignore = patterns
+	w.mu.Unlock()
+}
+
+// ignored reports whether events for path are dropped
+func (w *Watcher) ignored(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return watchIgnored(w.rootPath, path, w.ignore)
+}
+
+// watchIgnored matches path against ignore patterns. A pattern without a
+// slash matches any path component ("node_modules", "*.swp"); one with a
+// leading slash or a slash inside matches from the root ("/dist",
+// ".git/objects"), along with everything below. Trailing slashes are ignored.
+func watchIgnored(root, path string, patterns []string) bool {
+	if len(patterns) == 0 || !isWithin(root, path) {
+		return false
+	}
+	rel, _ := filepath.Rel(root, path)
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	for _, pattern := range patterns {
+		anchored := strings.HasPrefix(pattern, "/")
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		if !anchored && !strings.Contains(pattern, "/") {
+			for _, part := range parts {
+				if ok, _ := filepath.Match(pattern, part); ok {
+					return true
 				}
-				// Ignore errors, just continue watching
+			}
+			continue
+		}
+		n := strings.Count(pattern, "/") + 1
+		if len(parts) >= n {
+			if ok, _ := filepath.Match(pattern, strings.Join(parts[:n], "/")); ok {
+				return true
 			}
 		}
 	}
+	return false
+}
+
+// handleEvent keeps the watch list in step with the event and converts it
+// to a FileChange (false for events that don't change the tree view)
+func (w *Watcher) handleEvent(event fsnotify.Event) (FileChange, bool) {
+	if w.ignored(event.Name) {
+		return FileChange{}, false
+	}
+
+	switch {
+	case event.Op&fsnotify.Create == fsnotify.Create:
+		// Watch new directories
+		info, err := os.Stat(event.Name)
+		if err == nil && info.IsDir() {
+			w.addPath(event.Name)
+		}
+		return FileChange{Path: event.Name, Op: FileCreated}, true
+
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		// The new name of a rename arrives as a separate Create
+		w.mu.Lock()
+		delete(w.watched, event.Name)
+		w.mu.Unlock()
+		return FileChange{Path: event.Name, Op: FileRemoved}, true
+
+	case event.Op&fsnotify.Write == fsnotify.Write:
+		return FileChange{Path: event.Name, Op: FileModified}, true
+	}
+
+	// Ignore Chmod events (Spotlight, antivirus, etc.)
+	return FileChange{}, false
 }
 
 // WatchedCount returns the number of watched paths
@@ -192,8 +453,20 @@ func (w *Watcher) WatchedCount() int {
 
 // Close closes the watcher
 func (w *Watcher) Close() error {
-	if w == nil || w.watcher == nil {
+	if w == nil {
 		return nil
 	}
-	return w.watcher.Close()
+	var err error
+	w.closeOnce.Do(func() {
+		if w.done != nil {
+			close(w.done)
+		}
+		w.mu.Lock()
+		watcher := w.watcher
+		w.mu.Unlock()
+		if watcher != nil {
+			err = watcher.Close()
+		}
+	})
+	return err
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Polling backend: used when fsnotify is unavailable or out of watches.
+// Each watched directory is listed on an interval and compared with the
+// previous listing by modification time and size.
+
+// entryStat is what polling compares for a directory entry
+type entryStat struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// dirSnapshot maps entry names to their last seen state
+type dirSnapshot map[string]entryStat
+
+// snapshotDir lists dir for polling
+func snapshotDir(dir string) (dirSnapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	snap := make(dirSnapshot, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed while listing
+		}
+		snap[entry.Name()] = entryStat{modTime: info.ModTime(), size: info.Size(), isDir: info.IsDir()}
+	}
+	return snap, nil
+}
+
+// diffSnapshots returns the changes between two listings of dir, by name
+func diffSnapshots(dir string, before, after dirSnapshot) []FileChange {
+	var changes []FileChange
+	for name, now := range after {
+		path := filepath.Join(dir, name)
+		prev, ok := before[name]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: path, Op: FileCreated})
+		case prev.isDir != now.isDir:
+			changes = append(changes, FileChange{Path: path, Op: FileRemoved}, FileChange{Path: path, Op: FileCreated})
+		case now.isDir:
+			// A directory's mtime only says its entries changed
+		case !prev.modTime.Equal(now.modTime) || prev.size != now.size:
+			changes = append(changes, FileChange{Path: path, Op: FileModified})
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, FileChange{Path: filepath.Join(dir, name), Op: FileRemoved})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// pollChanges blocks until a poll finds changes, backing off while the
+// tree is idle and for trees that take long to scan
+func (w *Watcher) pollChanges() tea.Msg {
+	if w.pollInterval == 0 {
+		w.pollInterval = PollMinIntervalMs * time.Millisecond
+	}
+	for {
+		select {
+		case <-w.done:
+			return nil
+		case <-time.After(w.pollInterval):
+		}
+
+		start := time.Now()
+		batch := w.pollOnce()
+		if len(batch.changes) > 0 || batch.overflow {
+			w.pollInterval = PollMinIntervalMs * time.Millisecond
+			return batch.msg()
+		}
+
+		w.pollInterval *= 2
+		if maxInterval := PollMaxIntervalMs * time.Millisecond; w.pollInterval > maxInterval {
+			w.pollInterval = maxInterval
+		}
+		// Never spend more than a fraction of the time scanning
+		if scan := time.Since(start) * PollScanFactor; w.pollInterval < scan {
+			w.pollInterval = scan
+		}
+	}
+}
+
+// pollOnce rescans every watched directory and collects the differences
+func (w *Watcher) pollOnce() *changeBatch {
+	w.mu.Lock()
+	dirs := make([]string, 0, len(w.watched))
+	for dir := range w.watched {
+		dirs = append(dirs, dir)
+	}
+	w.mu.Unlock()
+	sort.Strings(dirs)
+
+	batch := newChangeBatch()
+	for _, dir := range dirs {
+		snap, err := snapshotDir(dir)
+
+		w.mu.Lock()
+		before := w.snapshots[dir]
+		if err != nil {
+			// Gone: the parent's listing reports the removal
+			delete(w.watched, dir)
+			delete(w.snapshots, dir)
+		} else if w.watched[dir] {
+			w.snapshots[dir] = snap
+		}
+		ignore := w.ignore
+		w.mu.Unlock()
+
+		if err != nil {
+			continue
+		}
+		for _, change := range diffSnapshots(dir, before, snap) {
+			if !watchIgnored(w.rootPath, change.Path, ignore) {
+				batch.add(change)
+			}
+		}
+	}
+	return batch
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	before := dirSnapshot{
+		"same.txt":    {modTime: t0, size: 1},
+		"written.txt": {modTime: t0, size: 1},
+		"grown.txt":   {modTime: t0, size: 1},
+		"gone.txt":    {modTime: t0, size: 1},
+		"swap":        {modTime: t0, isDir: true},
+		"dir":         {modTime: t0, isDir: true},
+	}
+	after := dirSnapshot{
+		"same.txt":    {modTime: t0, size: 1},
+		"written.txt": {modTime: t0.Add(time.Second), size: 1},
+		"grown.txt":   {modTime: t0, size: 2},
+		"new.txt":     {modTime: t0, size: 0},
+		"swap":        {modTime: t0, size: 3},
+		"dir":         {modTime: t0.Add(time.Second), isDir: true},
+	}
+
+	expected := []FileChange{
+		{"/d/gone.txt", FileRemoved},
+		{"/d/grown.txt", FileModified},
+		{"/d/new.txt", FileCreated},
+		{"/d/swap", FileRemoved},
+		{"/d/swap", FileCreated},
+		{"/d/written.txt", FileModified},
+	}
+	if got := diffSnapshots("/d", before, after); !reflect.DeepEqual(got, expected) {
+		t.Errorf("diffSnapshots = %+v\nexpected %+v", got, expected)
+	}
+}
+
+func TestPollWatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	sub := filepath.Join(tmpDir, "sub")
+	os.Mkdir(sub, 0755)
+
+	w, err := NewPollWatcher(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Backend() != "poll" || w.watcher != nil {
+		t.Fatalf("Expected the polling backend, got %s", w.Backend())
+	}
+	w.AddPath(sub)
+	w.pollInterval = 20 * time.Millisecond
+
+	msgs := make(chan FileChangeMsg, 1)
+	go func() {
+		msg, _ := w.Watch()().(FileChangeMsg)
+		msgs <- msg
+	}()
+
+	created := filepath.Join(sub, "new.txt")
+	os.WriteFile(created, []byte("x"), 0644)
+
+	select {
+	case msg := <-msgs:
+		if len(msg.Changes) != 1 || msg.Changes[0] != (FileChange{created, FileCreated}) {
+			t.Errorf("Expected %s created, got %+v", created, msg.Changes)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timeout waiting for the poll")
+	}
+
+	// Closing ends a pending poll
+	done := make(chan any, 1)
+	go func() { done <- w.Watch()() }()
+	w.Close()
+	select {
+	case msg := <-done:
+		if msg != nil {
+			t.Errorf("Expected nil after Close, got %v", msg)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Watch did not return after Close")
+	}
+}
+
+func TestWatcher_SwitchesToPolling(t *testing.T) {
+	if !watchLimitReached(fmt.Errorf("add watch: %w", syscall.ENOSPC)) {
+		t.Error("Expected ENOSPC to count as the watch limit")
+	}
+	if watchLimitReached(os.ErrNotExist) {
+		t.Error("Expected a missing path not to count as the watch limit")
+	}
+
+	tmpDir := t.TempDir()
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+	if w.Backend() != "fsnotify" {
+		t.Skipf("fsnotify unavailable (%s)", w.Backend())
+	}
+
+	msgs := make(chan any, 1)
+	go func() { msgs <- w.Watch()() }()
+	time.Sleep(50 * time.Millisecond)
+
+	// What addPath does when inotify runs out of watches
+	w.mu.Lock()
+	w.switchToPolling()
+	w.mu.Unlock()
+
+	select {
+	case msg := <-msgs:
+		if change, ok := msg.(FileChangeMsg); !ok || change.Changes != nil {
+			t.Errorf("Expected a full refresh after switching, got %#v", msg)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Watch did not return after switching to polling")
+	}
+	if w.Backend() != "poll" || !w.watched[tmpDir] {
+		t.Errorf("Expected polling of the root, backend %s", w.Backend())
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -200,6 +203,198 @@ func TestWatcher_DetectsFileCreation(t *testing.T) {
 	}
 }
 
+func TestWatcher_BatchesChangedPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	old := filepath.Join(tmpDir, "old.txt")
+	os.WriteFile(old, nil, 0644)
+
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+
+	msgs := make(chan FileChangeMsg, 1)
+	go func() {
+		msg, _ := w.Watch()().(FileChangeMsg)
+		msgs <- msg
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	created := filepath.Join(tmpDir, "new.txt")
+	os.WriteFile(created, nil, 0644)
+	os.Remove(old)
+
+	select {
+	case msg := <-msgs:
+		ops := make(map[string]FileChangeOp)
+		for _, change := range msg.Changes {
+			ops[change.Path] = change.Op
+		}
+		if op, ok := ops[created]; !ok || op == FileRemoved {
+			t.Errorf("Expected %s created in %+v", created, msg.Changes)
+		}
+		if op, ok := ops[old]; !ok || op != FileRemoved {
+			t.Errorf("Expected %s removed in %+v", old, msg.Changes)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the batch")
+	}
+}
+
+func TestChangeBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		changes  []FileChange
+		expected []FileChange
+	}{
+		{
+			"write after create stays a create",
+			[]FileChange{{"/a", FileCreated}, {"/a", FileModified}, {"/a", FileModified}},
+			[]FileChange{{"/a", FileCreated}},
+		},
+		{
+			"atomic save ends as a create",
+			[]FileChange{{"/a", FileRemoved}, {"/a.tmp", FileCreated}, {"/a.tmp", FileRemoved}, {"/a", FileCreated}},
+			[]FileChange{{"/a", FileCreated}, {"/a.tmp", FileRemoved}},
+		},
+		{
+			"removed after a write",
+			[]FileChange{{"/b", FileModified}, {"/b", FileRemoved}},
+			[]FileChange{{"/b", FileRemoved}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := newChangeBatch()
+			for _, change := range tt.changes {
+				batch.add(change)
+			}
+			if got := batch.msg().Changes; !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Changes = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+
+	batch := newChangeBatch()
+	for i := 0; i <= MaxWatchBatch; i++ {
+		batch.add(FileChange{Path: fmt.Sprintf("/f%d", i), Op: FileCreated})
+	}
+	if msg := batch.msg(); msg.Changes != nil {
+		t.Errorf("Expected an overflowed batch to ask for a full refresh, got %d changes", len(msg.Changes))
+	}
+}
+
+func TestWatchIgnored(t *testing.T) {
+	patterns := []string{"node_modules", ".git/objects", "*.swp", "build/"}
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"/r/node_modules", true},
+		{"/r/web/node_modules/react/index.js", true},
+		{"/r/.git/objects/ab/cdef", true},
+		{"/r/.git/index", false},
+		{"/r/sub/.git/objects/ab", false},
+		{"/r/main.go.swp", true},
+		{"/r/build/out.o", true},
+		{"/r/src/build/out.o", true},
+		{"/r/src/main.go", false},
+		{"/elsewhere/node_modules", false},
+	}
+	for _, tt := range tests {
+		if got := watchIgnored("/r", tt.path, patterns); got != tt.ignored {
+			t.Errorf("watchIgnored(%s) = %v, expected %v", tt.path, got, tt.ignored)
+		}
+	}
+
+	// The defaults skip build output at the top only
+	for _, path := range []string{"/r/dist/app.js", "/r/build/index.html", "/r/target/debug/app"} {
+		if !watchIgnored("/r", path, DefaultWatchIgnore) {
+			t.Errorf("Expected %s ignored by default", path)
+		}
+	}
+	for _, path := range []string{"/r/pkg/build/build.go", "/r/cmd/dist/main.go", "/r/internal/target"} {
+		if watchIgnored("/r", path, DefaultWatchIgnore) {
+			t.Errorf("Expected %s watched by default", path)
+		}
+	}
+}
+
+func TestWatcher_TrailingEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Mkdir(filepath.Join(tmpDir, "node_modules"), 0755)
+
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+	w.debounceMs = 100
+	w.AddPath(filepath.Join(tmpDir, "node_modules"))
+
+	msgs := make(chan FileChangeMsg, 1)
+	go func() {
+		msg, _ := w.Watch()().(FileChangeMsg)
+		msgs <- msg
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// A burst longer than the debounce interval: the last write must be in the batch
+	for i := 0; i < 4; i++ {
+		os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("f%d.txt", i)), nil, 0644)
+		os.WriteFile(filepath.Join(tmpDir, "node_modules", fmt.Sprintf("dep%d.js", i)), nil, 0644)
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	select {
+	case msg := <-msgs:
+		paths := make(map[string]bool)
+		for _, change := range msg.Changes {
+			paths[filepath.Base(change.Path)] = true
+			if strings.Contains(change.Path, "node_modules") {
+				t.Errorf("Expected node_modules ignored, got %s", change.Path)
+			}
+		}
+		if !paths["f3.txt"] || !paths["f0.txt"] {
+			t.Errorf("Expected the whole burst in one batch, got %+v", msg.Changes)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timeout waiting for the batch")
+	}
+}
+
+func TestWatcher_WatchesNestedBuildDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	nested := filepath.Join(tmpDir, "pkg", "build")
+	os.MkdirAll(nested, 0755)
+
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+	w.debounceMs = 50
+	w.AddPath(nested)
+
+	msgs := make(chan FileChangeMsg, 1)
+	go func() {
+		msg, _ := w.Watch()().(FileChangeMsg)
+		msgs <- msg
+	}()
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(filepath.Join(nested, "build.go"), nil, 0644)
+
+	select {
+	case msg := <-msgs:
+		if len(msg.Changes) == 0 || msg.Changes[0].Path != filepath.Join(nested, "build.go") {
+			t.Errorf("Expected pkg/build/build.go reported, got %+v", msg.Changes)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected changes in pkg/build to be watched")
+	}
+}
+
 func TestWatcher_IgnoresChmodEvents(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "test.txt")